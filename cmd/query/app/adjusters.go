@@ -26,4 +26,5 @@ var StandardAdjusters = []adjuster.Adjuster{
 	adjuster.IPTagAdjuster(),
 	adjuster.SortLogFields(),
 	adjuster.SpanReferences(),
+	adjuster.TraceAttributes(),
 }
@@ -46,8 +46,9 @@ func (s spanReferenceAdjuster) adjust(span *model.Span) *model.Span {
 	}
 	references := make([]model.SpanRef, 0, len(span.References)-1)
 	for i := range span.References {
-		if !s.valid(&span.References[i]) {
-			span.Warnings = append(span.Warnings, fmt.Sprintf("Invalid span reference removed %+v", span.References[i]))
+		if ref := &span.References[i]; !s.valid(ref) {
+			span.Warnings = append(span.Warnings, fmt.Sprintf("Invalid span reference removed {RefType:%v TraceID:%v SpanID:%v}",
+				ref.RefType, ref.TraceID, ref.SpanID))
 			continue
 		}
 		references = append(references, span.References[i])
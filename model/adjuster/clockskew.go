@@ -136,7 +136,7 @@ func (a *clockSkewAdjuster) adjustNode(n *node, parent *node, skew clockSkew) {
 		// so we can compare this node's timestamps against the parent.
 		skew = clockSkew{
 			hostKey: n.hostKey,
-			delta:   a.calculateSkew(n, parent),
+			delta:   calculateSkew(n.span, parent.span),
 		}
 	}
 	a.adjustTimestamps(n, skew)
@@ -145,30 +145,32 @@ func (a *clockSkewAdjuster) adjustNode(n *node, parent *node, skew clockSkew) {
 	}
 }
 
-func (a *clockSkewAdjuster) calculateSkew(child *node, parent *node) time.Duration {
-	parentDuration := parent.span.Duration
-	childDuration := child.span.Duration
-	parentEndTime := parent.span.StartTime.Add(parent.span.Duration)
-	childEndTime := child.span.StartTime.Add(child.span.Duration)
+// calculateSkew returns the adjustment of the child start time that makes the child
+// fit within the already adjusted parent.
+func calculateSkew(child *model.Span, parent *model.Span) time.Duration {
+	parentDuration := parent.Duration
+	childDuration := child.Duration
+	parentEndTime := parent.StartTime.Add(parent.Duration)
+	childEndTime := child.StartTime.Add(child.Duration)
 
 	if childDuration > parentDuration {
 		// When the child lasted longer than the parent, it was either
 		// async or the parent may have timed out before child responded.
 		// The only reasonable adjustment we can do in this case is to make
 		// sure the child does not start before parent.
-		if child.span.StartTime.Before(parent.span.StartTime) {
-			return parent.span.StartTime.Sub(child.span.StartTime)
+		if child.StartTime.Before(parent.StartTime) {
+			return parent.StartTime.Sub(child.StartTime)
 		}
 		return 0
 	}
-	if !child.span.StartTime.Before(parent.span.StartTime) && !childEndTime.After(parentEndTime) {
+	if !child.StartTime.Before(parent.StartTime) && !childEndTime.After(parentEndTime) {
 		// child already fits within the parent span, do not adjust
 		return 0
 	}
 	// Assume that network latency is equally split between req and res.
 	latency := (parentDuration - childDuration) / 2
 	// Goal: parentStartTime + latency = childStartTime + adjustment
-	return parent.span.StartTime.Add(latency).Sub(child.span.StartTime)
+	return parent.StartTime.Add(latency).Sub(child.StartTime)
 }
 
 func (a *clockSkewAdjuster) adjustTimestamps(n *node, skew clockSkew) {
@@ -0,0 +1,95 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	warningFormatClockSkewAdjusted = "start time adjusted by %v to correct clock skew"
+	warningFormatMaxClockSkew      = "clock skew adjustment of %v exceeds the maximum of %v; not adjusting"
+)
+
+// RPCClockSkew returns an adjuster that corrects clock skew between the client and the
+// server of an RPC, identified by the span.kind tags: a server span whose parent is a
+// client span is moved to fit within the client span, assuming that the network latency
+// is equally split between request and response. The descendants of the server span are
+// moved by the same amount, as they are assumed to be timed by the same clock, except
+// for the server spans of further RPCs, which are adjusted against their own client spans.
+//
+// Unlike ClockSkew, it does not rely on process tags to tell hosts apart, but only
+// adjusts spans of RPCs with span.kind tags on both sides.
+//
+// Adjustments larger than maxDelta are not applied, as they more likely indicate broken
+// instrumentation than clock skew; a non-positive maxDelta disables this limit.
+// This adjuster never returns any errors. Instead it records adjustments and skipped
+// adjustments in Span.Warnings of the affected spans.
+func RPCClockSkew(maxDelta time.Duration) Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		adjuster := &rpcClockSkewAdjuster{
+			maxDelta: maxDelta,
+			children: trace.ChildIndex(),
+			visited:  make(map[*model.Span]struct{}, len(trace.Spans)),
+		}
+		spansByID := make(map[model.SpanID]*model.Span, len(trace.Spans))
+		for _, span := range trace.Spans {
+			if _, ok := spansByID[span.SpanID]; !ok {
+				spansByID[span.SpanID] = span
+			}
+		}
+		for _, span := range trace.Spans {
+			if _, ok := spansByID[span.ParentSpanID()]; !ok {
+				adjuster.adjustSpan(span, nil, 0)
+			}
+		}
+		return trace, nil
+	})
+}
+
+type rpcClockSkewAdjuster struct {
+	maxDelta time.Duration
+	children map[model.SpanID][]*model.Span
+	visited  map[*model.Span]struct{}
+}
+
+// adjustSpan moves the span by delta, or by the skew calculated against the parent
+// if the span is the server side of an RPC from the parent, then adjusts its children.
+func (a *rpcClockSkewAdjuster) adjustSpan(span *model.Span, parent *model.Span, delta time.Duration) {
+	if _, ok := a.visited[span]; ok {
+		return // reference cycle or repeated span ID
+	}
+	a.visited[span] = struct{}{}
+	if parent != nil && parent.IsRPCClient() && span.IsRPCServer() {
+		delta = calculateSkew(span, parent)
+		if a.maxDelta > 0 && (delta > a.maxDelta || delta < -a.maxDelta) {
+			span.Warnings = append(span.Warnings, fmt.Sprintf(warningFormatMaxClockSkew, delta, a.maxDelta))
+			delta = 0
+		}
+	}
+	if delta != 0 {
+		span.StartTime = span.StartTime.Add(delta)
+		for i := range span.Logs {
+			span.Logs[i].Timestamp = span.Logs[i].Timestamp.Add(delta)
+		}
+		span.Warnings = append(span.Warnings, fmt.Sprintf(warningFormatClockSkewAdjusted, delta))
+	}
+	for _, child := range a.children[span.SpanID] {
+		a.adjustSpan(child, span, delta)
+	}
+}
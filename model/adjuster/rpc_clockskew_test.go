@@ -0,0 +1,134 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestRPCClockSkewAdjuster(t *testing.T) {
+	// rpcSpanProto is a simple descriptor of complete model.Span, times in milliseconds
+	type rpcSpanProto struct {
+		id, parent, startTime, duration int
+		kind                            ext.SpanKindEnum
+		adjusted                        int // start time after adjustment
+		warnings                        []string
+	}
+
+	toTime := func(t int) time.Time {
+		return time.Unix(0, (time.Duration(t) * time.Millisecond).Nanoseconds())
+	}
+	makeTrace := func(protos []rpcSpanProto) *model.Trace {
+		trace := &model.Trace{}
+		traceID := model.TraceID{Low: 1}
+		for _, proto := range protos {
+			span := &model.Span{
+				TraceID:    traceID,
+				SpanID:     model.SpanID(proto.id),
+				References: model.MaybeAddParentSpanID(traceID, model.SpanID(proto.parent), nil),
+				StartTime:  toTime(proto.startTime),
+				Duration:   time.Duration(proto.duration) * time.Millisecond,
+				Logs:       []model.Log{{Timestamp: toTime(proto.startTime)}},
+			}
+			if proto.kind != "" {
+				span.Tags = []model.KeyValue{model.String(string(ext.SpanKind), string(proto.kind))}
+			}
+			trace.Spans = append(trace.Spans, span)
+		}
+		return trace
+	}
+
+	testCases := []struct {
+		description string
+		maxDelta    time.Duration
+		trace       []rpcSpanProto
+	}{
+		{
+			description: "server fits within client",
+			trace: []rpcSpanProto{
+				{id: 1, startTime: 0, duration: 100, kind: ext.SpanKindRPCClientEnum, adjusted: 0},
+				{id: 2, parent: 1, startTime: 10, duration: 50, kind: ext.SpanKindRPCServerEnum, adjusted: 10},
+			},
+		},
+		{
+			description: "server and its subtree moved into client",
+			trace: []rpcSpanProto{
+				{id: 1, startTime: 0, duration: 100, kind: ext.SpanKindRPCClientEnum, adjusted: 0},
+				{id: 2, parent: 1, startTime: -80, duration: 50, kind: ext.SpanKindRPCServerEnum, adjusted: 25,
+					warnings: []string{"start time adjusted by 105ms to correct clock skew"}},
+				{id: 3, parent: 2, startTime: -70, duration: 10, adjusted: 35,
+					warnings: []string{"start time adjusted by 105ms to correct clock skew"}},
+			},
+		},
+		{
+			description: "nested RPC adjusted against its own client",
+			trace: []rpcSpanProto{
+				{id: 1, startTime: 0, duration: 100, kind: ext.SpanKindRPCClientEnum, adjusted: 0},
+				{id: 2, parent: 1, startTime: 200, duration: 80, kind: ext.SpanKindRPCServerEnum, adjusted: 10,
+					warnings: []string{"start time adjusted by -190ms to correct clock skew"}},
+				{id: 3, parent: 2, startTime: 210, duration: 60, kind: ext.SpanKindRPCClientEnum, adjusted: 20,
+					warnings: []string{"start time adjusted by -190ms to correct clock skew"}},
+				{id: 4, parent: 3, startTime: 0, duration: 40, kind: ext.SpanKindRPCServerEnum, adjusted: 30,
+					warnings: []string{"start time adjusted by 30ms to correct clock skew"}},
+			},
+		},
+		{
+			description: "spans without span.kind on both sides are not adjusted",
+			trace: []rpcSpanProto{
+				{id: 1, startTime: 0, duration: 100, kind: ext.SpanKindRPCClientEnum, adjusted: 0},
+				{id: 2, parent: 1, startTime: 500, duration: 50, adjusted: 500},
+				{id: 3, parent: 2, startTime: 900, duration: 10, kind: ext.SpanKindRPCServerEnum, adjusted: 900},
+			},
+		},
+		{
+			description: "adjustment beyond max delta is skipped",
+			maxDelta:    time.Millisecond * 100,
+			trace: []rpcSpanProto{
+				{id: 1, startTime: 0, duration: 100, kind: ext.SpanKindRPCClientEnum, adjusted: 0},
+				{id: 2, parent: 1, startTime: 500, duration: 50, kind: ext.SpanKindRPCServerEnum, adjusted: 500,
+					warnings: []string{"clock skew adjustment of -475ms exceeds the maximum of 100ms; not adjusting"}},
+				{id: 3, parent: 1, startTime: 120, duration: 60, kind: ext.SpanKindRPCServerEnum, adjusted: 20,
+					warnings: []string{"start time adjusted by -100ms to correct clock skew"}},
+			},
+		},
+		{
+			description: "reference cycle",
+			trace: []rpcSpanProto{
+				{id: 1, parent: 2, startTime: 0, duration: 100, kind: ext.SpanKindRPCClientEnum, adjusted: 0},
+				{id: 2, parent: 1, startTime: 500, duration: 50, kind: ext.SpanKindRPCServerEnum, adjusted: 500},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			trace, err := RPCClockSkew(testCase.maxDelta).Adjust(makeTrace(testCase.trace))
+			require.NoError(t, err)
+			for i, proto := range testCase.trace {
+				span := trace.Spans[i]
+				assert.Equal(t, toTime(proto.adjusted), span.StartTime, "span %d start time", proto.id)
+				assert.Equal(t, toTime(proto.adjusted), span.Logs[0].Timestamp, "span %d log timestamp", proto.id)
+				assert.Equal(t, proto.warnings, span.Warnings, "span %d warnings", proto.id)
+			}
+		})
+	}
+}
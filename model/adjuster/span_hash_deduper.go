@@ -0,0 +1,125 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// SpanHashDeduperOptions configure the SpanHashDeduper adjuster.
+type SpanHashDeduperOptions struct {
+	// MergeNearDuplicates enables merging of spans that are equal except for their logs,
+	// e.g. when the span was written more than once with different subsets of its logs.
+	// The logs of the later copies are merged into the first one, see Span.Merge.
+	MergeNearDuplicates bool
+}
+
+// SpanHashDeduper returns an adjuster that removes copies of the same span from the trace,
+// which storage backends return when spans are written more than once, e.g. by dual writes
+// or retries. Exact duplicates are found by Span.Hash and confirmed with Span.Equal, and
+// only the first copy is kept in its position in the trace.
+//
+// Spans that share a span ID but differ otherwise, e.g. the client and server spans
+// of Zipkin-style clients (see SpanIDDeduper), are not duplicates and are kept.
+//
+// This adjuster never returns any errors. Spans that cannot be hashed are kept as they are.
+func SpanHashDeduper(opts SpanHashDeduperOptions) Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		deduper := &spanHashDeduper{
+			opts:        opts,
+			exact:       make(map[uint64][]*model.Span),
+			hashes:      make(map[*model.Span]uint64),
+			withoutLogs: make(map[uint64][]*model.Span),
+		}
+		spans := trace.Spans[:0]
+		for _, span := range trace.Spans {
+			if deduper.isDuplicate(span) {
+				continue
+			}
+			spans = append(spans, span)
+		}
+		trace.Spans = spans
+		return trace, nil
+	})
+}
+
+type spanHashDeduper struct {
+	opts SpanHashDeduperOptions
+	// exact maps the hashes of the kept spans to the spans
+	exact map[uint64][]*model.Span
+	// hashes maps the kept spans to their keys in exact
+	hashes map[*model.Span]uint64
+	// withoutLogs maps the hashes of the kept spans, excluding logs, to the spans
+	withoutLogs map[uint64][]*model.Span
+}
+
+// isDuplicate returns true if the span is a copy of a span that was already kept,
+// merging it into that span if it is a near-duplicate. Otherwise it records the span as kept.
+func (d *spanHashDeduper) isDuplicate(span *model.Span) bool {
+	hash, err := model.HashCode(span)
+	if err != nil {
+		return false
+	}
+	for _, kept := range d.exact[hash] {
+		if kept.Equal(span) {
+			return true
+		}
+	}
+	if d.opts.MergeNearDuplicates {
+		noLogs := *span
+		noLogs.Logs = nil
+		noLogsHash, err := model.HashCode(&noLogs)
+		if err != nil {
+			return false
+		}
+		for _, kept := range d.withoutLogs[noLogsHash] {
+			keptNoLogs := *kept
+			keptNoLogs.Logs = nil
+			if keptNoLogs.Equal(&noLogs) {
+				kept.Merge(span)
+				d.rehash(kept)
+				return true
+			}
+		}
+		d.withoutLogs[noLogsHash] = append(d.withoutLogs[noLogsHash], span)
+	}
+	d.exact[hash] = append(d.exact[hash], span)
+	d.hashes[span] = hash
+	return false
+}
+
+// rehash moves a kept span to its new key in exact after other logs were merged into it.
+// A span that can no longer be hashed is only found as a near-duplicate.
+func (d *spanHashDeduper) rehash(kept *model.Span) {
+	if oldHash, ok := d.hashes[kept]; ok {
+		spans := d.exact[oldHash]
+		for i := range spans {
+			if spans[i] == kept {
+				d.exact[oldHash] = append(spans[:i], spans[i+1:]...)
+				break
+			}
+		}
+		if len(d.exact[oldHash]) == 0 {
+			delete(d.exact, oldHash)
+		}
+		delete(d.hashes, kept)
+	}
+	hash, err := model.HashCode(kept)
+	if err != nil {
+		return
+	}
+	d.exact[hash] = append(d.exact[hash], kept)
+	d.hashes[kept] = hash
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func newDuplicateSpan(spanID model.SpanID, logs ...int) *model.Span {
+	traceID := model.TraceID{Low: 1}
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: "op",
+		StartTime:     time.Unix(100, 0),
+		Duration:      time.Second,
+		Tags:          []model.KeyValue{model.String("k", "v")},
+		Process:       model.NewProcess("svc", nil),
+	}
+	for _, ts := range logs {
+		span.Logs = append(span.Logs, model.Log{
+			Timestamp: time.Unix(100, int64(ts)),
+			Fields:    []model.KeyValue{model.Int64("n", int64(ts))},
+		})
+	}
+	return span
+}
+
+func logTimestamps(span *model.Span) []int {
+	var timestamps []int
+	for _, log := range span.Logs {
+		timestamps = append(timestamps, log.Timestamp.Nanosecond())
+	}
+	return timestamps
+}
+
+func TestSpanHashDeduperExactDuplicates(t *testing.T) {
+	clientSpan := newDuplicateSpan(2)
+	clientSpan.Tags = []model.KeyValue{model.String("span.kind", "client")}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			newDuplicateSpan(1, 1),
+			newDuplicateSpan(2),
+			newDuplicateSpan(1, 1),
+			clientSpan,
+			newDuplicateSpan(1, 2),
+			newDuplicateSpan(2),
+		},
+	}
+	trace, err := SpanHashDeduper(SpanHashDeduperOptions{}).Adjust(trace)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 4)
+	assert.Equal(t, []int{1}, logTimestamps(trace.Spans[0]))
+	assert.Equal(t, model.SpanID(2), trace.Spans[1].SpanID)
+	assert.True(t, trace.Spans[2] == clientSpan, "spans sharing only the span ID are kept")
+	assert.Equal(t, []int{2}, logTimestamps(trace.Spans[3]), "near-duplicates are kept unless merging is enabled")
+}
+
+func TestSpanHashDeduperMergeNearDuplicates(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			newDuplicateSpan(1, 1),
+			newDuplicateSpan(2, 1),
+			newDuplicateSpan(1, 2, 3),
+			newDuplicateSpan(1, 1),
+		},
+	}
+	different := newDuplicateSpan(1, 4)
+	different.OperationName = "other"
+	trace.Spans = append(trace.Spans, different)
+
+	trace, err := SpanHashDeduper(SpanHashDeduperOptions{MergeNearDuplicates: true}).Adjust(trace)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 3)
+	assert.Equal(t, []int{1, 2, 3}, logTimestamps(trace.Spans[0]))
+	assert.Equal(t, []int{1}, logTimestamps(trace.Spans[1]))
+	assert.Equal(t, "other", trace.Spans[2].OperationName)
+}
+
+func TestSpanHashDeduperRehashMergedSpans(t *testing.T) {
+	deduper := &spanHashDeduper{
+		opts:        SpanHashDeduperOptions{MergeNearDuplicates: true},
+		exact:       make(map[uint64][]*model.Span),
+		hashes:      make(map[*model.Span]uint64),
+		withoutLogs: make(map[uint64][]*model.Span),
+	}
+	kept := newDuplicateSpan(1, 1)
+	assert.False(t, deduper.isDuplicate(kept))
+	assert.True(t, deduper.isDuplicate(newDuplicateSpan(1, 2)))
+
+	hash, err := model.HashCode(kept)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64][]*model.Span{hash: {kept}}, deduper.exact)
+	assert.Equal(t, map[*model.Span]uint64{kept: hash}, deduper.hashes)
+}
+
+func TestSpanHashDeduperUnhashableSpans(t *testing.T) {
+	newUnhashable := func() *model.Span {
+		span := newDuplicateSpan(1)
+		span.Tags = []model.KeyValue{{Key: "k", VType: model.ValueType(-1)}}
+		return span
+	}
+	trace := &model.Trace{Spans: []*model.Span{newUnhashable(), newUnhashable()}}
+	trace, err := SpanHashDeduper(SpanHashDeduperOptions{MergeNearDuplicates: true}).Adjust(trace)
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 2)
+}
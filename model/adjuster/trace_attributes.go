@@ -0,0 +1,28 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// TraceAttributes returns an Adjuster that restores the trace attributes stored
+// as span tags when the trace was written, see model.Trace.LiftAttributes.
+func TraceAttributes() Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		trace.LiftAttributes()
+		return trace, nil
+	})
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adjuster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceAttributes(t *testing.T) {
+	span := &model.Span{Tags: []model.KeyValue{
+		model.String("jaeger.trace_attribute.tenant", "acme"),
+		model.String("k", "v"),
+	}}
+	trace, err := TraceAttributes().Adjust(&model.Trace{Spans: []*model.Span{span}})
+	require.NoError(t, err)
+	assert.Equal(t, model.KeyValues{model.String("tenant", "acme")}, trace.Attributes)
+	assert.Equal(t, []model.KeyValue{model.String("k", "v")}, span.Tags)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// Clone returns a deep copy of the span, including its references, tags, logs,
+// process and warnings, so that the copy can be modified without affecting the
+// original span. Nil slices remain nil.
+func (s *Span) Clone() *Span {
+	return s.clone(s.Process.Clone())
+}
+
+func (s *Span) clone(process *Process) *Span {
+	c := *s
+	if s.References != nil {
+		c.References = make([]SpanRef, len(s.References))
+		for i, ref := range s.References {
+			c.References[i] = ref
+			c.References[i].Tags = cloneKeyValues(ref.Tags)
+		}
+	}
+	c.Tags = cloneKeyValues(s.Tags)
+	if s.Logs != nil {
+		c.Logs = make([]Log, len(s.Logs))
+		for i, log := range s.Logs {
+			c.Logs[i] = Log{Timestamp: log.Timestamp, Fields: cloneKeyValues(log.Fields)}
+		}
+	}
+	c.Process = process
+	if s.Warnings != nil {
+		c.Warnings = append([]string(nil), s.Warnings...)
+	}
+	return &c
+}
+
+// Clone returns a deep copy of the trace (see Span.Clone). Spans that share
+// a Process in the original trace share the copy of that Process.
+func (t *Trace) Clone() *Trace {
+	c := &Trace{}
+	if t.Spans != nil {
+		c.Spans = make([]*Span, len(t.Spans))
+		processes := make(map[*Process]*Process)
+		for i, span := range t.Spans {
+			process, ok := processes[span.Process]
+			if !ok {
+				process = span.Process.Clone()
+				processes[span.Process] = process
+			}
+			c.Spans[i] = span.clone(process)
+		}
+	}
+	if t.Warnings != nil {
+		c.Warnings = append([]string(nil), t.Warnings...)
+	}
+	c.Attributes = cloneKeyValues(t.Attributes)
+	return c
+}
+
+// Clone returns a deep copy of the process. It is safe to call on nil Process.
+func (p *Process) Clone() *Process {
+	if p == nil {
+		return nil
+	}
+	return &Process{ServiceName: p.ServiceName, Tags: cloneKeyValues(p.Tags)}
+}
+
+// cloneKeyValues copies the key-values, including the contents of binary values.
+func cloneKeyValues(kvs []KeyValue) []KeyValue {
+	if kvs == nil {
+		return nil
+	}
+	c := make([]KeyValue, len(kvs))
+	for i, kv := range kvs {
+		c[i] = kv
+		if kv.VBlob != nil {
+			c[i].VBlob = append([]byte(nil), kv.VBlob...)
+		}
+	}
+	return c
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeCloneSpan(process *model.Process) *model.Span {
+	return &model.Span{
+		TraceID:       model.TraceID{Low: 1},
+		SpanID:        2,
+		OperationName: "op",
+		References:    []model.SpanRef{model.NewChildOfRef(model.TraceID{Low: 1}, 1)},
+		StartTime:     time.Unix(100, 0),
+		Duration:      time.Second,
+		Tags:          []model.KeyValue{model.String("k", "v"), model.Binary("blob", []byte{1, 2})},
+		Logs: []model.Log{{
+			Timestamp: time.Unix(100, 5),
+			Fields:    []model.KeyValue{model.Binary("payload", []byte{3})},
+		}},
+		Process:  process,
+		Warnings: []string{"w"},
+	}
+}
+
+func TestSpanClone(t *testing.T) {
+	span := makeCloneSpan(model.NewProcess("svc", []model.KeyValue{model.Binary("id", []byte{4})}))
+	original := makeCloneSpan(model.NewProcess("svc", []model.KeyValue{model.Binary("id", []byte{4})}))
+	clone := span.Clone()
+	assert.Equal(t, span, clone)
+
+	clone.References[0].SpanID = 9
+	clone.Tags[0].VStr = "changed"
+	clone.Tags[1].VBlob[0] = 9
+	clone.Logs[0].Fields[0].VBlob[0] = 9
+	clone.Process.ServiceName = "other"
+	clone.Process.Tags[0].VBlob[0] = 9
+	clone.Warnings[0] = "changed"
+	assert.Equal(t, original, span)
+
+	empty := &model.Span{}
+	assert.Equal(t, empty, empty.Clone())
+}
+
+func TestTraceClone(t *testing.T) {
+	process := model.NewProcess("svc", nil)
+	trace := &model.Trace{
+		Spans:      []*model.Span{makeCloneSpan(process), makeCloneSpan(process), makeCloneSpan(nil)},
+		Warnings:   []string{"w"},
+		Attributes: model.KeyValues{model.Binary("blob", []byte{1})},
+	}
+	clone := trace.Clone()
+	assert.Equal(t, trace, clone)
+	assert.True(t, clone.Spans[0].Process == clone.Spans[1].Process, "shared process stays shared")
+	assert.False(t, clone.Spans[0].Process == process)
+
+	clone.Spans[0].OperationName = "changed"
+	clone.Warnings[0] = "changed"
+	clone.Attributes[0].VBlob[0] = 2
+	assert.Equal(t, "op", trace.Spans[0].OperationName)
+	assert.Equal(t, []string{"w"}, trace.Warnings)
+	assert.Equal(t, []byte{1}, trace.Attributes[0].VBlob)
+
+	assert.Equal(t, &model.Trace{}, (&model.Trace{}).Clone())
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// CollapsedSpanCountTagKey is the tag of a collapsed span holding the number of spans it replaced.
+	CollapsedSpanCountTagKey = "jaeger.collapsed.span_count"
+	// CollapsedServicesTagKey is the tag of a collapsed span holding the sorted, comma-separated
+	// names of the services of the spans it replaced.
+	CollapsedServicesTagKey = "jaeger.collapsed.services"
+)
+
+// CollapseSubtree replaces the given span and all its descendants (see ChildIndex) with
+// a single span summarizing them, and returns it, or nil if the span is not in the trace.
+//
+// The summary span is a copy of the subtree root covering the time window of the whole
+// subtree, with the CollapsedSpanCountTagKey and CollapsedServicesTagKey tags added.
+// It keeps the span ID of the subtree root, and references from the remaining spans to
+// any collapsed span are redirected to it, so that spans outside of the subtree, e.g.
+// follows-from spans or children with multiple parents, remain connected.
+func (t *Trace) CollapseSubtree(root *Span) *Span {
+	index := -1
+	for i, span := range t.Spans {
+		if span == root {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	children := t.ChildIndex()
+	subtree := map[*Span]struct{}{root: {}}
+	collapsedIDs := map[SpanID]struct{}{root.SpanID: {}}
+	queue := []*Span{root}
+	for len(queue) > 0 {
+		span := queue[0]
+		queue = queue[1:]
+		for _, child := range children[span.SpanID] {
+			if _, ok := subtree[child]; !ok {
+				subtree[child] = struct{}{}
+				collapsedIDs[child.SpanID] = struct{}{}
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	collapsed := *root
+	collapsed.References = append([]SpanRef(nil), root.References...)
+	collapsed.Tags = append([]KeyValue(nil), root.Tags...)
+	var subtreeSpans []*Span
+	services := make(map[string]struct{})
+	for _, span := range t.Spans {
+		if _, ok := subtree[span]; ok {
+			subtreeSpans = append(subtreeSpans, span)
+			services[span.serviceName()] = struct{}{}
+		}
+	}
+	interval := (&Trace{Spans: subtreeSpans}).Interval()
+	collapsed.StartTime = interval.Start
+	collapsed.Duration = interval.Duration()
+	serviceNames := make([]string, 0, len(services))
+	for service := range services {
+		serviceNames = append(serviceNames, service)
+	}
+	sort.Strings(serviceNames)
+	collapsed.setTag(Int64(CollapsedSpanCountTagKey, int64(len(subtreeSpans))))
+	collapsed.setTag(String(CollapsedServicesTagKey, strings.Join(serviceNames, ",")))
+
+	spans := t.Spans[:0]
+	for i, span := range t.Spans {
+		if i == index {
+			spans = append(spans, &collapsed)
+			continue
+		}
+		if _, ok := subtree[span]; ok {
+			continue
+		}
+		span.References = redirectReferences(span.References, collapsedIDs, collapsed.TraceID, collapsed.SpanID)
+		spans = append(spans, span)
+	}
+	t.Spans = spans
+	return &collapsed
+}
+
+// redirectReferences replaces the references to the given spans with references to
+// the span with traceID and spanID, dropping duplicates introduced by the replacement.
+func redirectReferences(refs []SpanRef, from map[SpanID]struct{}, traceID TraceID, spanID SpanID) []SpanRef {
+	redirected := false
+	for _, ref := range refs {
+		if _, ok := from[ref.SpanID]; ok && ref.TraceID == traceID {
+			redirected = true
+			break
+		}
+	}
+	if !redirected {
+		return refs
+	}
+	var result []SpanRef
+	for _, ref := range refs {
+		if _, ok := from[ref.SpanID]; ok && ref.TraceID == traceID {
+			ref.SpanID = spanID
+		}
+		result = mergeReferences(result, []SpanRef{ref})
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceCollapseSubtree(t *testing.T) {
+	traceID := model.TraceID{Low: 1}
+	base := time.Unix(100, 0)
+	services := map[model.SpanID]string{1: "frontend", 2: "backend", 3: "db", 4: "backend", 5: "worker", 6: "frontend"}
+	starts := map[model.SpanID]time.Duration{1: 0, 2: 10, 3: 5, 4: 20, 5: 50, 6: 60}
+	durations := map[model.SpanID]time.Duration{1: 100, 2: 20, 3: 10, 4: 30, 5: 10, 6: 10}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(1, 0),
+			makeTreeSpan(2, 1),
+			makeTreeSpan(3, 2),
+			makeTreeSpan(4, 2),
+			makeTreeSpan(5, 1),
+			makeTreeSpan(6, 1),
+		},
+	}
+	for _, span := range trace.Spans {
+		span.OperationName = services[span.SpanID] + "-op"
+		span.Process = model.NewProcess(services[span.SpanID], nil)
+		span.StartTime = base.Add(starts[span.SpanID])
+		span.Duration = durations[span.SpanID]
+	}
+	root := trace.Spans[1]
+	root.Tags = []model.KeyValue{model.String("k", "v")}
+	trace.Spans[4].References = append(trace.Spans[4].References, model.NewFollowsFromRef(traceID, 3))
+	trace.Spans[5].References = append(trace.Spans[5].References,
+		model.NewFollowsFromRef(traceID, 4), model.NewFollowsFromRef(traceID, 2))
+
+	collapsed := trace.CollapseSubtree(root)
+	require.NotNil(t, collapsed)
+	assert.Equal(t, []model.SpanID{1, 2, 5, 6}, spanIDs(trace.Spans))
+	assert.True(t, collapsed == trace.Spans[1])
+	assert.Equal(t, "backend-op", collapsed.OperationName)
+	assert.Equal(t, model.SpanID(1), collapsed.ParentSpanID())
+	assert.Equal(t, base.Add(5), collapsed.StartTime)
+	assert.Equal(t, time.Duration(45), collapsed.Duration)
+	assert.Equal(t, []model.KeyValue{
+		model.String("k", "v"),
+		model.Int64(model.CollapsedSpanCountTagKey, 3),
+		model.String(model.CollapsedServicesTagKey, "backend,db"),
+	}, collapsed.Tags)
+
+	assert.Equal(t, []model.SpanRef{
+		model.NewChildOfRef(traceID, 1),
+		model.NewFollowsFromRef(traceID, 2),
+	}, trace.Spans[2].References)
+	assert.Equal(t, []model.SpanRef{
+		model.NewChildOfRef(traceID, 1),
+		model.NewFollowsFromRef(traceID, 2),
+	}, trace.Spans[3].References, "duplicate references are dropped")
+
+	assert.Equal(t, []model.KeyValue{model.String("k", "v")}, root.Tags, "the original root is not modified")
+	assert.Equal(t, base.Add(10), root.StartTime)
+}
+
+func TestTraceCollapseSubtreeLeafAndMissing(t *testing.T) {
+	trace := &model.Trace{Spans: []*model.Span{makeTreeSpan(1, 0), makeTreeSpan(2, 1)}}
+	assert.Nil(t, trace.CollapseSubtree(makeTreeSpan(1, 0)), "span must be in the trace")
+	assert.Len(t, trace.Spans, 2)
+
+	collapsed := trace.CollapseSubtree(trace.Spans[1])
+	require.NotNil(t, collapsed)
+	assert.Equal(t, []model.SpanID{1, 2}, spanIDs(trace.Spans))
+	count, _ := model.KeyValues(collapsed.Tags).FindByKey(model.CollapsedSpanCountTagKey)
+	assert.Equal(t, int64(1), count.Int64())
+}
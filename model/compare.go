@@ -0,0 +1,124 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DefaultDurationTolerance is the relative difference in span durations
+// that CompareTraces allows between the expected and the actual trace.
+const DefaultDurationTolerance = 0.1
+
+// CompareTraces returns human-readable differences between the expected and the actual
+// trace, using DefaultDurationTolerance. See CompareTracesWithTolerance.
+func CompareTraces(expected, actual *Trace) []string {
+	return CompareTracesWithTolerance(expected, actual, DefaultDurationTolerance)
+}
+
+// CompareTracesWithTolerance returns human-readable differences between the expected
+// and the actual trace: missing and unexpected spans, durations that differ by more
+// than the given fraction of the expected duration, and differences in span tags.
+//
+// Since span and trace IDs differ between runs, spans are matched by their position
+// in the span tree, i.e. by the service and operation names of the span and its
+// ancestors, with siblings of the same name ordered by start time. Spans that are
+// not reachable from a root span, i.e. that form reference cycles, are ignored.
+func CompareTracesWithTolerance(expected, actual *Trace, tolerance float64) []string {
+	expectedPaths, expectedSpans := expected.structuralPaths()
+	actualPaths, actualSpans := actual.structuralPaths()
+	var diffs []string
+	for _, path := range expectedPaths {
+		e := expectedSpans[path]
+		a, ok := actualSpans[path]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("missing span %s", path))
+			continue
+		}
+		delta := a.Duration - e.Duration
+		if delta < 0 {
+			delta = -delta
+		}
+		if float64(delta) > tolerance*float64(e.Duration) {
+			diffs = append(diffs, fmt.Sprintf("span %s: expected duration %v, got %v", path, e.Duration, a.Duration))
+		}
+		diffs = append(diffs, compareTags(path, e.Tags, a.Tags)...)
+	}
+	for _, path := range actualPaths {
+		if _, ok := expectedSpans[path]; !ok {
+			diffs = append(diffs, fmt.Sprintf("unexpected span %s", path))
+		}
+	}
+	return diffs
+}
+
+func compareTags(path string, expected, actual KeyValues) []string {
+	var diffs []string
+	for _, e := range expected {
+		a, ok := actual.FindByKey(e.Key)
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("span %s: missing tag %s=%s", path, e.Key, e.AsString()))
+		} else if !e.Equal(&a) {
+			diffs = append(diffs, fmt.Sprintf("span %s: expected tag %s=%s, got %s", path, e.Key, e.AsString(), a.AsString()))
+		}
+	}
+	for _, a := range actual {
+		if _, ok := expected.FindByKey(a.Key); !ok {
+			diffs = append(diffs, fmt.Sprintf("span %s: unexpected tag %s=%s", path, a.Key, a.AsString()))
+		}
+	}
+	return diffs
+}
+
+// structuralPaths identifies each span by the path of service:operation names from
+// the root separated by " > ", with a #N suffix for the N-th sibling with the same name,
+// ordered by start time.
+// It returns the paths in depth-first order and a map from path to span.
+func (t *Trace) structuralPaths() ([]string, map[string]*Span) {
+	spansByID := t.spansByID()
+	children := t.ChildIndex()
+	var paths []string
+	spans := make(map[string]*Span, len(t.Spans))
+	visited := make(map[*Span]struct{}, len(t.Spans))
+	var visit func(siblings []*Span, prefix string)
+	visit = func(siblings []*Span, prefix string) {
+		sortSpansByStartTime(siblings)
+		seen := make(map[string]int)
+		for _, span := range siblings {
+			if _, ok := visited[span]; ok {
+				continue
+			}
+			visited[span] = struct{}{}
+			name := span.OperationKey()
+			path := prefix + name
+			if n := seen[name]; n > 0 {
+				path += "#" + strconv.Itoa(n)
+			}
+			seen[name]++
+			paths = append(paths, path)
+			spans[path] = span
+			visit(children[span.SpanID], path+" > ")
+		}
+	}
+	var roots []*Span
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.ParentSpanID()]; !ok {
+			roots = append(roots, span)
+		}
+	}
+	visit(roots, "")
+	return paths, spans
+}
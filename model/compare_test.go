@@ -0,0 +1,88 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeCompareTrace(traceID uint64, idOffset model.SpanID) *model.Trace {
+	base := time.Unix(100, 0)
+	span := func(id, parent model.SpanID, service, operation string, start, duration time.Duration) *model.Span {
+		if parent != 0 {
+			parent += idOffset
+		}
+		s := makeTreeSpan(id+idOffset, parent)
+		s.TraceID = model.TraceID{Low: traceID}
+		s.References = model.MaybeAddParentSpanID(s.TraceID, parent, nil)
+		s.Process = model.NewProcess(service, nil)
+		s.OperationName = operation
+		s.StartTime = base.Add(start)
+		s.Duration = duration
+		return s
+	}
+	return &model.Trace{
+		Spans: []*model.Span{
+			span(1, 0, "frontend", "GET /", 0, 100*time.Millisecond),
+			span(2, 1, "db", "query", time.Millisecond, 10*time.Millisecond),
+			span(3, 1, "db", "query", 20*time.Millisecond, 10*time.Millisecond),
+			span(4, 1, "cache", "get", 40*time.Millisecond, 5*time.Millisecond),
+		},
+	}
+}
+
+func TestCompareTracesEqual(t *testing.T) {
+	expected := makeCompareTrace(1, 0)
+	actual := makeCompareTrace(2, 100)
+	actual.Spans[0].Duration = 105 * time.Millisecond // within tolerance
+	assert.Empty(t, model.CompareTraces(expected, actual))
+}
+
+func TestCompareTracesDifferences(t *testing.T) {
+	expected := makeCompareTrace(1, 0)
+	expected.Spans[1].Tags = model.KeyValues{
+		model.String("db.statement", "SELECT 1"),
+		model.String("db.user", "root"),
+	}
+	actual := makeCompareTrace(2, 100)
+	actual.Spans[1].Tags = model.KeyValues{
+		model.String("db.statement", "SELECT 2"),
+		model.Int64("rows", 1),
+	}
+	actual.Spans[2].Duration = 20 * time.Millisecond
+	actual.Spans[3].OperationName = "set"
+
+	assert.Equal(t, []string{
+		"span frontend:GET / > db:query: expected tag db.statement=SELECT 1, got SELECT 2",
+		"span frontend:GET / > db:query: missing tag db.user=root",
+		"span frontend:GET / > db:query: unexpected tag rows=1",
+		"span frontend:GET / > db:query#1: expected duration 10ms, got 20ms",
+		"missing span frontend:GET / > cache:get",
+		"unexpected span frontend:GET / > cache:set",
+	}, model.CompareTraces(expected, actual))
+}
+
+func TestCompareTracesWithTolerance(t *testing.T) {
+	expected := makeCompareTrace(1, 0)
+	actual := makeCompareTrace(2, 100)
+	actual.Spans[2].Duration = 20 * time.Millisecond
+	assert.Len(t, model.CompareTracesWithTolerance(expected, actual, 0.5), 1)
+	assert.Empty(t, model.CompareTracesWithTolerance(expected, actual, 1.0))
+}
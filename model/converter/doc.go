@@ -13,5 +13,5 @@
 // limitations under the License.
 
 // Package converter contains various utilities for converting model.Trace
-// to/from other data modes, like Thrift, or UI JSON.
+// to/from other data modes, like Thrift, OTLP, Zipkin v2 JSON, or UI JSON.
 package converter
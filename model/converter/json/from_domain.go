@@ -57,6 +57,9 @@ func (fd fromDomain) fromDomain(trace *model.Trace) *json.Trace {
 		Processes: fd.convertProcesses(processes.getMapping()),
 		Warnings:  trace.Warnings,
 	}
+	if len(trace.Attributes) > 0 {
+		jTrace.Attributes = fd.convertKeyValues(trace.Attributes)
+	}
 	return jTrace
 }
 
@@ -92,11 +95,15 @@ func (fd fromDomain) convertSpanEmbedProcess(span *model.Span) *json.Span {
 func (fd fromDomain) convertReferences(span *model.Span) []json.Reference {
 	out := make([]json.Reference, 0, len(span.References))
 	for _, ref := range span.References {
-		out = append(out, json.Reference{
+		jRef := json.Reference{
 			RefType: fd.convertRefType(ref.RefType),
 			TraceID: json.TraceID(ref.TraceID.String()),
 			SpanID:  json.SpanID(ref.SpanID.String()),
-		})
+		}
+		if len(ref.Tags) > 0 {
+			jRef.Tags = fd.convertKeyValues(ref.Tags)
+		}
+		out = append(out, jRef)
 	}
 	return out
 }
@@ -101,6 +101,21 @@ func testOutput(t *testing.T, i int, expectedStr []byte, object interface{}, pro
 	}
 }
 
+func TestFromDomainTraceAttributes(t *testing.T) {
+	trace := &model.Trace{
+		Spans:      []*model.Span{{TraceID: model.TraceID{Low: 1}, SpanID: 2, Process: model.NewProcess("svc", nil)}},
+		Attributes: model.KeyValues{model.String("tenant", "acme"), model.Int64("shard", 3)},
+	}
+	uiTrace := FromDomain(trace)
+	assert.Equal(t, []jModel.KeyValue{
+		{Key: "tenant", Type: jModel.StringType, Value: "acme"},
+		{Key: "shard", Type: jModel.Int64Type, Value: int64(3)},
+	}, uiTrace.Attributes)
+
+	trace.Attributes = nil
+	assert.Nil(t, FromDomain(trace).Attributes)
+}
+
 func TestDependenciesFromDomain(t *testing.T) {
 	someParent := "someParent"
 	someChild := "someChild"
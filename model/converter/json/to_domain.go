@@ -30,22 +30,78 @@ func SpanToDomain(span *json.Span) (*model.Span, error) {
 	return toDomain{}.spanToDomain(span)
 }
 
+// TraceToDomain converts json.Trace into model.Trace format. Spans without an embedded
+// Process use the process with their ProcessID, which is shared by all of these spans.
+// Tag values can be strings, as written by FromDomainEmbedProcess, or typed values,
+// as written by FromDomain.
+func TraceToDomain(trace *json.Trace) (*model.Trace, error) {
+	return toDomain{}.traceToDomain(trace)
+}
+
 type toDomain struct{}
 
+func (td toDomain) traceToDomain(jTrace *json.Trace) (*model.Trace, error) {
+	processes := make(map[json.ProcessID]*model.Process, len(jTrace.Processes))
+	for id := range jTrace.Processes {
+		jProcess := jTrace.Processes[id]
+		process, err := td.convertProcess(&jProcess)
+		if err != nil {
+			return nil, err
+		}
+		processes[id] = process
+	}
+	trace := &model.Trace{
+		Spans:    make([]*model.Span, len(jTrace.Spans)),
+		Warnings: jTrace.Warnings,
+	}
+	for i := range jTrace.Spans {
+		jSpan := &jTrace.Spans[i]
+		if jSpan.Process != nil {
+			span, err := td.spanToDomain(jSpan)
+			if err != nil {
+				return nil, err
+			}
+			trace.Spans[i] = span
+			continue
+		}
+		process, ok := processes[jSpan.ProcessID]
+		if !ok {
+			return nil, fmt.Errorf("span %s refers to unknown process %s", jSpan.SpanID, jSpan.ProcessID)
+		}
+		span, err := td.spanWithProcessToDomain(jSpan, process)
+		if err != nil {
+			return nil, err
+		}
+		trace.Spans[i] = span
+	}
+	if len(jTrace.Attributes) > 0 {
+		attributes, err := td.convertKeyValues(jTrace.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		trace.Attributes = attributes
+	}
+	return trace, nil
+}
+
 func (td toDomain) spanToDomain(dbSpan *json.Span) (*model.Span, error) {
-	tags, err := td.convertKeyValues(dbSpan.Tags)
+	process, err := td.convertProcess(dbSpan.Process)
 	if err != nil {
 		return nil, err
 	}
-	logs, err := td.convertLogs(dbSpan.Logs)
+	return td.spanWithProcessToDomain(dbSpan, process)
+}
+
+func (td toDomain) spanWithProcessToDomain(dbSpan *json.Span, process *model.Process) (*model.Span, error) {
+	tags, err := td.convertKeyValues(dbSpan.Tags)
 	if err != nil {
 		return nil, err
 	}
-	refs, err := td.convertRefs(dbSpan.References)
+	logs, err := td.convertLogs(dbSpan.Logs)
 	if err != nil {
 		return nil, err
 	}
-	process, err := td.convertProcess(dbSpan.Process)
+	refs, err := td.convertRefs(dbSpan.References)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +166,11 @@ func (td toDomain) convertRefs(refs []json.Reference) ([]model.SpanRef, error) {
 			TraceID: traceID,
 			SpanID:  model.SpanID(spanID),
 		}
+		if len(r.Tags) > 0 {
+			if retMe[i].Tags, err = td.convertKeyValues(r.Tags); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return retMe, nil
 }
@@ -135,7 +196,10 @@ func (td toDomain) convertKeyValue(tag *json.KeyValue) (model.KeyValue, error) {
 }
 
 func (td toDomain) convertKeyValueOfType(tag *json.KeyValue, vType model.ValueType) (model.KeyValue, error) {
-	tagValue := tag.Value.(string)
+	tagValue, ok := tag.Value.(string)
+	if !ok {
+		return td.convertTypedKeyValue(tag, vType)
+	}
 	switch vType {
 	case model.StringType:
 		return model.String(tag.Key, tagValue), nil
@@ -167,6 +231,34 @@ func (td toDomain) convertKeyValueOfType(tag *json.KeyValue, vType model.ValueTy
 	return model.KeyValue{}, fmt.Errorf("not a valid ValueType string %s", vType.String())
 }
 
+// convertTypedKeyValue converts tags with values of the Go type produced by FromDomain,
+// rather than the strings used by FromDomainEmbedProcess. Int64 values decoded from JSON
+// without type information are float64.
+func (td toDomain) convertTypedKeyValue(tag *json.KeyValue, vType model.ValueType) (model.KeyValue, error) {
+	switch value := tag.Value.(type) {
+	case bool:
+		if vType == model.BoolType {
+			return model.Bool(tag.Key, value), nil
+		}
+	case int64:
+		if vType == model.Int64Type {
+			return model.Int64(tag.Key, value), nil
+		}
+	case float64:
+		if vType == model.Int64Type {
+			return model.Int64(tag.Key, int64(value)), nil
+		}
+		if vType == model.Float64Type {
+			return model.Float64(tag.Key, value), nil
+		}
+	case []byte:
+		if vType == model.BinaryType {
+			return model.Binary(tag.Key, value), nil
+		}
+	}
+	return model.KeyValue{}, fmt.Errorf("invalid %s value %v of tag %s", vType.String(), tag.Value, tag.Key)
+}
+
 func (td toDomain) convertLogs(logs []json.Log) ([]model.Log, error) {
 	retMe := make([]model.Log, len(logs))
 	for i, l := range logs {
@@ -176,6 +176,26 @@ func TestRevertKeyValueOfType(t *testing.T) {
 	assert.EqualError(t, err, "not a valid ValueType string <invalid>")
 }
 
+func TestConvertTypedKeyValue(t *testing.T) {
+	testCases := []struct {
+		tag      jModel.KeyValue
+		expected model.KeyValue
+	}{
+		{tag: jModel.KeyValue{Key: "b", Type: jModel.BoolType, Value: true}, expected: model.Bool("b", true)},
+		{tag: jModel.KeyValue{Key: "i", Type: jModel.Int64Type, Value: int64(-3)}, expected: model.Int64("i", -3)},
+		{tag: jModel.KeyValue{Key: "i", Type: jModel.Int64Type, Value: float64(42)}, expected: model.Int64("i", 42)},
+		{tag: jModel.KeyValue{Key: "f", Type: jModel.Float64Type, Value: 1.5}, expected: model.Float64("f", 1.5)},
+		{tag: jModel.KeyValue{Key: "x", Type: jModel.BinaryType, Value: []byte{1}}, expected: model.Binary("x", []byte{1})},
+	}
+	for _, testCase := range testCases {
+		kv, err := toDomain{}.convertKeyValue(&testCase.tag)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, kv)
+	}
+	_, err := toDomain{}.convertKeyValue(&jModel.KeyValue{Key: "b", Type: jModel.BoolType, Value: 1.5})
+	assert.EqualError(t, err, "invalid bool value 1.5 of tag b")
+}
+
 func TestFailureBadRefs(t *testing.T) {
 	badRefsESSpan, err := loadESSpanFixture(1)
 	require.NoError(t, err)
@@ -188,6 +208,27 @@ func TestFailureBadRefs(t *testing.T) {
 	failingSpanTransform(t, &badRefsESSpan, "not a valid SpanRefType string makeOurOwnCasino")
 }
 
+func TestReferenceTags(t *testing.T) {
+	esSpan, err := loadESSpanFixture(1)
+	require.NoError(t, err)
+	esSpan.References = []jModel.Reference{
+		{
+			RefType: "FOLLOWS_FROM",
+			TraceID: "1",
+			SpanID:  "2",
+			Tags:    []jModel.KeyValue{{Key: "link.reason", Type: "string", Value: "batch"}},
+		},
+	}
+	span, err := SpanToDomain(&esSpan)
+	require.NoError(t, err)
+	require.Len(t, span.References, 1)
+	assert.Equal(t, []model.KeyValue{model.String("link.reason", "batch")}, span.References[0].Tags)
+	assert.Equal(t, esSpan.References, FromDomainEmbedProcess(span).References)
+
+	esSpan.References[0].Tags[0].Type = "badType"
+	failingSpanTransform(t, &esSpan, "not a valid ValueType string badType")
+}
+
 func TestFailureBadTraceIDRefs(t *testing.T) {
 	badRefsESSpan, err := loadESSpanFixture(1)
 	require.NoError(t, err)
@@ -259,3 +300,54 @@ func TestFailureBadParentSpanID(t *testing.T) {
 	badParentSpanIDESSpan.ParentSpanID = "zz"
 	failingSpanTransformAnyMsg(t, &badParentSpanIDESSpan)
 }
+
+func TestTraceToDomain(t *testing.T) {
+	process := model.NewProcess("svc", []model.KeyValue{model.String("host", "h1")})
+	traceID := model.TraceID{High: 1, Low: 2}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:    traceID,
+				SpanID:     1,
+				References: []model.SpanRef{},
+				StartTime:  model.EpochMicrosecondsAsTime(1000),
+				Tags:       []model.KeyValue{model.Int64("n", 1)},
+				Logs:       []model.Log{},
+				Process:    process,
+			},
+			{
+				TraceID:    traceID,
+				SpanID:     2,
+				References: model.MaybeAddParentSpanID(traceID, 1, nil),
+				StartTime:  model.EpochMicrosecondsAsTime(2000),
+				Tags:       []model.KeyValue{},
+				Logs:       []model.Log{},
+				Process:    process,
+			},
+		},
+		Warnings:   []string{"w"},
+		Attributes: model.KeyValues{model.String("tenant", "acme")},
+	}
+	actual, err := TraceToDomain(FromDomain(trace))
+	require.NoError(t, err)
+	assert.Equal(t, trace, actual)
+	assert.True(t, actual.Spans[0].Process == actual.Spans[1].Process, "spans share the process")
+}
+
+func TestTraceToDomainErrors(t *testing.T) {
+	jTrace := &jModel.Trace{
+		Spans:     []jModel.Span{{TraceID: "1", SpanID: "2", ProcessID: "p2"}},
+		Processes: map[jModel.ProcessID]jModel.Process{"p1": {ServiceName: "svc"}},
+	}
+	_, err := TraceToDomain(jTrace)
+	assert.EqualError(t, err, "span 2 refers to unknown process p2")
+
+	jTrace.Spans[0].ProcessID = "p1"
+	jTrace.Attributes = []jModel.KeyValue{{Key: "meh", Type: "badType"}}
+	_, err = TraceToDomain(jTrace)
+	assert.EqualError(t, err, "not a valid ValueType string badType")
+
+	jTrace.Processes["p1"] = jModel.Process{Tags: jTrace.Attributes}
+	_, err = TraceToDomain(jTrace)
+	assert.EqualError(t, err, "not a valid ValueType string badType")
+}
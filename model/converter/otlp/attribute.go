@@ -0,0 +1,68 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"fmt"
+
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// keyValueToAttribute converts KeyValue to an OpenTelemetry attribute.
+// Values of unknown type are converted to their string representation.
+func keyValueToAttribute(kv model.KeyValue) *otlpcommon.KeyValue {
+	value := &otlpcommon.AnyValue{}
+	switch kv.VType {
+	case model.StringType:
+		value.Value = &otlpcommon.AnyValue_StringValue{StringValue: kv.VStr}
+	case model.BoolType:
+		value.Value = &otlpcommon.AnyValue_BoolValue{BoolValue: kv.Bool()}
+	case model.Int64Type:
+		value.Value = &otlpcommon.AnyValue_IntValue{IntValue: kv.Int64()}
+	case model.Float64Type:
+		value.Value = &otlpcommon.AnyValue_DoubleValue{DoubleValue: kv.Float64()}
+	case model.BinaryType:
+		value.Value = &otlpcommon.AnyValue_BytesValue{BytesValue: kv.Binary()}
+	default:
+		value.Value = &otlpcommon.AnyValue_StringValue{StringValue: kv.AsString()}
+	}
+	return &otlpcommon.KeyValue{Key: kv.Key, Value: value}
+}
+
+// attributeToKeyValue converts an OpenTelemetry attribute to KeyValue.
+// It returns an error for values that have no KeyValue equivalent, such as arrays and maps.
+func attributeToKeyValue(attr *otlpcommon.KeyValue) (model.KeyValue, error) {
+	if attr == nil {
+		return model.KeyValue{}, fmt.Errorf("attribute is nil")
+	}
+	switch v := attr.GetValue().GetValue().(type) {
+	case *otlpcommon.AnyValue_StringValue:
+		return model.String(attr.Key, v.StringValue), nil
+	case *otlpcommon.AnyValue_BoolValue:
+		return model.Bool(attr.Key, v.BoolValue), nil
+	case *otlpcommon.AnyValue_IntValue:
+		return model.Int64(attr.Key, v.IntValue), nil
+	case *otlpcommon.AnyValue_DoubleValue:
+		return model.Float64(attr.Key, v.DoubleValue), nil
+	case *otlpcommon.AnyValue_BytesValue:
+		return model.Binary(attr.Key, v.BytesValue), nil
+	case nil:
+		return model.KeyValue{}, fmt.Errorf("attribute %s has no value", attr.Key)
+	default:
+		return model.KeyValue{}, fmt.Errorf("attribute %s has unsupported value type %T", attr.Key, v)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestAttributeRoundTrip(t *testing.T) {
+	testCases := []model.KeyValue{
+		model.String("s", "str"),
+		model.String("s", ""),
+		model.Bool("b", true),
+		model.Bool("b", false),
+		model.Int64("i", math.MinInt64),
+		model.Int64("i", 42),
+		model.Float64("f", 3.14159),
+		model.Float64("f", math.Inf(-1)),
+		model.Binary("bin", []byte{0, 1, 2, 255}),
+	}
+	for _, kv := range testCases {
+		attr := keyValueToAttribute(kv)
+		assert.Equal(t, kv.Key, attr.Key)
+		out, err := attributeToKeyValue(attr)
+		require.NoError(t, err)
+		assert.True(t, kv.Equal(&out), "%+v != %+v", kv, out)
+	}
+}
+
+func TestKeyValueToAttribute(t *testing.T) {
+	attr := keyValueToAttribute(model.Int64("i", 7))
+	assert.Equal(t, int64(7), attr.GetValue().GetIntValue())
+
+	attr = keyValueToAttribute(model.Float64("f", 1.5))
+	assert.Equal(t, 1.5, attr.GetValue().GetDoubleValue())
+
+	attr = keyValueToAttribute(model.KeyValue{Key: "x", VType: model.ValueType(-1)})
+	assert.Equal(t, "unknown type -1", attr.GetValue().GetStringValue())
+}
+
+func TestAttributeToKeyValueErrors(t *testing.T) {
+	testCases := []struct {
+		attr *otlpcommon.KeyValue
+		err  string
+	}{
+		{attr: nil, err: "attribute is nil"},
+		{attr: &otlpcommon.KeyValue{Key: "x"}, err: "attribute x has no value"},
+		{
+			attr: &otlpcommon.KeyValue{
+				Key:   "x",
+				Value: &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_ArrayValue{}},
+			},
+			err: "attribute x has unsupported value type *v1.AnyValue_ArrayValue",
+		},
+	}
+	for _, testCase := range testCases {
+		_, err := attributeToKeyValue(testCase.attr)
+		assert.EqualError(t, err, testCase.err)
+	}
+}
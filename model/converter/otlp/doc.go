@@ -0,0 +1,24 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp allows converting model.Span to and from OpenTelemetry OTLP trace data.
+//
+// Process tags become resource attributes, with the service name as the service.name
+// attribute and the otel.schema_url tag as the schema URL of the resource. Logs become
+// span events named by their `event` field, the parent reference becomes the parent span
+// ID and the other references become links, with the reference tags as link attributes.
+// The span.kind, error, otel.status_code and otel.status_description tags become the span
+// kind and status, and the otel.scope.name and otel.scope.version tags the instrumentation
+// scope.
+package otlp
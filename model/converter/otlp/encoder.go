@@ -0,0 +1,33 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func init() {
+	model.RegisterEncoder(model.OTLPFormat, spanEncoder{})
+}
+
+// spanEncoder encodes a span as OTLP TracesData in the protobuf wire format, see FromDomain.
+type spanEncoder struct{}
+
+func (spanEncoder) Encode(span *model.Span) ([]byte, error) {
+	return proto.Marshal(&otlptrace.TracesData{ResourceSpans: FromDomain([]*model.Span{span})})
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanEncoder(t *testing.T) {
+	encoder, err := model.EncoderFor(model.OTLPFormat)
+	require.NoError(t, err)
+	span := makeTestSpan(1, makeTestProcess("svc"))
+	data, err := encoder.Encode(span)
+	require.NoError(t, err)
+
+	traces := &otlptrace.TracesData{}
+	require.NoError(t, proto.Unmarshal(data, traces))
+	spans, err := ToDomain(traces.ResourceSpans)
+	require.NoError(t, err)
+	expected, err := ToDomain(FromDomain([]*model.Span{span}))
+	require.NoError(t, err)
+	assert.Equal(t, expected, spans)
+
+	_, err = model.DetectSpanFormat(data)
+	assert.EqualError(t, err, "unrecognized span format")
+}
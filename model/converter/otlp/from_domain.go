@@ -0,0 +1,213 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"encoding/binary"
+
+	"github.com/opentracing/opentracing-go/ext"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	serviceNameAttribute   = "service.name"
+	errorTag               = "error"
+	statusCodeTag          = "otel.status_code"
+	statusDescriptionTag   = "otel.status_description"
+	traceStateTag          = "w3c.tracestate"
+	scopeNameTag           = "otel.scope.name"
+	scopeVersionTag        = "otel.scope.version"
+	refTypeAttribute       = "opentracing.ref_type"
+	refTypeChildOf         = "child_of"
+	refTypeFollowsFrom     = "follows_from"
+	statusCodeOK           = "OK"
+	statusCodeError        = "ERROR"
+	spanKindInternalString = "internal"
+)
+
+var spanKindsFromDomain = map[string]otlptrace.Span_SpanKind{
+	string(ext.SpanKindRPCClientEnum): otlptrace.Span_SPAN_KIND_CLIENT,
+	string(ext.SpanKindRPCServerEnum): otlptrace.Span_SPAN_KIND_SERVER,
+	string(ext.SpanKindProducerEnum):  otlptrace.Span_SPAN_KIND_PRODUCER,
+	string(ext.SpanKindConsumerEnum):  otlptrace.Span_SPAN_KIND_CONSUMER,
+	spanKindInternalString:            otlptrace.Span_SPAN_KIND_INTERNAL,
+}
+
+// FromDomain converts model.Span list into OTLP ResourceSpans. Spans with the same
+// (or equal) Process share a ResourceSpans, and spans with the same scope tags share
+// a ScopeSpans, both in the order in which they first appear.
+// Span flags and warnings have no OTLP equivalent and are not converted.
+func FromDomain(spans []*model.Span) []*otlptrace.ResourceSpans {
+	var processes []*model.Process
+	var resources []*otlptrace.ResourceSpans
+	for _, span := range spans {
+		i := findProcess(processes, span.Process)
+		if i < 0 {
+			i = len(processes)
+			processes = append(processes, span.Process)
+			resources = append(resources, convertProcess(span.Process))
+		}
+		resource := resources[i]
+		otlpSpan, scope := convertSpan(span)
+		scopeSpans := findScopeSpans(resource, scope)
+		scopeSpans.Spans = append(scopeSpans.Spans, otlpSpan)
+	}
+	return resources
+}
+
+func findProcess(processes []*model.Process, process *model.Process) int {
+	for i, p := range processes {
+		if p == process || (p != nil && process != nil && p.Equal(process)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func findScopeSpans(resource *otlptrace.ResourceSpans, scope *otlpcommon.InstrumentationScope) *otlptrace.ScopeSpans {
+	for _, scopeSpans := range resource.ScopeSpans {
+		if scopeSpans.Scope.GetName() == scope.GetName() && scopeSpans.Scope.GetVersion() == scope.GetVersion() {
+			return scopeSpans
+		}
+	}
+	scopeSpans := &otlptrace.ScopeSpans{Scope: scope}
+	resource.ScopeSpans = append(resource.ScopeSpans, scopeSpans)
+	return scopeSpans
+}
+
+func convertProcess(process *model.Process) *otlptrace.ResourceSpans {
+	resource := &otlptrace.ResourceSpans{Resource: &otlpresource.Resource{}}
+	if process == nil {
+		return resource
+	}
+	resource.SchemaUrl, _ = process.SchemaURL()
+	attrs := []*otlpcommon.KeyValue{keyValueToAttribute(model.String(serviceNameAttribute, process.ServiceName))}
+	for _, tag := range process.Tags {
+		if tag.Key != model.SchemaURLTagKey {
+			attrs = append(attrs, keyValueToAttribute(tag))
+		}
+	}
+	resource.Resource.Attributes = attrs
+	return resource
+}
+
+func convertSpan(span *model.Span) (*otlptrace.Span, *otlpcommon.InstrumentationScope) {
+	otlpSpan := &otlptrace.Span{
+		TraceId:           traceIDFromDomain(span.TraceID),
+		SpanId:            spanIDFromDomain(span.SpanID),
+		Name:              span.OperationName,
+		StartTimeUnixNano: uint64(span.StartTime.UnixNano()),
+		EndTimeUnixNano:   uint64(span.StartTime.Add(span.Duration).UnixNano()),
+		Events:            convertLogs(span.Logs),
+	}
+	parentID := span.ParentSpanID()
+	if parentID != 0 {
+		otlpSpan.ParentSpanId = spanIDFromDomain(parentID)
+	}
+	parentFound := false
+	for _, ref := range span.References {
+		if !parentFound && parentID != 0 && ref.TraceID == span.TraceID && ref.RefType == model.ChildOf && ref.SpanID == parentID {
+			parentFound = true
+			continue
+		}
+		otlpSpan.Links = append(otlpSpan.Links, convertReference(ref))
+	}
+
+	var scope *otlpcommon.InstrumentationScope
+	status := &otlptrace.Status{}
+	if span.IsError() {
+		status.Code = otlptrace.Status_STATUS_CODE_ERROR
+	}
+	for _, tag := range span.Tags {
+		switch {
+		case tag.Key == string(ext.SpanKind) && spanKindsFromDomain[tag.AsString()] != otlptrace.Span_SPAN_KIND_UNSPECIFIED:
+			otlpSpan.Kind = spanKindsFromDomain[tag.AsString()]
+		case tag.Key == errorTag && tag.VType == model.BoolType && tag.Bool():
+		case tag.Key == statusCodeTag && (tag.AsString() == statusCodeOK || tag.AsString() == statusCodeError):
+			status.Code = otlptrace.Status_STATUS_CODE_OK
+			if tag.AsString() == statusCodeError {
+				status.Code = otlptrace.Status_STATUS_CODE_ERROR
+			}
+		case tag.Key == statusDescriptionTag && tag.VType == model.StringType:
+			status.Message = tag.VStr
+		case tag.Key == traceStateTag && tag.VType == model.StringType:
+			otlpSpan.TraceState = tag.VStr
+		case (tag.Key == scopeNameTag || tag.Key == scopeVersionTag) && tag.VType == model.StringType:
+			if scope == nil {
+				scope = &otlpcommon.InstrumentationScope{}
+			}
+			if tag.Key == scopeNameTag {
+				scope.Name = tag.VStr
+			} else {
+				scope.Version = tag.VStr
+			}
+		default:
+			otlpSpan.Attributes = append(otlpSpan.Attributes, keyValueToAttribute(tag))
+		}
+	}
+	if status.Code != otlptrace.Status_STATUS_CODE_UNSET || status.Message != "" {
+		otlpSpan.Status = status
+	}
+	return otlpSpan, scope
+}
+
+func convertLogs(logs []model.Log) []*otlptrace.Span_Event {
+	if len(logs) == 0 {
+		return nil
+	}
+	events := make([]*otlptrace.Span_Event, len(logs))
+	for i, log := range logs {
+		e, _ := log.Event()
+		event := &otlptrace.Span_Event{TimeUnixNano: uint64(log.Timestamp.UnixNano()), Name: e.Name}
+		for _, field := range e.Attributes {
+			event.Attributes = append(event.Attributes, keyValueToAttribute(field))
+		}
+		events[i] = event
+	}
+	return events
+}
+
+func convertReference(ref model.SpanRef) *otlptrace.Span_Link {
+	refType := refTypeFollowsFrom
+	if ref.RefType == model.ChildOf {
+		refType = refTypeChildOf
+	}
+	link := &otlptrace.Span_Link{
+		TraceId:    traceIDFromDomain(ref.TraceID),
+		SpanId:     spanIDFromDomain(ref.SpanID),
+		Attributes: []*otlpcommon.KeyValue{keyValueToAttribute(model.String(refTypeAttribute, refType))},
+	}
+	for _, tag := range ref.Tags {
+		link.Attributes = append(link.Attributes, keyValueToAttribute(tag))
+	}
+	return link
+}
+
+func traceIDFromDomain(traceID model.TraceID) []byte {
+	id := make([]byte, 16)
+	binary.BigEndian.PutUint64(id[:8], traceID.High)
+	binary.BigEndian.PutUint64(id[8:], traceID.Low)
+	return id
+}
+
+func spanIDFromDomain(spanID model.SpanID) []byte {
+	id := make([]byte, 8)
+	binary.BigEndian.PutUint64(id, uint64(spanID))
+	return id
+}
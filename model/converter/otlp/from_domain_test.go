@@ -0,0 +1,173 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var testTraceID = model.TraceID{High: 0x0102030405060708, Low: 0x090a0b0c0d0e0f10}
+
+func makeTestSpan(spanID model.SpanID, process *model.Process) *model.Span {
+	start := time.Unix(100, 500).UTC()
+	return &model.Span{
+		TraceID:       testTraceID,
+		SpanID:        spanID,
+		OperationName: "GET /api",
+		References: []model.SpanRef{
+			model.NewChildOfRef(testTraceID, 1),
+			{RefType: model.FollowsFrom, TraceID: testTraceID, SpanID: 2, Tags: []model.KeyValue{model.String("link.reason", "batch")}},
+			model.NewChildOfRef(model.TraceID{Low: 7}, 3),
+		},
+		StartTime: start,
+		Duration:  time.Millisecond,
+		Tags: []model.KeyValue{
+			model.String("http.method", "GET"),
+			model.Int64("http.status_code", 500),
+			model.String("span.kind", "server"),
+			model.Bool("error", true),
+			model.String("otel.status_description", "internal error"),
+			model.String("w3c.tracestate", "rojo=00f067aa0ba902b7"),
+			model.String("otel.scope.name", "net/http"),
+			model.String("otel.scope.version", "1.0"),
+		},
+		Logs: []model.Log{
+			{
+				Timestamp: start.Add(time.Microsecond),
+				Fields:    []model.KeyValue{model.String("event", "exception"), model.String("message", "boom")},
+			},
+			{
+				Timestamp: start.Add(2 * time.Microsecond),
+				Fields:    []model.KeyValue{model.Float64("retry.delay", 0.5)},
+			},
+		},
+		Process: process,
+	}
+}
+
+func makeTestProcess(serviceName string) *model.Process {
+	return &model.Process{
+		ServiceName: serviceName,
+		Tags: []model.KeyValue{
+			model.String("host.name", "h1"),
+			model.Binary("ip", []byte{10, 0, 0, 1}),
+			model.String(model.SchemaURLTagKey, "https://opentelemetry.io/schemas/1.21.0"),
+		},
+	}
+}
+
+func TestFromDomain(t *testing.T) {
+	resources := FromDomain([]*model.Span{makeTestSpan(10, makeTestProcess("frontend"))})
+	require.Len(t, resources, 1)
+	resource := resources[0]
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.21.0", resource.SchemaUrl)
+	attrs := resource.Resource.Attributes
+	require.Len(t, attrs, 3, "schema URL tag is not an attribute")
+	assert.Equal(t, "service.name", attrs[0].Key)
+	assert.Equal(t, "frontend", attrs[0].GetValue().GetStringValue())
+	assert.Equal(t, "host.name", attrs[1].Key)
+
+	require.Len(t, resource.ScopeSpans, 1)
+	assert.Equal(t, "net/http", resource.ScopeSpans[0].Scope.GetName())
+	assert.Equal(t, "1.0", resource.ScopeSpans[0].Scope.GetVersion())
+	require.Len(t, resource.ScopeSpans[0].Spans, 1)
+	span := resource.ScopeSpans[0].Spans[0]
+
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, span.TraceId)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 10}, span.SpanId)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1}, span.ParentSpanId)
+	assert.Equal(t, "GET /api", span.Name)
+	assert.Equal(t, uint64(100000000500), span.StartTimeUnixNano)
+	assert.Equal(t, uint64(100001000500), span.EndTimeUnixNano)
+	assert.Equal(t, otlptrace.Span_SPAN_KIND_SERVER, span.Kind)
+	assert.Equal(t, otlptrace.Status_STATUS_CODE_ERROR, span.Status.Code)
+	assert.Equal(t, "internal error", span.Status.Message)
+	assert.Equal(t, "rojo=00f067aa0ba902b7", span.TraceState)
+
+	require.Len(t, span.Attributes, 2, "tags mapped to span fields are not attributes")
+	assert.Equal(t, "http.method", span.Attributes[0].Key)
+	assert.Equal(t, int64(500), span.Attributes[1].GetValue().GetIntValue())
+
+	require.Len(t, span.Links, 2, "the parent reference is not a link")
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 2}, span.Links[0].SpanId)
+	assert.Equal(t, "follows_from", span.Links[0].Attributes[0].GetValue().GetStringValue())
+	require.Len(t, span.Links[0].Attributes, 2, "reference tags are link attributes")
+	assert.Equal(t, "link.reason", span.Links[0].Attributes[1].Key)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 7}, span.Links[1].TraceId)
+	assert.Equal(t, "child_of", span.Links[1].Attributes[0].GetValue().GetStringValue())
+
+	require.Len(t, span.Events, 2)
+	assert.Equal(t, "exception", span.Events[0].Name)
+	assert.Equal(t, uint64(100000001500), span.Events[0].TimeUnixNano)
+	require.Len(t, span.Events[0].Attributes, 1)
+	assert.Equal(t, "message", span.Events[0].Attributes[0].Key)
+	assert.Equal(t, "", span.Events[1].Name)
+	assert.Equal(t, 0.5, span.Events[1].Attributes[0].GetValue().GetDoubleValue())
+}
+
+func TestFromDomainGrouping(t *testing.T) {
+	frontend := makeTestProcess("frontend")
+	backend := makeTestProcess("backend")
+	s1, s2, s3, s4 := makeTestSpan(1, frontend), makeTestSpan(2, backend), makeTestSpan(3, makeTestProcess("frontend")), makeTestSpan(4, frontend)
+	s4.Tags = []model.KeyValue{model.String("otel.scope.name", "grpc")}
+	resources := FromDomain([]*model.Span{s1, s2, s3, s4})
+	require.Len(t, resources, 2, "equal processes share a resource")
+	require.Len(t, resources[0].ScopeSpans, 2)
+	assert.Len(t, resources[0].ScopeSpans[0].Spans, 2)
+	assert.Equal(t, "grpc", resources[0].ScopeSpans[1].Scope.GetName())
+	assert.Len(t, resources[1].ScopeSpans[0].Spans, 1)
+}
+
+func TestFromDomainStatus(t *testing.T) {
+	testCases := []struct {
+		tags    []model.KeyValue
+		status  *otlptrace.Status
+		attrLen int
+	}{
+		{tags: nil, status: nil},
+		{tags: []model.KeyValue{model.String("otel.status_code", "OK")}, status: &otlptrace.Status{Code: otlptrace.Status_STATUS_CODE_OK}},
+		{tags: []model.KeyValue{model.String("otel.status_code", "ERROR")}, status: &otlptrace.Status{Code: otlptrace.Status_STATUS_CODE_ERROR}},
+		{tags: []model.KeyValue{model.String("error", "true")}, status: &otlptrace.Status{Code: otlptrace.Status_STATUS_CODE_ERROR}, attrLen: 1},
+		{tags: []model.KeyValue{model.Bool("error", false)}, status: nil, attrLen: 1},
+		{tags: []model.KeyValue{model.String("span.kind", "unknown")}, status: nil, attrLen: 1},
+	}
+	for i, testCase := range testCases {
+		span := makeTestSpan(1, nil)
+		span.Tags = testCase.tags
+		resources := FromDomain([]*model.Span{span})
+		otlpSpan := resources[0].ScopeSpans[0].Spans[0]
+		assert.Equal(t, testCase.status, otlpSpan.Status, "test case %d", i)
+		assert.Len(t, otlpSpan.Attributes, testCase.attrLen, "test case %d", i)
+		assert.Equal(t, otlptrace.Span_SPAN_KIND_UNSPECIFIED, otlpSpan.Kind, "test case %d", i)
+	}
+}
+
+func TestFromDomainNilProcess(t *testing.T) {
+	span := makeTestSpan(1, nil)
+	span.References = nil
+	resources := FromDomain([]*model.Span{span})
+	require.Len(t, resources, 1)
+	assert.Empty(t, resources[0].Resource.Attributes)
+	assert.Empty(t, resources[0].SchemaUrl)
+	assert.Nil(t, resources[0].ScopeSpans[0].Spans[0].ParentSpanId)
+	assert.Empty(t, FromDomain(nil))
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var spanKindsToDomain = map[otlptrace.Span_SpanKind]string{
+	otlptrace.Span_SPAN_KIND_CLIENT:   string(ext.SpanKindRPCClientEnum),
+	otlptrace.Span_SPAN_KIND_SERVER:   string(ext.SpanKindRPCServerEnum),
+	otlptrace.Span_SPAN_KIND_PRODUCER: string(ext.SpanKindProducerEnum),
+	otlptrace.Span_SPAN_KIND_CONSUMER: string(ext.SpanKindConsumerEnum),
+	otlptrace.Span_SPAN_KIND_INTERNAL: spanKindInternalString,
+}
+
+// ToDomain converts OTLP ResourceSpans into model.Span list. The spans of a ResourceSpans
+// share the same Process. It returns an error for invalid IDs and for attributes whose
+// values have no KeyValue equivalent, such as arrays and maps.
+func ToDomain(resourceSpans []*otlptrace.ResourceSpans) ([]*model.Span, error) {
+	var spans []*model.Span
+	for _, resource := range resourceSpans {
+		process, err := convertResource(resource)
+		if err != nil {
+			return nil, err
+		}
+		for _, scopeSpans := range resource.ScopeSpans {
+			for _, otlpSpan := range scopeSpans.Spans {
+				span, err := convertSpanToDomain(otlpSpan, scopeSpans.Scope)
+				if err != nil {
+					return nil, err
+				}
+				span.Process = process
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans, nil
+}
+
+func convertResource(resource *otlptrace.ResourceSpans) (*model.Process, error) {
+	var serviceName string
+	var tags []model.KeyValue
+	serviceNameFound := false
+	for _, attr := range resource.GetResource().GetAttributes() {
+		tag, err := attributeToKeyValue(attr)
+		if err != nil {
+			return nil, err
+		}
+		if !serviceNameFound && tag.Key == serviceNameAttribute && tag.VType == model.StringType {
+			serviceName = tag.VStr
+			serviceNameFound = true
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if resource.SchemaUrl != "" {
+		tags = append(tags, model.String(model.SchemaURLTagKey, resource.SchemaUrl))
+	}
+	return model.NewProcess(serviceName, tags), nil
+}
+
+func convertSpanToDomain(otlpSpan *otlptrace.Span, scope *otlpcommon.InstrumentationScope) (*model.Span, error) {
+	traceID, err := traceIDToDomain(otlpSpan.TraceId)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := spanIDToDomain(otlpSpan.SpanId)
+	if err != nil {
+		return nil, err
+	}
+	startTime := time.Unix(0, int64(otlpSpan.StartTimeUnixNano)).UTC()
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: otlpSpan.Name,
+		StartTime:     startTime,
+		Duration:      time.Unix(0, int64(otlpSpan.EndTimeUnixNano)).Sub(startTime),
+	}
+	if len(otlpSpan.ParentSpanId) > 0 {
+		parentID, err := spanIDToDomain(otlpSpan.ParentSpanId)
+		if err != nil {
+			return nil, err
+		}
+		span.References = append(span.References, model.NewChildOfRef(traceID, parentID))
+	}
+	for _, link := range otlpSpan.Links {
+		ref, err := convertLink(link)
+		if err != nil {
+			return nil, err
+		}
+		span.References = append(span.References, ref)
+	}
+	if span.Tags, err = convertAttributes(otlpSpan.Attributes); err != nil {
+		return nil, err
+	}
+	if kind, ok := spanKindsToDomain[otlpSpan.Kind]; ok {
+		span.Tags = append(span.Tags, model.String(string(ext.SpanKind), kind))
+	}
+	switch otlpSpan.Status.GetCode() {
+	case otlptrace.Status_STATUS_CODE_ERROR:
+		if _, ok := model.KeyValues(span.Tags).FindByKey(errorTag); !ok {
+			span.Tags = append(span.Tags, model.Bool(errorTag, true))
+		}
+	case otlptrace.Status_STATUS_CODE_OK:
+		span.Tags = append(span.Tags, model.String(statusCodeTag, statusCodeOK))
+	}
+	if message := otlpSpan.Status.GetMessage(); message != "" {
+		span.Tags = append(span.Tags, model.String(statusDescriptionTag, message))
+	}
+	if otlpSpan.TraceState != "" {
+		span.Tags = append(span.Tags, model.String(traceStateTag, otlpSpan.TraceState))
+	}
+	if name := scope.GetName(); name != "" {
+		span.Tags = append(span.Tags, model.String(scopeNameTag, name))
+	}
+	if version := scope.GetVersion(); version != "" {
+		span.Tags = append(span.Tags, model.String(scopeVersionTag, version))
+	}
+	for _, event := range otlpSpan.Events {
+		attributes, err := convertAttributes(event.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		timestamp := time.Unix(0, int64(event.TimeUnixNano)).UTC()
+		span.AddEvent(event.Name, timestamp, attributes...)
+	}
+	return span, nil
+}
+
+// convertLink converts a link to a reference, of the type given by the
+// opentracing.ref_type attribute, or FollowsFrom if it is not set, with
+// the other attributes as reference tags.
+func convertLink(link *otlptrace.Span_Link) (model.SpanRef, error) {
+	traceID, err := traceIDToDomain(link.TraceId)
+	if err != nil {
+		return model.SpanRef{}, err
+	}
+	spanID, err := spanIDToDomain(link.SpanId)
+	if err != nil {
+		return model.SpanRef{}, err
+	}
+	ref := model.NewFollowsFromRef(traceID, spanID)
+	for _, attr := range link.Attributes {
+		if attr.GetKey() == refTypeAttribute {
+			if attr.GetValue().GetStringValue() == refTypeChildOf {
+				ref.RefType = model.ChildOf
+			}
+			continue
+		}
+		tag, err := attributeToKeyValue(attr)
+		if err != nil {
+			return model.SpanRef{}, err
+		}
+		ref.Tags = append(ref.Tags, tag)
+	}
+	return ref, nil
+}
+
+func convertAttributes(attrs []*otlpcommon.KeyValue) ([]model.KeyValue, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	tags := make([]model.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		tag, err := attributeToKeyValue(attr)
+		if err != nil {
+			return nil, err
+		}
+		tags[i] = tag
+	}
+	return tags, nil
+}
+
+func traceIDToDomain(id []byte) (model.TraceID, error) {
+	if len(id) != 16 {
+		return model.TraceID{}, fmt.Errorf("trace ID must be 16 bytes, got %d", len(id))
+	}
+	return model.TraceID{
+		High: binary.BigEndian.Uint64(id[:8]),
+		Low:  binary.BigEndian.Uint64(id[8:]),
+	}, nil
+}
+
+func spanIDToDomain(id []byte) (model.SpanID, error) {
+	if len(id) != 8 {
+		return model.SpanID(0), fmt.Errorf("span ID must be 8 bytes, got %d", len(id))
+	}
+	return model.SpanID(binary.BigEndian.Uint64(id)), nil
+}
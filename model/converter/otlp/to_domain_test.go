@@ -0,0 +1,160 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otlpcommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpresource "go.opentelemetry.io/proto/otlp/resource/v1"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestRoundTrip(t *testing.T) {
+	frontend := makeTestProcess("frontend")
+	backend := makeTestProcess("backend")
+	spans := []*model.Span{
+		makeTestSpan(10, frontend),
+		makeTestSpan(11, frontend),
+		makeTestSpan(12, backend),
+	}
+	spans[1].References = nil
+	spans[1].Tags = []model.KeyValue{model.String("span.kind", "internal"), model.String("otel.status_code", "OK")}
+	spans[1].Logs = nil
+
+	actual, err := ToDomain(FromDomain(spans))
+	require.NoError(t, err)
+	assert.Equal(t, spans, actual)
+	assert.True(t, actual[0].Process == actual[1].Process, "spans of a resource share the process")
+}
+
+func TestToDomainDefaults(t *testing.T) {
+	resources := []*otlptrace.ResourceSpans{
+		{
+			ScopeSpans: []*otlptrace.ScopeSpans{{
+				Spans: []*otlptrace.Span{{
+					TraceId: make([]byte, 16),
+					SpanId:  []byte{0, 0, 0, 0, 0, 0, 0, 1},
+					Links:   []*otlptrace.Span_Link{{TraceId: make([]byte, 16), SpanId: []byte{0, 0, 0, 0, 0, 0, 0, 2}}},
+					Status:  &otlptrace.Status{Code: otlptrace.Status_STATUS_CODE_ERROR},
+					Attributes: []*otlpcommon.KeyValue{
+						keyValueToAttribute(model.String("error", "yes")),
+					},
+				}},
+			}},
+		},
+	}
+	spans, err := ToDomain(resources)
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, &model.Process{}, span.Process)
+	assert.Equal(t, []model.SpanRef{model.NewFollowsFromRef(model.TraceID{}, 2)}, span.References,
+		"links without ref type are follows-from references")
+	assert.Equal(t, []model.KeyValue{model.String("error", "yes")}, span.Tags, "existing error tag is kept")
+}
+
+func TestToDomainProcessTagsSorted(t *testing.T) {
+	resource := func(keys ...string) *otlptrace.ResourceSpans {
+		var attrs []*otlpcommon.KeyValue
+		for _, key := range keys {
+			attrs = append(attrs, keyValueToAttribute(model.String(key, "v")))
+		}
+		return &otlptrace.ResourceSpans{
+			Resource: &otlpresource.Resource{Attributes: attrs},
+			ScopeSpans: []*otlptrace.ScopeSpans{{
+				Spans: []*otlptrace.Span{{TraceId: make([]byte, 16), SpanId: []byte{0, 0, 0, 0, 0, 0, 0, 1}}},
+			}},
+		}
+	}
+	spans, err := ToDomain([]*otlptrace.ResourceSpans{resource("b", "a"), resource("a", "b")})
+	require.NoError(t, err)
+	require.Len(t, spans, 2)
+	assert.Equal(t, []model.KeyValue{model.String("a", "v"), model.String("b", "v")}, spans[0].Process.Tags)
+	assert.True(t, spans[0].Process.Equal(spans[1].Process))
+	hash0, err := model.HashCode(spans[0].Process)
+	require.NoError(t, err)
+	hash1, err := model.HashCode(spans[1].Process)
+	require.NoError(t, err)
+	assert.Equal(t, hash0, hash1)
+}
+
+func TestToDomainErrors(t *testing.T) {
+	validSpan := func() *otlptrace.Span {
+		return &otlptrace.Span{TraceId: make([]byte, 16), SpanId: make([]byte, 8)}
+	}
+	arrayAttr := &otlpcommon.KeyValue{
+		Key:   "list",
+		Value: &otlpcommon.AnyValue{Value: &otlpcommon.AnyValue_ArrayValue{}},
+	}
+	testCases := []struct {
+		modify   func(span *otlptrace.Span, resource *otlptrace.ResourceSpans)
+		expected string
+	}{
+		{
+			modify:   func(span *otlptrace.Span, _ *otlptrace.ResourceSpans) { span.TraceId = []byte{1} },
+			expected: "trace ID must be 16 bytes, got 1",
+		},
+		{
+			modify:   func(span *otlptrace.Span, _ *otlptrace.ResourceSpans) { span.SpanId = nil },
+			expected: "span ID must be 8 bytes, got 0",
+		},
+		{
+			modify:   func(span *otlptrace.Span, _ *otlptrace.ResourceSpans) { span.ParentSpanId = []byte{1, 2} },
+			expected: "span ID must be 8 bytes, got 2",
+		},
+		{
+			modify: func(span *otlptrace.Span, _ *otlptrace.ResourceSpans) {
+				span.Links = []*otlptrace.Span_Link{{TraceId: make([]byte, 15)}}
+			},
+			expected: "trace ID must be 16 bytes, got 15",
+		},
+		{
+			modify: func(span *otlptrace.Span, _ *otlptrace.ResourceSpans) {
+				span.Links = []*otlptrace.Span_Link{{TraceId: make([]byte, 16)}}
+			},
+			expected: "span ID must be 8 bytes, got 0",
+		},
+		{
+			modify: func(span *otlptrace.Span, _ *otlptrace.ResourceSpans) {
+				span.Attributes = []*otlpcommon.KeyValue{arrayAttr}
+			},
+			expected: "attribute list has unsupported value type *v1.AnyValue_ArrayValue",
+		},
+		{
+			modify: func(span *otlptrace.Span, _ *otlptrace.ResourceSpans) {
+				span.Events = []*otlptrace.Span_Event{{Attributes: []*otlpcommon.KeyValue{arrayAttr}}}
+			},
+			expected: "attribute list has unsupported value type *v1.AnyValue_ArrayValue",
+		},
+		{
+			modify: func(_ *otlptrace.Span, resource *otlptrace.ResourceSpans) {
+				resource.Resource = &otlpresource.Resource{Attributes: []*otlpcommon.KeyValue{arrayAttr}}
+			},
+			expected: "attribute list has unsupported value type *v1.AnyValue_ArrayValue",
+		},
+	}
+	for _, testCase := range testCases {
+		span := validSpan()
+		resource := &otlptrace.ResourceSpans{ScopeSpans: []*otlptrace.ScopeSpans{{Spans: []*otlptrace.Span{span}}}}
+		testCase.modify(span, resource)
+		_, err := ToDomain([]*otlptrace.ResourceSpans{resource})
+		assert.EqualError(t, err, testCase.expected)
+	}
+}
@@ -41,6 +41,16 @@ func FromDomainSpan(span *model.Span) *jaeger.Span {
 	return dToJ.transformSpan(span)
 }
 
+// FromDomainProcess takes a model.Process and converts it into a jaeger.Process.
+// A nil process is converted to nil.
+func FromDomainProcess(process *model.Process) *jaeger.Process {
+	if process == nil {
+		return nil
+	}
+	dToJ := domainToJaegerTransformer{}
+	return dToJ.transformProcess(process)
+}
+
 type domainToJaegerTransformer struct{}
 
 func (d domainToJaegerTransformer) keyValueToTag(kv *model.KeyValue) *jaeger.Tag {
@@ -131,6 +141,13 @@ func (d domainToJaegerTransformer) convertSpanRefs(refs []model.SpanRef) []*jaeg
 	return jaegerSpanRefs
 }
 
+func (d domainToJaegerTransformer) transformProcess(process *model.Process) *jaeger.Process {
+	return &jaeger.Process{
+		ServiceName: process.ServiceName,
+		Tags:        d.convertKeyValuesToTags(process.Tags),
+	}
+}
+
 func (d domainToJaegerTransformer) transformSpan(span *model.Span) *jaeger.Span {
 	tags := d.convertKeyValuesToTags(span.Tags)
 	logs := d.convertLogs(span.Logs)
@@ -15,9 +15,12 @@
 package jaeger
 
 import (
+	"math/rand"
 	"testing"
 
+	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jaegertracing/jaeger/model"
 	j "github.com/jaegertracing/jaeger/thrift-gen/jaeger"
@@ -84,3 +87,84 @@ func TestKeyValueToTag(t *testing.T) {
 	assert.Equal(t, "Error", jaegerTag.Key)
 	assert.Equal(t, "No suitable tag type found for: -1", *jaegerTag.VStr)
 }
+
+func TestFromDomainRoundTripRandomSpans(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		process := &model.Process{ServiceName: "service", Tags: randomKeyValues(r)}
+		spans := make([]*model.Span, 1+r.Intn(3))
+		for k := range spans {
+			spans[k] = randomSpan(r, process)
+		}
+
+		batch := &j.Batch{Process: FromDomainProcess(process), Spans: FromDomain(spans)}
+		data, err := thrift.NewTSerializer().Write(batch)
+		require.NoError(t, err)
+		decoded := &j.Batch{}
+		require.NoError(t, thrift.NewTDeserializer().Read(decoded, data))
+
+		assert.Equal(t, spans, ToDomain(decoded.Spans, decoded.Process), "iteration %d", i)
+	}
+}
+
+func TestFromDomainProcessNil(t *testing.T) {
+	assert.Nil(t, FromDomainProcess(nil))
+}
+
+// randomSpan generates a span with a 128-bit trace ID and microsecond precision timestamps,
+// which is the precision of jaeger.thrift.
+func randomSpan(r *rand.Rand, process *model.Process) *model.Span {
+	traceID := model.TraceID{High: r.Uint64(), Low: r.Uint64()}
+	var refs []model.SpanRef
+	for i := r.Intn(4); i > 0; i-- {
+		ref := model.SpanRef{
+			RefType: model.SpanRefType(r.Intn(2)),
+			TraceID: traceID,
+			SpanID:  model.SpanID(r.Uint64() | 1),
+		}
+		if r.Intn(3) == 0 {
+			ref.TraceID = model.TraceID{High: r.Uint64(), Low: r.Uint64()}
+		}
+		refs = append(refs, ref)
+	}
+	var logs []model.Log
+	for i := r.Intn(3); i > 0; i-- {
+		logs = append(logs, model.Log{
+			Timestamp: model.EpochMicrosecondsAsTime(uint64(r.Int63n(1 << 52))),
+			Fields:    randomKeyValues(r),
+		})
+	}
+	return &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.SpanID(r.Uint64() | 1),
+		OperationName: "op",
+		References:    refs,
+		Flags:         model.Flags(r.Uint32()),
+		StartTime:     model.EpochMicrosecondsAsTime(uint64(r.Int63n(1 << 52))),
+		Duration:      model.MicrosecondsAsDuration(uint64(r.Int63n(1 << 32))),
+		Tags:          randomKeyValues(r),
+		Logs:          logs,
+		Process:       process,
+	}
+}
+
+func randomKeyValues(r *rand.Rand) model.KeyValues {
+	var kvs model.KeyValues
+	for i := r.Intn(6); i > 0; i-- {
+		switch r.Intn(5) {
+		case 0:
+			kvs = append(kvs, model.String("s", "value"))
+		case 1:
+			kvs = append(kvs, model.Bool("b", r.Intn(2) == 0))
+		case 2:
+			kvs = append(kvs, model.Int64("i", int64(r.Uint64())))
+		case 3:
+			kvs = append(kvs, model.Float64("f", r.NormFloat64()))
+		default:
+			blob := make([]byte, r.Intn(8))
+			r.Read(blob)
+			kvs = append(kvs, model.Binary("bin", blob))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zipkin converts between model.Span and the Zipkin v2 JSON format, documented at
+// https://zipkin.io/zipkin-api/#/default/post_spans.
+//
+// The local endpoint of a Zipkin span becomes the Process, with the service name and
+// the IP address in the "ip" tag, and the remote endpoint becomes the peer.* span tags.
+// The kind becomes the span.kind tag and the annotations become span logs with the
+// annotation value in the "event" field (or, for annotations holding a JSON object, with
+// one field per object member).
+//
+// Zipkin allows the client and the server side of an RPC to share a span ID, marking
+// the server span as shared. Like the Zipkin Thrift converter, ToDomain keeps the shared
+// span ID, which adjuster.SpanIDDeduper later replaces. FromDomain marks a server span
+// as shared when the client span with the same ID is among the converted spans, and
+// splits spans that record both sides of an RPC in Zipkin core annotations
+// (see model.Span.SplitSharedRPC) into a client and a shared server span.
+package zipkin
@@ -0,0 +1,32 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"encoding/json"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func init() {
+	model.RegisterEncoder(model.ZipkinFormat, spanEncoder{})
+}
+
+// spanEncoder encodes a span as a JSON list of Zipkin v2 spans, see FromDomain.
+type spanEncoder struct{}
+
+func (spanEncoder) Encode(span *model.Span) ([]byte, error) {
+	return json.Marshal(FromDomain([]*model.Span{span}))
+}
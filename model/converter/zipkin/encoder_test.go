@@ -0,0 +1,40 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanEncoder(t *testing.T) {
+	encoder, err := model.EncoderFor(model.ZipkinFormat)
+	require.NoError(t, err)
+	span := makeTestSpan(1, "server", model.NewProcess("svc", nil))
+	data, err := encoder.Encode(span)
+	require.NoError(t, err)
+
+	var zSpans []*Span
+	require.NoError(t, json.Unmarshal(data, &zSpans))
+	assert.Equal(t, FromDomain([]*model.Span{span}), zSpans)
+
+	_, err = model.DetectSpanFormat(data)
+	assert.EqualError(t, err, "unrecognized span format")
+}
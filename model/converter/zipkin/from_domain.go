@@ -0,0 +1,194 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var spanKindsFromDomain = map[string]string{
+	string(ext.SpanKindRPCClientEnum): KindClient,
+	string(ext.SpanKindRPCServerEnum): KindServer,
+	string(ext.SpanKindProducerEnum):  KindProducer,
+	string(ext.SpanKindConsumerEnum):  KindConsumer,
+}
+
+// FromDomain converts model.Span list into Zipkin v2 spans.
+// References other than the parent, process tags other than the IP address,
+// and span warnings have no Zipkin equivalent and are not converted.
+func FromDomain(spans []*model.Span) []*Span {
+	clientSpanIDs := make(map[model.SpanID]struct{})
+	for _, span := range spans {
+		if span.IsRPCClient() {
+			clientSpanIDs[span.SpanID] = struct{}{}
+		}
+	}
+	zSpans := make([]*Span, 0, len(spans))
+	for _, span := range spans {
+		if client, server, ok := span.SplitSharedRPC(); ok {
+			zClient := fromDomainSpan(client)
+			zServer := fromDomainSpan(server)
+			zServer.ID = zClient.ID
+			zServer.ParentID = zClient.ParentID
+			zServer.Shared = true
+			zSpans = append(zSpans, zClient, zServer)
+			continue
+		}
+		zSpan := fromDomainSpan(span)
+		if _, ok := clientSpanIDs[span.SpanID]; ok && span.IsRPCServer() {
+			zSpan.Shared = true
+		}
+		zSpans = append(zSpans, zSpan)
+	}
+	return zSpans
+}
+
+func fromDomainSpan(span *model.Span) *Span {
+	zSpan := &Span{
+		TraceID:       traceIDToHex(span.TraceID),
+		ID:            spanIDToHex(span.SpanID),
+		Name:          span.OperationName,
+		Timestamp:     model.TimeAsEpochMicroseconds(span.StartTime),
+		Duration:      model.DurationAsMicroseconds(span.Duration),
+		Debug:         span.Flags.IsDebug(),
+		LocalEndpoint: fromDomainProcess(span.Process),
+		Annotations:   fromDomainLogs(span.Logs),
+	}
+	if parentID := span.ParentSpanID(); parentID != 0 {
+		zSpan.ParentID = spanIDToHex(parentID)
+	}
+	fromDomainTags(span.Tags, zSpan)
+	return zSpan
+}
+
+// fromDomainTags sets the kind and the remote endpoint of zSpan from the span.kind
+// and peer.* tags and converts the remaining tags to strings.
+func fromDomainTags(tags []model.KeyValue, zSpan *Span) {
+	remote := Endpoint{}
+	for _, tag := range tags {
+		switch tag.Key {
+		case string(ext.SpanKind):
+			if kind, ok := spanKindsFromDomain[tag.AsString()]; ok {
+				zSpan.Kind = kind
+				continue
+			}
+		case string(ext.PeerService):
+			remote.ServiceName = tag.AsString()
+			continue
+		case string(ext.PeerHostIPv4):
+			if ipv4, ok := ipv4FromTag(tag); ok {
+				remote.IPv4 = ipv4
+				continue
+			}
+		case string(ext.PeerHostIPv6):
+			if tag.VType == model.BinaryType {
+				remote.IPv6 = net.IP(tag.Binary()).String()
+			} else {
+				remote.IPv6 = tag.AsString()
+			}
+			continue
+		case string(ext.PeerPort):
+			if port, err := strconv.ParseUint(tag.AsString(), 10, 16); err == nil {
+				remote.Port = int32(port)
+				continue
+			}
+		}
+		if zSpan.Tags == nil {
+			zSpan.Tags = make(map[string]string)
+		}
+		zSpan.Tags[tag.Key] = tag.AsString()
+	}
+	if remote != (Endpoint{}) {
+		zSpan.RemoteEndpoint = &remote
+	}
+}
+
+func fromDomainLogs(logs []model.Log) []Annotation {
+	var annotations []Annotation
+	for _, log := range logs {
+		if len(log.Fields) == 0 {
+			continue
+		}
+		annotations = append(annotations, Annotation{
+			Timestamp: model.TimeAsEpochMicroseconds(log.Timestamp),
+			Value:     fromDomainLogFields(log.Fields),
+		})
+	}
+	return annotations
+}
+
+// fromDomainLogFields uses the value of a lone "event" field as the annotation value,
+// and encodes all other fields as a JSON object.
+func fromDomainLogFields(fields []model.KeyValue) string {
+	if len(fields) == 1 && fields[0].Key == model.EventKey {
+		return fields[0].AsString()
+	}
+	members := make(map[string]string, len(fields))
+	for _, field := range fields {
+		members[field.Key] = field.AsString()
+	}
+	// encoding a map of strings cannot fail
+	out, _ := json.Marshal(members)
+	return string(out)
+}
+
+func fromDomainProcess(process *model.Process) *Endpoint {
+	if process == nil {
+		return nil
+	}
+	endpoint := &Endpoint{ServiceName: process.ServiceName}
+	if ipTag, ok := model.KeyValues(process.Tags).FindByKey(IPTagName); ok {
+		if ipv4, ok := ipv4FromTag(ipTag); ok {
+			endpoint.IPv4 = ipv4
+		} else if ip := net.ParseIP(ipTag.AsString()); ip != nil {
+			endpoint.IPv6 = ip.String()
+		}
+	}
+	return endpoint
+}
+
+// ipv4FromTag returns the dotted IPv4 address held in tag, either packed into an integer
+// or as a string.
+func ipv4FromTag(tag model.KeyValue) (string, bool) {
+	if tag.VType == model.Int64Type {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], uint32(tag.Int64()))
+		return net.IP(buf[:]).String(), true
+	}
+	if ip := net.ParseIP(tag.AsString()).To4(); ip != nil {
+		return ip.String(), true
+	}
+	return "", false
+}
+
+// traceIDToHex formats the trace ID as 16 or, for 128-bit IDs, 32 lowercase hex characters.
+func traceIDToHex(traceID model.TraceID) string {
+	if traceID.High == 0 {
+		return fmt.Sprintf("%016x", traceID.Low)
+	}
+	return fmt.Sprintf("%016x%016x", traceID.High, traceID.Low)
+}
+
+func spanIDToHex(spanID model.SpanID) string {
+	return fmt.Sprintf("%016x", uint64(spanID))
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeTestSpan(spanID model.SpanID, kind string, process *model.Process) *model.Span {
+	traceID := model.TraceID{Low: 42}
+	return &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: "op",
+		References:    []model.SpanRef{model.NewChildOfRef(traceID, 1)},
+		StartTime:     time.Date(2018, 10, 31, 15, 33, 20, 1000, time.UTC),
+		Duration:      time.Millisecond,
+		Tags: []model.KeyValue{
+			model.String("http.method", "GET"),
+			model.String("span.kind", kind),
+		},
+		Logs: []model.Log{{
+			Timestamp: time.Date(2018, 10, 31, 15, 33, 20, 2000, time.UTC),
+			Fields:    []model.KeyValue{model.String("event", "retry")},
+		}},
+		Process: process,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	frontend := model.NewProcess("frontend", []model.KeyValue{model.Int64("ip", 0x0a000001)})
+	backend := model.NewProcess("backend", []model.KeyValue{model.String("ip", "2001:db8::1")})
+	spans := []*model.Span{
+		makeTestSpan(2, "client", frontend),
+		makeTestSpan(2, "server", backend),
+		makeTestSpan(3, "producer", frontend),
+	}
+	spans[0].Flags.SetDebug()
+	spans[0].Tags = append(spans[0].Tags, model.String("peer.service", "backend"), model.Int64("peer.port", 9000))
+	spans[2].References = nil
+	spans[2].Logs[0].Fields = []model.KeyValue{model.String("event", "x"), model.String("level", "info")}
+
+	zSpans := FromDomain(spans)
+	require.Len(t, zSpans, 3)
+	assert.Equal(t, "000000000000002a", zSpans[0].TraceID)
+	assert.Equal(t, "0000000000000002", zSpans[0].ID)
+	assert.Equal(t, "0000000000000001", zSpans[0].ParentID)
+	assert.Equal(t, &Endpoint{ServiceName: "frontend", IPv4: "10.0.0.1"}, zSpans[0].LocalEndpoint)
+	assert.Equal(t, &Endpoint{ServiceName: "backend", Port: 9000}, zSpans[0].RemoteEndpoint)
+	assert.False(t, zSpans[0].Shared)
+	assert.True(t, zSpans[1].Shared, "server span sharing the ID of a client span")
+	assert.Equal(t, &Endpoint{ServiceName: "backend", IPv6: "2001:db8::1"}, zSpans[1].LocalEndpoint)
+	assert.Equal(t, `{"event":"x","level":"info"}`, zSpans[2].Annotations[0].Value)
+
+	actual, err := ToDomain(zSpans)
+	require.NoError(t, err)
+	assert.Equal(t, spans, actual)
+}
+
+func TestFromDomainSplitsSharedRPC(t *testing.T) {
+	span := makeTestSpan(2, "client", model.NewProcess("frontend", nil))
+	span.Logs = []model.Log{
+		{Timestamp: span.StartTime, Fields: []model.KeyValue{model.String("event", "cs")}},
+		{Timestamp: span.StartTime.Add(100 * time.Microsecond), Fields: []model.KeyValue{model.String("event", "sr")}},
+		{Timestamp: span.StartTime.Add(900 * time.Microsecond), Fields: []model.KeyValue{model.String("event", "ss")}},
+	}
+	zSpans := FromDomain([]*model.Span{span})
+	require.Len(t, zSpans, 2)
+	client, server := zSpans[0], zSpans[1]
+	assert.Equal(t, KindClient, client.Kind)
+	assert.Nil(t, client.Annotations)
+	assert.Equal(t, KindServer, server.Kind)
+	assert.True(t, server.Shared)
+	assert.Equal(t, client.ID, server.ID)
+	assert.Equal(t, client.ParentID, server.ParentID)
+	assert.Equal(t, client.Timestamp+100, server.Timestamp)
+	assert.Equal(t, uint64(800), server.Duration)
+}
+
+func TestFromDomainTags(t *testing.T) {
+	span := &model.Span{
+		TraceID: model.TraceID{High: 1, Low: 2},
+		SpanID:  3,
+		Tags: []model.KeyValue{
+			model.String("span.kind", "internal"),
+			model.String("peer.ipv4", "10.0.0.2"),
+			model.Binary("peer.ipv6", []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}),
+			model.String("peer.port", "http"),
+			model.Bool("error", true),
+		},
+		Logs: []model.Log{{}},
+	}
+	zSpan := FromDomain([]*model.Span{span})[0]
+	assert.Equal(t, "00000000000000010000000000000002", zSpan.TraceID)
+	assert.Empty(t, zSpan.ParentID)
+	assert.Empty(t, zSpan.Kind)
+	assert.Nil(t, zSpan.LocalEndpoint)
+	assert.Nil(t, zSpan.Annotations, "logs without fields are dropped")
+	assert.Equal(t, &Endpoint{IPv4: "10.0.0.2", IPv6: "2001:db8::1"}, zSpan.RemoteEndpoint)
+	assert.Equal(t, map[string]string{"span.kind": "internal", "peer.port": "http", "error": "true"}, zSpan.Tags)
+}
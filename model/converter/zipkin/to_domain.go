@@ -0,0 +1,197 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// UnknownServiceName is the service name of the Process of spans without a local endpoint.
+	UnknownServiceName = "unknown-service-name"
+
+	// IPTagName is the Process tag holding the IP address of the local endpoint.
+	IPTagName = "ip"
+)
+
+var spanKindsToDomain = map[string]ext.SpanKindEnum{
+	KindClient:   ext.SpanKindRPCClientEnum,
+	KindServer:   ext.SpanKindRPCServerEnum,
+	KindProducer: ext.SpanKindProducerEnum,
+	KindConsumer: ext.SpanKindConsumerEnum,
+}
+
+// ToDomain converts Zipkin v2 spans into model.Span list. Spans with the same
+// local endpoint share a Process.
+func ToDomain(zSpans []*Span) ([]*model.Span, error) {
+	processes := make(map[Endpoint]*model.Process)
+	spans := make([]*model.Span, 0, len(zSpans))
+	for _, zSpan := range zSpans {
+		span, err := toDomainSpan(zSpan)
+		if err != nil {
+			return nil, err
+		}
+		var endpoint Endpoint
+		if zSpan.LocalEndpoint != nil {
+			endpoint = *zSpan.LocalEndpoint
+		}
+		process, ok := processes[endpoint]
+		if !ok {
+			process = toDomainProcess(endpoint)
+			processes[endpoint] = process
+		}
+		span.Process = process
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+func toDomainSpan(zSpan *Span) (*model.Span, error) {
+	traceID, err := model.TraceIDFromString(zSpan.TraceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace ID %q: %v", zSpan.TraceID, err)
+	}
+	spanID, err := spanIDFromString(zSpan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span ID %q: %v", zSpan.ID, err)
+	}
+	var refs []model.SpanRef
+	if zSpan.ParentID != "" {
+		parentID, err := spanIDFromString(zSpan.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent ID %q: %v", zSpan.ParentID, err)
+		}
+		refs = model.MaybeAddParentSpanID(traceID, parentID, refs)
+	}
+	var flags model.Flags
+	if zSpan.Debug {
+		flags.SetDebug()
+	}
+	return &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: zSpan.Name,
+		References:    refs,
+		Flags:         flags,
+		StartTime:     model.EpochMicrosecondsAsTime(zSpan.Timestamp),
+		Duration:      model.MicrosecondsAsDuration(zSpan.Duration),
+		Tags:          toDomainTags(zSpan),
+		Logs:          toDomainLogs(zSpan.Annotations),
+	}, nil
+}
+
+// spanIDFromString parses a span ID, keeping the lowest 64 bits of the 128-bit span IDs
+// sent by some Zipkin clients, like the Zipkin collector endpoints do.
+func spanIDFromString(s string) (model.SpanID, error) {
+	if l := len(s); l > 16 && l <= 32 {
+		s = s[l-16:]
+	}
+	return model.SpanIDFromString(s)
+}
+
+// toDomainTags converts the tags, sorted by key, followed by the span kind and the
+// remote endpoint tags.
+func toDomainTags(zSpan *Span) []model.KeyValue {
+	keys := make([]string, 0, len(zSpan.Tags))
+	for k := range zSpan.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var tags []model.KeyValue
+	for _, k := range keys {
+		tags = append(tags, model.String(k, zSpan.Tags[k]))
+	}
+	if kind, ok := spanKindsToDomain[strings.ToUpper(zSpan.Kind)]; ok {
+		tags = append(tags, model.String(string(ext.SpanKind), string(kind)))
+	}
+	if remote := zSpan.RemoteEndpoint; remote != nil {
+		if remote.ServiceName != "" {
+			tags = append(tags, model.String(string(ext.PeerService), remote.ServiceName))
+		}
+		if ipv4, ok := ipv4ToInt64(remote.IPv4); ok {
+			tags = append(tags, model.Int64(string(ext.PeerHostIPv4), ipv4))
+		}
+		if remote.IPv6 != "" {
+			tags = append(tags, model.String(string(ext.PeerHostIPv6), remote.IPv6))
+		}
+		if remote.Port != 0 {
+			tags = append(tags, model.Int64(string(ext.PeerPort), int64(remote.Port)))
+		}
+	}
+	return tags
+}
+
+func toDomainLogs(annotations []Annotation) []model.Log {
+	var logs []model.Log
+	for _, a := range annotations {
+		if a.Value == "" {
+			continue
+		}
+		logs = append(logs, model.Log{
+			Timestamp: model.EpochMicrosecondsAsTime(a.Timestamp),
+			Fields:    toDomainLogFields(a.Value),
+		})
+	}
+	return logs
+}
+
+// toDomainLogFields decodes annotation values holding a JSON object of strings,
+// which some clients use to encode key-value logs, into one field per member.
+func toDomainLogFields(value string) []model.KeyValue {
+	var members map[string]string
+	if err := json.Unmarshal([]byte(value), &members); err == nil && len(members) > 0 {
+		fields := make(model.KeyValues, 0, len(members))
+		for k, v := range members {
+			fields = append(fields, model.String(k, v))
+		}
+		fields.Sort()
+		return fields
+	}
+	return []model.KeyValue{model.String(model.EventKey, value)}
+}
+
+// toDomainProcess converts a local endpoint into a Process. The endpoint port has
+// no Process equivalent and is not converted.
+func toDomainProcess(endpoint Endpoint) *model.Process {
+	serviceName := endpoint.ServiceName
+	if serviceName == "" {
+		serviceName = UnknownServiceName
+	}
+	var tags []model.KeyValue
+	if ipv4, ok := ipv4ToInt64(endpoint.IPv4); ok {
+		tags = append(tags, model.Int64(IPTagName, ipv4))
+	} else if endpoint.IPv6 != "" {
+		tags = append(tags, model.String(IPTagName, endpoint.IPv6))
+	}
+	return model.NewProcess(serviceName, tags)
+}
+
+// ipv4ToInt64 packs a dotted IPv4 address into an integer, as in the Zipkin Thrift format.
+func ipv4ToInt64(s string) (int64, bool) {
+	ip := net.ParseIP(s).To4()
+	if ip == nil {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint32(ip)), true
+}
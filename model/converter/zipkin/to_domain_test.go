@@ -0,0 +1,155 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const testSpansJSON = `[
+  {
+    "traceId": "463ac35c9f6413ad48485a3953bb6124",
+    "id": "a2fb4a1d1a96d312",
+    "parentId": "0020000000000001",
+    "name": "get /api",
+    "kind": "CLIENT",
+    "timestamp": 1541000000000001,
+    "duration": 1500,
+    "debug": true,
+    "localEndpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1", "port": 8080},
+    "remoteEndpoint": {"serviceName": "backend", "ipv4": "10.0.0.2", "ipv6": "::1", "port": 9000},
+    "annotations": [
+      {"timestamp": 1541000000000100, "value": "ws"},
+      {"timestamp": 1541000000000200, "value": "{\"level\":\"info\",\"message\":\"retry\"}"},
+      {"timestamp": 1541000000000300, "value": ""}
+    ],
+    "tags": {"http.path": "/api", "http.method": "GET"}
+  },
+  {
+    "traceId": "463ac35c9f6413ad48485a3953bb6124",
+    "id": "a2fb4a1d1a96d312",
+    "parentId": "0020000000000001",
+    "name": "get /api",
+    "kind": "SERVER",
+    "shared": true,
+    "timestamp": 1541000000000200,
+    "duration": 1000,
+    "localEndpoint": {"serviceName": "backend", "ipv6": "2001:db8::1"}
+  },
+  {
+    "traceId": "48485a3953bb6124",
+    "id": "1",
+    "localEndpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1", "port": 8080}
+  },
+  {
+    "traceId": "48485a3953bb6124",
+    "id": "2"
+  }
+]`
+
+func TestToDomain(t *testing.T) {
+	var zSpans []*Span
+	require.NoError(t, json.Unmarshal([]byte(testSpansJSON), &zSpans))
+	spans, err := ToDomain(zSpans)
+	require.NoError(t, err)
+	require.Len(t, spans, 4)
+
+	traceID := model.TraceID{High: 0x463ac35c9f6413ad, Low: 0x48485a3953bb6124}
+	frontend := model.NewProcess("frontend", []model.KeyValue{model.Int64("ip", 0x0a000001)})
+	client := &model.Span{
+		TraceID:       traceID,
+		SpanID:        0xa2fb4a1d1a96d312,
+		OperationName: "get /api",
+		References:    []model.SpanRef{model.NewChildOfRef(traceID, 0x0020000000000001)},
+		Flags:         model.Flags(2),
+		StartTime:     time.Date(2018, 10, 31, 15, 33, 20, 1000, time.UTC),
+		Duration:      1500 * time.Microsecond,
+		Tags: []model.KeyValue{
+			model.String("http.method", "GET"),
+			model.String("http.path", "/api"),
+			model.String("span.kind", "client"),
+			model.String("peer.service", "backend"),
+			model.Int64("peer.ipv4", 0x0a000002),
+			model.String("peer.ipv6", "::1"),
+			model.Int64("peer.port", 9000),
+		},
+		Logs: []model.Log{
+			{
+				Timestamp: time.Date(2018, 10, 31, 15, 33, 20, 100000, time.UTC),
+				Fields:    []model.KeyValue{model.String("event", "ws")},
+			},
+			{
+				Timestamp: time.Date(2018, 10, 31, 15, 33, 20, 200000, time.UTC),
+				Fields:    []model.KeyValue{model.String("level", "info"), model.String("message", "retry")},
+			},
+		},
+		Process: frontend,
+	}
+	assert.Equal(t, client, spans[0])
+
+	server := spans[1]
+	assert.Equal(t, client.SpanID, server.SpanID, "shared span keeps the span ID")
+	assert.Equal(t, client.References, server.References)
+	assert.True(t, server.IsRPCServer())
+	assert.Equal(t, model.NewProcess("backend", []model.KeyValue{model.String("ip", "2001:db8::1")}), server.Process)
+
+	assert.Equal(t, model.TraceID{Low: 0x48485a3953bb6124}, spans[2].TraceID)
+	assert.True(t, spans[0].Process == spans[2].Process, "spans with the same endpoint share the process")
+	assert.Equal(t, model.NewProcess(UnknownServiceName, nil), spans[3].Process)
+	assert.Nil(t, spans[3].Tags)
+	assert.Nil(t, spans[3].Logs)
+}
+
+func TestToDomainErrors(t *testing.T) {
+	testCases := []struct {
+		span *Span
+		err  string
+	}{
+		{
+			span: &Span{TraceID: "xyz", ID: "1"},
+			err:  `invalid trace ID "xyz": strconv.ParseUint: parsing "xyz": invalid syntax`,
+		},
+		{
+			span: &Span{TraceID: "1", ID: ""},
+			err:  `invalid span ID "": strconv.ParseUint: parsing "": invalid syntax`,
+		},
+		{
+			span: &Span{TraceID: "1", ID: "1", ParentID: "000000000000000000000000000000001"},
+			err:  `invalid parent ID "000000000000000000000000000000001": SpanID cannot be longer than 16 hex characters: 000000000000000000000000000000001`,
+		},
+	}
+	for _, testCase := range testCases {
+		_, err := ToDomain([]*Span{testCase.span})
+		assert.EqualError(t, err, testCase.err)
+	}
+}
+
+func TestToDomainLongSpanIDs(t *testing.T) {
+	spans, err := ToDomain([]*Span{{
+		TraceID:  "1",
+		ID:       "00000000000000010000000000000002",
+		ParentID: "00000000000000030000000000000004",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, model.SpanID(2), spans[0].SpanID)
+	assert.Equal(t, model.SpanID(4), spans[0].ParentSpanID())
+}
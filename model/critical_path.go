@@ -0,0 +1,155 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// CriticalPathTagKey is the tag set by MarkCriticalPath on spans on the critical path.
+const CriticalPathTagKey = "jaeger.critical_path"
+
+// CriticalPathSegment is a span on the critical path of a trace with its self time,
+// i.e. the part of the path spent in the span itself rather than in its children.
+type CriticalPathSegment struct {
+	Span     *Span
+	SelfTime time.Duration
+}
+
+// CriticalPath returns the spans on the critical path of the trace, i.e. the chain of
+// spans that determines its end-to-end latency, with the root span first.
+// See CriticalPathSegments.
+func (t *Trace) CriticalPath() []*Span {
+	segments := t.CriticalPathSegments()
+	if segments == nil {
+		return nil
+	}
+	path := make([]*Span, len(segments))
+	for i, segment := range segments {
+		path[i] = segment.Span
+	}
+	return path
+}
+
+// CriticalPathSegments returns the spans on the critical path of the trace with their
+// self time contributions, with the root span first. The self times add up to the
+// duration of the root span.
+//
+// The path starts at the root span that finishes last. From each span it walks back
+// from the end of the span, repeatedly following the child (see ChildIndex) that was
+// the last to finish before the current point in time, and moving that point to the
+// start of the child, until it reaches the start of the span. Children that finish
+// after the current point in time, or start before their parent, are treated as
+// running only within it, and children that do not overlap the span are ignored.
+func (t *Trace) CriticalPathSegments() []CriticalPathSegment {
+	spansByID := t.spansByID()
+	var root *Span
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.ParentSpanID()]; ok {
+			continue
+		}
+		if root == nil || span.Interval().End.After(root.Interval().End) {
+			root = span
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	children := t.ChildIndex()
+	visited := make(map[*Span]struct{}, len(t.Spans))
+	var path []CriticalPathSegment
+	// visit adds the span to the path, considering only its part within window
+	var visit func(span *Span, window Interval)
+	visit = func(span *Span, window Interval) {
+		if _, ok := visited[span]; ok {
+			return
+		}
+		visited[span] = struct{}{}
+		index := len(path)
+		path = append(path, CriticalPathSegment{Span: span})
+
+		spanChildren := append([]*Span(nil), children[span.SpanID]...)
+		sort.Sort(sort.Reverse(spanByEndTime(spanChildren)))
+		var selfTime time.Duration
+		cursor := window.End
+		for _, child := range spanChildren {
+			if !cursor.After(window.Start) {
+				break
+			}
+			if !child.StartTime.Before(cursor) {
+				// the child runs entirely during a later part of the path
+				continue
+			}
+			childWindow := Interval{
+				Start: maxTime(child.StartTime, window.Start),
+				End:   maxTime(minTime(child.Interval().End, cursor), window.Start),
+			}
+			if !childWindow.End.After(childWindow.Start) {
+				// the child does not overlap the window, e.g. because of clock skew
+				continue
+			}
+			selfTime += cursor.Sub(childWindow.End)
+			visit(child, childWindow)
+			cursor = childWindow.Start
+		}
+		selfTime += Interval{Start: window.Start, End: cursor}.Duration()
+		path[index].SelfTime = selfTime
+	}
+	visit(root, root.Interval())
+	return path
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// MarkCriticalPath sets the `jaeger.critical_path` tag to true on the spans on the
+// critical path of the trace (see CriticalPath), and removes it from other spans.
+func (t *Trace) MarkCriticalPath() {
+	for _, span := range t.Spans {
+		span.RemoveTag(CriticalPathTagKey)
+	}
+	for _, span := range t.CriticalPath() {
+		span.setTag(Bool(CriticalPathTagKey, true))
+	}
+}
+
+// IsOnCriticalPath returns true if the span was marked by Trace.MarkCriticalPath.
+func (s *Span) IsOnCriticalPath() bool {
+	tag, ok := KeyValues(s.Tags).FindByKey(CriticalPathTagKey)
+	return ok && tag.Bool()
+}
+
+type spanByEndTime []*Span
+
+func (s spanByEndTime) Len() int      { return len(s) }
+func (s spanByEndTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s spanByEndTime) Less(i, j int) bool {
+	if end, otherEnd := s[i].Interval().End, s[j].Interval().End; !end.Equal(otherEnd) {
+		return end.Before(otherEnd)
+	}
+	return s[i].SpanID < s[j].SpanID
+}
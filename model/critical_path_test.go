@@ -0,0 +1,130 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeCriticalPathTrace() *model.Trace {
+	base := time.Unix(100, 0)
+	span := func(id, parent model.SpanID, start, end time.Duration) *model.Span {
+		span := makeTreeSpan(id, parent)
+		span.StartTime = base.Add(start * time.Millisecond)
+		span.Duration = (end - start) * time.Millisecond
+		return span
+	}
+	// 1 |----------------------------------------|     0-100
+	// 2    |--------|                                  10-40
+	// 3          |------------------------|            30-90
+	// 4              |---------|                       40-70
+	// 5                             |-|                75-80
+	// 6       |--|                                     20-25
+	return &model.Trace{
+		Spans: []*model.Span{
+			span(1, 0, 0, 100),
+			span(2, 1, 10, 40),
+			span(3, 1, 30, 90),
+			span(4, 3, 40, 70),
+			span(5, 3, 75, 80),
+			span(6, 2, 20, 25),
+		},
+	}
+}
+
+func TestTraceCriticalPath(t *testing.T) {
+	trace := makeCriticalPathTrace()
+	assert.Equal(t, []model.SpanID{1, 3, 5, 4, 2, 6}, spanIDs(trace.CriticalPath()))
+
+	// span 7 runs in parallel with span 5 but ends earlier, so it is not on the path
+	seven := makeTreeSpan(7, 3)
+	seven.StartTime = trace.Spans[4].StartTime
+	seven.Duration = time.Millisecond
+	trace.Spans = append(trace.Spans, seven)
+	assert.Equal(t, []model.SpanID{1, 3, 5, 4, 2, 6}, spanIDs(trace.CriticalPath()))
+
+	assert.Nil(t, (&model.Trace{}).CriticalPath())
+}
+
+func TestTraceCriticalPathChildOutlivesParent(t *testing.T) {
+	trace := makeCriticalPathTrace()
+	// span 3 now ends after its parent, and will not leave time for span 2
+	trace.Spans[2].StartTime = trace.Spans[0].StartTime.Add(5 * time.Millisecond)
+	trace.Spans[2].Duration = 200 * time.Millisecond
+	assert.Equal(t, []model.SpanID{1, 3, 5, 4}, spanIDs(trace.CriticalPath()))
+	// span 3 only counts until the end of span 1
+	assert.Equal(t, []time.Duration{5, 60, 5, 30}, criticalPathSelfTimes(trace))
+}
+
+func TestTraceCriticalPathChildBeforeParent(t *testing.T) {
+	root := makeTreeSpan(1, 0)
+	root.StartTime = time.Unix(100, 0)
+	root.Duration = 100 * time.Millisecond
+	// clock skew moves the child before the start of its parent
+	child := makeTreeSpan(2, 1)
+	child.StartTime = root.StartTime.Add(-50 * time.Millisecond)
+	child.Duration = 10 * time.Millisecond
+	trace := &model.Trace{Spans: []*model.Span{root, child}}
+	assert.Equal(t, []model.CriticalPathSegment{{Span: root, SelfTime: 100 * time.Millisecond}}, trace.CriticalPathSegments())
+}
+
+func TestTraceCriticalPathSegments(t *testing.T) {
+	trace := makeCriticalPathTrace()
+	segments := trace.CriticalPathSegments()
+	var ids []model.SpanID
+	for _, segment := range segments {
+		ids = append(ids, segment.Span.SpanID)
+	}
+	assert.Equal(t, spanIDs(trace.CriticalPath()), ids)
+	assert.Equal(t, []time.Duration{20, 25, 5, 30, 15, 5}, criticalPathSelfTimes(trace))
+
+	// span 6 starts before its parent, so only its part within span 2 counts
+	trace.Spans[5].StartTime = trace.Spans[0].StartTime.Add(5 * time.Millisecond)
+	trace.Spans[5].Duration = 15 * time.Millisecond
+	assert.Equal(t, []time.Duration{20, 25, 5, 30, 10, 10}, criticalPathSelfTimes(trace))
+
+	assert.Nil(t, (&model.Trace{}).CriticalPathSegments())
+}
+
+// criticalPathSelfTimes returns the self times of the critical path segments in milliseconds.
+func criticalPathSelfTimes(trace *model.Trace) []time.Duration {
+	var selfTimes []time.Duration
+	for _, segment := range trace.CriticalPathSegments() {
+		selfTimes = append(selfTimes, segment.SelfTime/time.Millisecond)
+	}
+	return selfTimes
+}
+
+func TestTraceMarkCriticalPath(t *testing.T) {
+	trace := makeCriticalPathTrace()
+	trace.Spans[2].Duration = 10 * time.Millisecond // 30-40, span 2 is now on the path
+	trace.Spans[2].Tags = []model.KeyValue{model.Bool("jaeger.critical_path", true)}
+	trace.MarkCriticalPath()
+	trace.MarkCriticalPath()
+	var onPath []model.SpanID
+	for _, span := range trace.Spans {
+		if span.IsOnCriticalPath() {
+			onPath = append(onPath, span.SpanID)
+			assert.Len(t, span.Tags, 1)
+		}
+	}
+	assert.Equal(t, []model.SpanID{1, 2, 3, 6}, onPath)
+	assert.Empty(t, trace.Spans[3].Tags)
+}
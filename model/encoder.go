@@ -0,0 +1,89 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// SpanEncoder serializes spans into a specific Format.
+type SpanEncoder interface {
+	Encode(span *Span) ([]byte, error)
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[Format]SpanEncoder{
+		JSONFormat: jsonSpanEncoder{},
+		GobFormat:  gobSpanEncoder{},
+	}
+)
+
+// RegisterEncoder makes the encoder available through EncoderFor, replacing any
+// encoder previously registered for the format. The encoders for ZipkinFormat and
+// OTLPFormat are registered by importing model/converter/zipkin and model/converter/otlp,
+// which the model package cannot depend on.
+func RegisterEncoder(format Format, encoder SpanEncoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[format] = encoder
+}
+
+// EncoderFor returns the SpanEncoder registered for the given format.
+// Only the output of the JSON and gob encoders can be read back with DecodeSpanAuto.
+func EncoderFor(format Format) (SpanEncoder, error) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	if encoder, ok := encoders[format]; ok {
+		return encoder, nil
+	}
+	return nil, fmt.Errorf("no span encoder for format %v", format)
+}
+
+// FormatFromString converts a string into Format enum.
+func FormatFromString(s string) (Format, error) {
+	switch s {
+	case jsonFormatStr:
+		return JSONFormat, nil
+	case gobFormatStr:
+		return GobFormat, nil
+	case zipkinFormatStr:
+		return ZipkinFormat, nil
+	case otlpFormatStr:
+		return OTLPFormat, nil
+	}
+	return UnknownFormat, fmt.Errorf("not a valid Format string %s", s)
+}
+
+type jsonSpanEncoder struct{}
+
+func (jsonSpanEncoder) Encode(span *Span) ([]byte, error) {
+	return json.Marshal(span)
+}
+
+type gobSpanEncoder struct{}
+
+func (gobSpanEncoder) Encode(span *Span) ([]byte, error) {
+	// a new gob.Encoder for each span makes the output self-describing
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(span); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterEncoderCustomFormat(t *testing.T) {
+	format := Format(100)
+	RegisterEncoder(format, fixedEncoder("data"))
+	defer func() {
+		encodersMu.Lock()
+		delete(encoders, format)
+		encodersMu.Unlock()
+	}()
+
+	encoder, err := EncoderFor(format)
+	require.NoError(t, err)
+	data, err := encoder.Encode(&Span{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+}
+
+type fixedEncoder string
+
+func (e fixedEncoder) Encode(*Span) ([]byte, error) {
+	return []byte(e), nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestEncoderFor(t *testing.T) {
+	span := makeSpan(model.String("k", "v"))
+	span.NormalizeTimestamps()
+	for _, name := range []string{"json", "gob"} {
+		format, err := model.FormatFromString(name)
+		require.NoError(t, err)
+		encoder, err := model.EncoderFor(format)
+		require.NoError(t, err)
+		data, err := encoder.Encode(span)
+		require.NoError(t, err)
+
+		detected, err := model.DetectSpanFormat(data)
+		require.NoError(t, err)
+		assert.Equal(t, format, detected)
+		decoded, err := model.DecodeSpanAuto(data)
+		require.NoError(t, err)
+		assert.Equal(t, span, decoded)
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	for _, name := range []string{"zipkin", "otlp"} {
+		format, err := model.FormatFromString(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, format.String())
+	}
+	_, err := model.EncoderFor(model.ZipkinFormat)
+	assert.EqualError(t, err, "no span encoder for format zipkin", "registered by model/converter/zipkin")
+}
+
+func TestEncoderForErrors(t *testing.T) {
+	_, err := model.EncoderFor(model.UnknownFormat)
+	assert.EqualError(t, err, "no span encoder for format unknown")
+	format, err := model.FormatFromString("xml")
+	assert.EqualError(t, err, "not a valid Format string xml")
+	assert.Equal(t, model.UnknownFormat, format)
+}
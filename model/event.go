@@ -0,0 +1,72 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "time"
+
+// EventKey is the reserved log field key that holds the name of a span event.
+const EventKey = "event"
+
+// Event is a named, timestamped occurrence during a span, such as an OpenTelemetry span event.
+// Events are stored as span logs whose first string EventKey field holds the name.
+type Event struct {
+	Name       string
+	Timestamp  time.Time
+	Attributes []KeyValue
+}
+
+// ToLog converts the event to a log with the name in the EventKey field, followed by the attributes.
+// Events without a name produce a log that only holds the attributes.
+func (e Event) ToLog() Log {
+	log := Log{Timestamp: e.Timestamp}
+	if e.Name != "" {
+		log.Fields = append(make([]KeyValue, 0, len(e.Attributes)+1), String(EventKey, e.Name))
+	}
+	log.Fields = append(log.Fields, e.Attributes...)
+	return log
+}
+
+// Event returns the event stored in the log. The first string EventKey field holds
+// the name and all the other fields become attributes.
+// It returns false for plain logs without such a field.
+func (l *Log) Event() (Event, bool) {
+	event := Event{Timestamp: l.Timestamp}
+	found := false
+	for _, field := range l.Fields {
+		if !found && field.Key == EventKey && field.VType == StringType {
+			event.Name = field.VStr
+			found = true
+			continue
+		}
+		event.Attributes = append(event.Attributes, field)
+	}
+	return event, found
+}
+
+// AddEvent appends an event with the given name, timestamp and attributes to the span logs.
+func (s *Span) AddEvent(name string, timestamp time.Time, attributes ...KeyValue) {
+	s.Logs = append(s.Logs, Event{Name: name, Timestamp: timestamp, Attributes: attributes}.ToLog())
+}
+
+// GetEvents returns the events stored in the span logs, in log order, skipping plain logs.
+func (s *Span) GetEvents() []Event {
+	var events []Event
+	for i := range s.Logs {
+		if event, ok := s.Logs[i].Event(); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
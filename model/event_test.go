@@ -0,0 +1,77 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanEvents(t *testing.T) {
+	ts := time.Unix(1500000000, 0).UTC()
+	span := &model.Span{}
+	span.AddEvent("exception", ts, model.String("exception.type", "io.EOF"), model.Int64("retries", 3))
+	span.Logs = append(span.Logs, model.Log{Timestamp: ts, Fields: []model.KeyValue{model.String("message", "plain")}})
+	span.AddEvent("cache.miss", ts.Add(time.Second))
+
+	assert.Equal(t, []model.KeyValue{
+		model.String("event", "exception"),
+		model.String("exception.type", "io.EOF"),
+		model.Int64("retries", 3),
+	}, span.Logs[0].Fields)
+	assert.Equal(t, []model.Event{
+		{
+			Name:       "exception",
+			Timestamp:  ts,
+			Attributes: []model.KeyValue{model.String("exception.type", "io.EOF"), model.Int64("retries", 3)},
+		},
+		{Name: "cache.miss", Timestamp: ts.Add(time.Second)},
+	}, span.GetEvents())
+}
+
+func TestLogEvent(t *testing.T) {
+	testCases := []struct {
+		fields []model.KeyValue
+		event  model.Event
+		ok     bool
+	}{
+		{
+			fields: []model.KeyValue{model.String("message", "hi")},
+			event:  model.Event{Attributes: []model.KeyValue{model.String("message", "hi")}},
+		},
+		{
+			fields: []model.KeyValue{model.Int64("event", 1), model.String("event", "name"), model.String("event", "again")},
+			event:  model.Event{Name: "name", Attributes: []model.KeyValue{model.Int64("event", 1), model.String("event", "again")}},
+			ok:     true,
+		},
+	}
+	for _, testCase := range testCases {
+		log := model.Log{Fields: testCase.fields}
+		event, ok := log.Event()
+		assert.Equal(t, testCase.ok, ok)
+		assert.Equal(t, testCase.event, event)
+	}
+}
+
+func TestEventToLogWithoutName(t *testing.T) {
+	log := model.Event{Attributes: []model.KeyValue{model.Bool("b", true)}}.ToLog()
+	assert.Equal(t, []model.KeyValue{model.Bool("b", true)}, log.Fields)
+	_, ok := log.Event()
+	assert.False(t, ok)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Format describes the encoding of a serialized Span.
+type Format int
+
+const (
+	// UnknownFormat indicates that the encoding is not recognized
+	UnknownFormat Format = iota
+	// JSONFormat indicates a Span encoded with encoding/json
+	JSONFormat
+	// GobFormat indicates a Span encoded with encoding/gob
+	GobFormat
+	// ZipkinFormat indicates a Span encoded as a list of Zipkin v2 JSON spans
+	ZipkinFormat
+	// OTLPFormat indicates a Span encoded as OTLP protobuf TracesData
+	OTLPFormat
+
+	unknownFormatStr = "unknown"
+	jsonFormatStr    = "json"
+	gobFormatStr     = "gob"
+	zipkinFormatStr  = "zipkin"
+	otlpFormatStr    = "otlp"
+)
+
+var errEmptyData = errors.New("cannot detect format of empty data")
+
+func (f Format) String() string {
+	switch f {
+	case JSONFormat:
+		return jsonFormatStr
+	case GobFormat:
+		return gobFormatStr
+	case ZipkinFormat:
+		return zipkinFormatStr
+	case OTLPFormat:
+		return otlpFormatStr
+	}
+	return unknownFormatStr
+}
+
+// DetectSpanFormat sniffs the encoding of a serialized Span. Only JSONFormat and
+// GobFormat are detected: JSON by the leading '{' (after optional whitespace),
+// gob by a well-formed first message that defines a struct type. The output of
+// the ZipkinFormat and OTLPFormat encoders is reported as an unrecognized format.
+func DetectSpanFormat(data []byte) (Format, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 {
+		return UnknownFormat, errEmptyData
+	}
+	if trimmed[0] == '{' {
+		return JSONFormat, nil
+	}
+	if looksLikeGob(data) {
+		return GobFormat, nil
+	}
+	return UnknownFormat, fmt.Errorf("unrecognized span format")
+}
+
+// DecodeSpanAuto decodes a Span from data in any of the formats recognized by DetectSpanFormat.
+func DecodeSpanAuto(data []byte) (*Span, error) {
+	format, err := DetectSpanFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	span := &Span{}
+	switch format {
+	case JSONFormat:
+		err = json.Unmarshal(data, span)
+	case GobFormat:
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(span)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode span as %v: %v", format, err)
+	}
+	return span, nil
+}
+
+// gobStructTypePrefix starts the wireType of a struct definition: the StructT
+// field, the embedded CommonType and its Name field.
+var gobStructTypePrefix = []byte{0x03, 0x01, 0x01}
+
+// looksLikeGob checks that data starts with a gob message, i.e. a message length
+// that fits in the data followed by a negative type ID and a struct type
+// definition. Every gob stream of a struct value starts with one.
+func looksLikeGob(data []byte) bool {
+	length, n, ok := decodeGobUint(data)
+	if !ok || length == 0 || length > uint64(len(data)-n) {
+		return false
+	}
+	message := data[n : n+int(length)]
+	typeID, m, ok := decodeGobUint(message)
+	// gob encodes signed integers with the sign in the lowest bit
+	if !ok || typeID&1 == 0 {
+		return false
+	}
+	return bytes.HasPrefix(message[m:], gobStructTypePrefix)
+}
+
+// decodeGobUint decodes an unsigned integer in gob encoding: values below 128 are
+// stored in a single byte, otherwise the first byte holds the negated byte count
+// of the big-endian value that follows.
+func decodeGobUint(data []byte) (value uint64, n int, ok bool) {
+	if len(data) == 0 {
+		return 0, 0, false
+	}
+	b := data[0]
+	if b < 0x80 {
+		return uint64(b), 1, true
+	}
+	count := -int(int8(b))
+	if count > 8 || count >= len(data) {
+		return 0, 0, false
+	}
+	for _, c := range data[1 : count+1] {
+		value = value<<8 | uint64(c)
+	}
+	return value, count + 1, true
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestFormatString(t *testing.T) {
+	assert.Equal(t, "json", model.JSONFormat.String())
+	assert.Equal(t, "gob", model.GobFormat.String())
+	assert.Equal(t, "zipkin", model.ZipkinFormat.String())
+	assert.Equal(t, "otlp", model.OTLPFormat.String())
+	assert.Equal(t, "unknown", model.UnknownFormat.String())
+	assert.Equal(t, "unknown", model.Format(-1).String())
+}
+
+func TestDecodeSpanAuto(t *testing.T) {
+	span := makeSpan(model.Binary("blob", []byte{1, 2, 3}))
+	span.NormalizeTimestamps()
+
+	jsonData, err := json.Marshal(span)
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, gob.NewEncoder(buf).Encode(span))
+
+	testCases := []struct {
+		data   []byte
+		format model.Format
+	}{
+		{data: jsonData, format: model.JSONFormat},
+		{data: append([]byte(" \n\t"), jsonData...), format: model.JSONFormat},
+		{data: buf.Bytes(), format: model.GobFormat},
+	}
+	for _, testCase := range testCases {
+		format, err := model.DetectSpanFormat(testCase.data)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.format, format)
+
+		decoded, err := model.DecodeSpanAuto(testCase.data)
+		require.NoError(t, err)
+		assert.Equal(t, span, decoded)
+	}
+}
+
+func TestDetectSpanFormatErrors(t *testing.T) {
+	testCases := []struct {
+		data []byte
+		err  string
+	}{
+		{data: nil, err: "cannot detect format of empty data"},
+		{data: []byte("  \n"), err: "cannot detect format of empty data"},
+		{data: []byte("hello"), err: "unrecognized span format"},
+		{data: []byte{0x05, 0x02}, err: "unrecognized span format"},
+		{data: []byte{0xfe, 0x01}, err: "unrecognized span format"},
+		{data: []byte{0x02, 0x01, 0x00}, err: "unrecognized span format"},
+		{data: []byte(`[{"traceId":"1"}]`), err: "unrecognized span format"},
+	}
+	for _, testCase := range testCases {
+		format, err := model.DetectSpanFormat(testCase.data)
+		assert.EqualError(t, err, testCase.err)
+		assert.Equal(t, model.UnknownFormat, format)
+
+		_, err = model.DecodeSpanAuto(testCase.data)
+		assert.EqualError(t, err, testCase.err)
+	}
+}
+
+func TestDecodeSpanAutoCorrupted(t *testing.T) {
+	_, err := model.DecodeSpanAuto([]byte(`{"traceID":`))
+	assert.EqualError(t, err, "cannot decode span as json: unexpected end of JSON input")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, gob.NewEncoder(buf).Encode(makeSpan(model.String("k", "v"))))
+	_, err = model.DecodeSpanAuto(buf.Bytes()[:buf.Len()/2])
+	assert.Error(t, err)
+}
@@ -15,22 +15,91 @@
 package model
 
 import (
+	"encoding/binary"
+	"fmt"
 	"hash/fnv"
 	"io"
+	"time"
 )
 
 // Hashable interface is for type that can participate in a hash computation
 // by writing their data into io.Writer, which is usually an instance of hash.Hash.
-//
 type Hashable interface {
 	Hash(w io.Writer) error
 }
 
 // HashCode calcualtes a FNV-1a hash code for a Hashable object.
 func HashCode(o Hashable) (uint64, error) {
+	return HashCodeWithSeed(o, 0)
+}
+
+// HashCodeWithSeed calculates a FNV-1a hash code for a Hashable object salted with
+// the seed, so that different seeds give independent hash codes for the same object.
+// A zero seed gives the same hash code as HashCode.
+func HashCodeWithSeed(o Hashable, seed uint64) (uint64, error) {
 	h := fnv.New64a()
+	if seed != 0 {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], seed)
+		h.Write(buf[:])
+	}
 	if err := o.Hash(h); err != nil {
 		return 0, err
 	}
 	return h.Sum64(), nil
 }
+
+// hashWriter writes values in a fixed binary layout for hashing. Strings and lists are
+// prefixed with their length, so that different values never produce the same bytes.
+// The first write error is kept in err and all later writes are skipped.
+type hashWriter struct {
+	w   io.Writer
+	buf [8]byte
+	err error
+}
+
+func (h *hashWriter) write(data []byte) {
+	if h.err == nil {
+		_, h.err = h.w.Write(data)
+	}
+}
+
+func (h *hashWriter) uint64(v uint64) {
+	binary.BigEndian.PutUint64(h.buf[:], v)
+	h.write(h.buf[:])
+}
+
+func (h *hashWriter) bytes(data []byte) {
+	h.uint64(uint64(len(data)))
+	h.write(data)
+}
+
+func (h *hashWriter) string(s string) {
+	h.uint64(uint64(len(s)))
+	h.write([]byte(s))
+}
+
+func (h *hashWriter) time(t time.Time) {
+	h.uint64(uint64(t.UnixNano()))
+}
+
+func (h *hashWriter) keyValues(kvs []KeyValue) {
+	h.uint64(uint64(len(kvs)))
+	for i := range kvs {
+		kv := &kvs[i]
+		h.string(kv.Key)
+		h.uint64(uint64(kv.VType))
+		switch kv.VType {
+		case StringType:
+			h.string(kv.VStr)
+		case BoolType, Int64Type, Float64Type:
+			h.uint64(uint64(kv.VNum))
+		case BinaryType:
+			h.bytes(kv.VBlob)
+		default:
+			if h.err == nil {
+				h.err = fmt.Errorf("unknown type %d", kv.VType)
+			}
+		}
+	}
+}
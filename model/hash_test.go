@@ -58,3 +58,29 @@ func TestHasCodeError(t *testing.T) {
 	assert.Equal(t, uint64(0), n)
 	assert.Equal(t, someErr, err)
 }
+
+func TestHashCodeWithSeed(t *testing.T) {
+	process := model.NewProcess("service", []model.KeyValue{model.String("host", "h1")})
+	hash, err := model.HashCode(process)
+	assert.NoError(t, err)
+
+	unseeded, err := model.HashCodeWithSeed(process, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, unseeded)
+
+	seeded1, err := model.HashCodeWithSeed(process, 1)
+	assert.NoError(t, err)
+	seeded2, err := model.HashCodeWithSeed(process, 2)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, seeded1)
+	assert.NotEqual(t, seeded1, seeded2)
+
+	again, err := model.HashCodeWithSeed(process.Clone(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, seeded1, again)
+
+	someErr := errors.New("some error")
+	n, err := model.HashCodeWithSeed(&errHashable{err: someErr}, 1)
+	assert.Equal(t, uint64(0), n)
+	assert.Equal(t, someErr, err)
+}
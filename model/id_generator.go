@@ -0,0 +1,115 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+)
+
+var defaultIDGenerator = NewIDGenerator(IDGeneratorOptions{})
+
+// GenerateTraceID returns a random 128bit TraceID drawn from crypto/rand.
+// The low 64 bits are never zero, so the ID is also valid for systems that
+// only support 64bit trace IDs.
+func GenerateTraceID() TraceID {
+	return defaultIDGenerator.TraceID()
+}
+
+// GenerateSpanID returns a random non-zero 64bit SpanID drawn from crypto/rand.
+func GenerateSpanID() SpanID {
+	return defaultIDGenerator.SpanID()
+}
+
+// IDGeneratorOptions configures an IDGenerator.
+type IDGeneratorOptions struct {
+	// Random is the source of random bytes, crypto/rand.Reader if nil.
+	// A deterministic source, e.g. math/rand seeded with a constant, makes the IDs
+	// reproducible in tests.
+	Random io.Reader
+	// TimePrefixed makes the top 32 bits of the trace IDs hold the current Unix time in
+	// seconds, like AWS X-Ray trace IDs, so that IDs generated close in time are close
+	// to each other, which improves the locality of storage backends keyed by trace ID.
+	TimePrefixed bool
+	// Now returns the current time for time-prefixed trace IDs, time.Now if nil.
+	Now func() time.Time
+}
+
+// IDGenerator generates trace and span IDs. It is safe for concurrent use,
+// even if the source of random bytes is not: reads from a custom source are
+// serialized, while crypto/rand.Reader is read without locking.
+type IDGenerator struct {
+	options IDGeneratorOptions
+	// lock serializes reads from a custom source of random bytes, if locking is set.
+	lock    sync.Mutex
+	locking bool
+}
+
+// NewIDGenerator creates an IDGenerator with the given options.
+func NewIDGenerator(options IDGeneratorOptions) *IDGenerator {
+	locking := options.Random != nil
+	if options.Random == nil {
+		options.Random = rand.Reader
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	return &IDGenerator{options: options, locking: locking}
+}
+
+// TraceID returns a random 128bit TraceID, with the time in the top 32 bits if the
+// generator is time-prefixed. The low 64 bits are never zero.
+// It panics if the source of random bytes fails.
+func (g *IDGenerator) TraceID() TraceID {
+	id := TraceID{
+		High: g.randomUint64(),
+		Low:  g.randomNonZeroUint64(),
+	}
+	if g.options.TimePrefixed {
+		id.High = uint64(uint32(g.options.Now().Unix()))<<32 | id.High&0xffffffff
+	}
+	return id
+}
+
+// SpanID returns a random non-zero 64bit SpanID.
+// It panics if the source of random bytes fails.
+func (g *IDGenerator) SpanID() SpanID {
+	return SpanID(g.randomNonZeroUint64())
+}
+
+func (g *IDGenerator) randomNonZeroUint64() uint64 {
+	for {
+		if n := g.randomUint64(); n != 0 {
+			return n
+		}
+	}
+}
+
+func (g *IDGenerator) randomUint64() uint64 {
+	var buf [8]byte
+	if g.locking {
+		g.lock.Lock()
+		defer g.lock.Unlock()
+	}
+	_, err := io.ReadFull(g.options.Random, buf[:])
+	if err != nil {
+		// crypto/rand only fails if the OS entropy source is broken
+		panic("cannot read random bytes: " + err.Error())
+	}
+	return binary.BigEndian.Uint64(buf[:])
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"bytes"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestGenerateTraceID(t *testing.T) {
+	seen := make(map[model.TraceID]struct{})
+	for i := 0; i < 1000; i++ {
+		id := model.GenerateTraceID()
+		assert.NotEqual(t, uint64(0), id.Low)
+		_, dup := seen[id]
+		assert.False(t, dup)
+		seen[id] = struct{}{}
+
+		parsed, err := model.TraceIDFromString(id.String())
+		assert.NoError(t, err)
+		assert.Equal(t, id, parsed)
+	}
+}
+
+func TestGenerateSpanID(t *testing.T) {
+	seen := make(map[model.SpanID]struct{})
+	for i := 0; i < 1000; i++ {
+		id := model.GenerateSpanID()
+		assert.NotEqual(t, model.SpanID(0), id)
+		_, dup := seen[id]
+		assert.False(t, dup)
+		seen[id] = struct{}{}
+	}
+}
+
+func TestIDGeneratorDeterministic(t *testing.T) {
+	newGenerator := func() *model.IDGenerator {
+		return model.NewIDGenerator(model.IDGeneratorOptions{Random: rand.New(rand.NewSource(42))})
+	}
+	g1, g2 := newGenerator(), newGenerator()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, g1.TraceID(), g2.TraceID())
+		assert.Equal(t, g1.SpanID(), g2.SpanID())
+	}
+}
+
+func TestIDGeneratorSkipsZero(t *testing.T) {
+	random := bytes.NewReader([]byte{
+		0, 0, 0, 0, 0, 0, 0, 0, // high
+		0, 0, 0, 0, 0, 0, 0, 0, // low, zero is skipped
+		0, 0, 0, 0, 0, 0, 0, 1, // low
+		0, 0, 0, 0, 0, 0, 0, 0, // span ID, zero is skipped
+		0, 0, 0, 0, 0, 0, 0, 2, // span ID
+	})
+	g := model.NewIDGenerator(model.IDGeneratorOptions{Random: random})
+	assert.Equal(t, model.TraceID{Low: 1}, g.TraceID())
+	assert.Equal(t, model.SpanID(2), g.SpanID())
+	assert.PanicsWithValue(t, "cannot read random bytes: EOF", func() { g.SpanID() })
+}
+
+func TestIDGeneratorTimePrefixed(t *testing.T) {
+	now := time.Unix(0x5bd9f5e0, 0)
+	g := model.NewIDGenerator(model.IDGeneratorOptions{
+		Random:       bytes.NewReader(bytes.Repeat([]byte{0xff}, 16)),
+		TimePrefixed: true,
+		Now:          func() time.Time { return now },
+	})
+	assert.Equal(t, model.TraceID{High: 0x5bd9f5e0ffffffff, Low: 0xffffffffffffffff}, g.TraceID())
+
+	g = model.NewIDGenerator(model.IDGeneratorOptions{TimePrefixed: true})
+	before := uint64(time.Now().Unix())
+	prefix := g.TraceID().High >> 32
+	assert.True(t, prefix >= before && prefix <= before+1, "prefix %d, time %d", prefix, before)
+}
+
+func TestIDGeneratorConcurrent(t *testing.T) {
+	g := model.NewIDGenerator(model.IDGeneratorOptions{Random: rand.New(rand.NewSource(1))})
+	var wg sync.WaitGroup
+	ids := make([]model.SpanID, 100)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids[i] = g.SpanID()
+		}(i)
+	}
+	wg.Wait()
+	seen := make(map[model.SpanID]struct{})
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	assert.Len(t, seen, len(ids))
+}
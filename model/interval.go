@@ -0,0 +1,88 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "time"
+
+// Interval is a half-open time interval [Start, End): it includes Start but not End,
+// so that intervals that only touch, such as a span and the span that starts right
+// after it ends, do not overlap.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Interval returns the time interval during which the span was active.
+func (s *Span) Interval() Interval {
+	return Interval{Start: s.StartTime, End: s.StartTime.Add(s.Duration)}
+}
+
+// Duration returns the length of the interval, or zero if it is empty.
+func (i Interval) Duration() time.Duration {
+	if !i.End.After(i.Start) {
+		return 0
+	}
+	return i.End.Sub(i.Start)
+}
+
+// IsEmpty returns true if the interval contains no instants, i.e. End is not after Start.
+func (i Interval) IsEmpty() bool {
+	return !i.End.After(i.Start)
+}
+
+// Overlaps returns true if the intervals have at least one instant in common.
+// Empty intervals do not overlap with any interval.
+func (i Interval) Overlaps(other Interval) bool {
+	if i.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+	return i.Start.Before(other.End) && other.Start.Before(i.End)
+}
+
+// Intersection returns the interval common to both intervals,
+// or false if they do not overlap.
+func (i Interval) Intersection(other Interval) (Interval, bool) {
+	if !i.Overlaps(other) {
+		return Interval{}, false
+	}
+	intersection := i
+	if other.Start.After(intersection.Start) {
+		intersection.Start = other.Start
+	}
+	if other.End.Before(intersection.End) {
+		intersection.End = other.End
+	}
+	return intersection, true
+}
+
+// Contains returns true if the other interval lies entirely within this interval.
+// An empty interval is contained in an interval if its start lies within [Start, End].
+func (i Interval) Contains(other Interval) bool {
+	return !other.Start.Before(i.Start) && !other.End.After(i.End) && !other.Start.After(i.End)
+}
+
+// SpansOverlap returns true if the spans were active at the same time, see Interval.Overlaps.
+func SpansOverlap(a, b *Span) bool {
+	return a.Interval().Overlaps(b.Interval())
+}
+
+// OverlapDuration returns how long the spans were active at the same time.
+func OverlapDuration(a, b *Span) time.Duration {
+	intersection, ok := a.Interval().Intersection(b.Interval())
+	if !ok {
+		return 0
+	}
+	return intersection.Duration()
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestInterval(t *testing.T) {
+	base := time.Unix(100, 0)
+	i := (&model.Span{StartTime: base, Duration: time.Second}).Interval()
+	assert.Equal(t, model.Interval{Start: base, End: base.Add(time.Second)}, i)
+	assert.Equal(t, time.Second, i.Duration())
+	assert.False(t, i.IsEmpty())
+
+	empty := model.Interval{Start: base, End: base}
+	assert.True(t, empty.IsEmpty())
+	assert.Equal(t, time.Duration(0), empty.Duration())
+	inverted := model.Interval{Start: base.Add(time.Second), End: base}
+	assert.True(t, inverted.IsEmpty())
+	assert.Equal(t, time.Duration(0), inverted.Duration())
+}
+
+func TestIntervalContains(t *testing.T) {
+	base := time.Unix(100, 0)
+	parent := model.Interval{Start: base, End: base.Add(10 * time.Second)}
+	testCases := []struct {
+		start, end time.Duration
+		contains   bool
+	}{
+		{start: 0, end: 10 * time.Second, contains: true},
+		{start: time.Second, end: 2 * time.Second, contains: true},
+		{start: 10 * time.Second, end: 10 * time.Second, contains: true},
+		{start: -time.Second, end: 2 * time.Second},
+		{start: 9 * time.Second, end: 11 * time.Second},
+		{start: 11 * time.Second, end: 11 * time.Second},
+	}
+	for _, testCase := range testCases {
+		child := model.Interval{Start: base.Add(testCase.start), End: base.Add(testCase.end)}
+		assert.Equal(t, testCase.contains, parent.Contains(child), "%v-%v", testCase.start, testCase.end)
+	}
+}
+
+func TestSpansOverlap(t *testing.T) {
+	base := time.Unix(100, 0)
+	span := func(start, duration time.Duration) *model.Span {
+		return &model.Span{StartTime: base.Add(start), Duration: duration}
+	}
+	testCases := []struct {
+		a, b    *model.Span
+		overlap time.Duration
+	}{
+		{a: span(0, 10*time.Second), b: span(2*time.Second, 3*time.Second), overlap: 3 * time.Second},
+		{a: span(0, 10*time.Second), b: span(8*time.Second, 5*time.Second), overlap: 2 * time.Second},
+		{a: span(0, 10*time.Second), b: span(0, 10*time.Second), overlap: 10 * time.Second},
+		{a: span(0, 10*time.Second), b: span(10*time.Second, time.Second)}, // touching
+		{a: span(0, time.Second), b: span(5*time.Second, time.Second)},
+		{a: span(0, 10*time.Second), b: span(5*time.Second, 0)}, // empty
+	}
+	for i, testCase := range testCases {
+		assert.Equal(t, testCase.overlap > 0, model.SpansOverlap(testCase.a, testCase.b), "case %d", i)
+		assert.Equal(t, testCase.overlap > 0, model.SpansOverlap(testCase.b, testCase.a), "case %d", i)
+		assert.Equal(t, testCase.overlap, model.OverlapDuration(testCase.a, testCase.b), "case %d", i)
+		assert.Equal(t, testCase.overlap, model.OverlapDuration(testCase.b, testCase.a), "case %d", i)
+	}
+}
@@ -53,6 +53,8 @@ type Trace struct {
 	Spans     []Span                `json:"spans"`
 	Processes map[ProcessID]Process `json:"processes"`
 	Warnings  []string              `json:"warnings"`
+	// Attributes apply to all spans of the trace
+	Attributes []KeyValue `json:"attributes,omitempty"`
 }
 
 // Span is a span denoting a piece of work in some infrastructure
@@ -81,6 +83,7 @@ type Reference struct {
 	RefType ReferenceType `json:"refType"`
 	TraceID TraceID       `json:"traceID"`
 	SpanID  SpanID        `json:"spanID"`
+	Tags    []KeyValue    `json:"tags,omitempty"`
 }
 
 // Process is the process emitting a set of spans
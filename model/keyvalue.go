@@ -22,6 +22,7 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"strings"
 )
 
 // ValueType describes the type of value contained in a KeyValue struct
@@ -127,6 +128,84 @@ func (kv *KeyValue) Binary() []byte {
 	return nil
 }
 
+// AsInt64Safe returns the value as int64. Besides Int64 values, it accepts Float64 values
+// without a fractional part that fit into int64 and strings holding a decimal integer,
+// and returns an error for all other values.
+func (kv *KeyValue) AsInt64Safe() (int64, error) {
+	switch kv.VType {
+	case Int64Type:
+		return kv.Int64(), nil
+	case Float64Type:
+		f := kv.Float64()
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), nil
+		}
+	case StringType:
+		if i, err := strconv.ParseInt(strings.TrimSpace(kv.VStr), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	return 0, kv.conversionError("int64")
+}
+
+// AsFloat64Safe returns the value as float64. Besides Float64 values, it accepts Int64 values
+// and strings holding a number, and returns an error for all other values.
+func (kv *KeyValue) AsFloat64Safe() (float64, error) {
+	switch kv.VType {
+	case Float64Type:
+		return kv.Float64(), nil
+	case Int64Type:
+		return float64(kv.Int64()), nil
+	case StringType:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(kv.VStr), 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, kv.conversionError("float64")
+}
+
+// AsBoolSafe returns the value as bool. Besides Bool values, it accepts boolean-like
+// strings, case-insensitively: "true", "t", "yes", "y", "on" and "1", or "false", "f",
+// "no", "n", "off" and "0", and int64 values 1 and 0. It returns an error for all other values.
+// This is the coercion used for boolean tags throughout the model, e.g. by Span.IsError.
+func (kv *KeyValue) AsBoolSafe() (bool, error) {
+	switch kv.VType {
+	case BoolType:
+		return kv.Bool(), nil
+	case StringType:
+		switch strings.ToLower(strings.TrimSpace(kv.VStr)) {
+		case "true", "t", "yes", "y", "on", "1":
+			return true, nil
+		case "false", "f", "no", "n", "off", "0":
+			return false, nil
+		}
+	case Int64Type:
+		switch kv.Int64() {
+		case 1:
+			return true, nil
+		case 0:
+			return false, nil
+		}
+	}
+	return false, kv.conversionError("bool")
+}
+
+// AsBinary returns the value as []byte. Besides Binary values, it accepts strings, returning
+// their bytes, and returns an error for all other values.
+func (kv *KeyValue) AsBinary() ([]byte, error) {
+	switch kv.VType {
+	case BinaryType:
+		return kv.Binary(), nil
+	case StringType:
+		return []byte(kv.VStr), nil
+	}
+	return nil, kv.conversionError("binary")
+}
+
+func (kv *KeyValue) conversionError(target string) error {
+	return fmt.Errorf("cannot convert %s value %q of %s to %s", kv.VType, kv.AsString(), kv.Key, target)
+}
+
 // Value returns typed values stored in KeyValue as interface{}.
 func (kv *KeyValue) Value() interface{} {
 	switch kv.VType {
@@ -169,6 +248,22 @@ func (kv *KeyValue) AsString() string {
 	}
 }
 
+// Size returns the approximate number of bytes occupied by the key and the value,
+// excluding the fixed overhead of the KeyValue struct. Numeric and Boolean values
+// count as 8 bytes, since they are stored as int64.
+func (kv *KeyValue) Size() int {
+	size := len(kv.Key)
+	switch kv.VType {
+	case StringType:
+		size += len(kv.VStr)
+	case BoolType, Int64Type, Float64Type:
+		size += 8
+	case BinaryType:
+		size += len(kv.VBlob)
+	}
+	return size
+}
+
 // Equal compares KeyValue object with another KeyValue.
 func (kv *KeyValue) Equal(other *KeyValue) bool {
 	if kv.Key != other.Key {
@@ -272,6 +367,65 @@ func (kvs KeyValues) Equal(other KeyValues) bool {
 	return true
 }
 
+// Deduplicate removes the key-values that are Equal to an earlier one in the list,
+// keeping the order of the remaining ones. Key-values with the same key but different
+// values are kept. The list is modified in place and the shortened list is returned.
+func (kvs KeyValues) Deduplicate() KeyValues {
+	result := kvs[:0]
+	for i := range kvs {
+		if !containsKeyValue(result, &kvs[i]) {
+			result = append(result, kvs[i])
+		}
+	}
+	return result
+}
+
+// ToMap returns a map from key to key-value. When several key-values have the same key,
+// the first one is kept, consistent with FindByKey.
+func (kvs KeyValues) ToMap() map[string]KeyValue {
+	m := make(map[string]KeyValue, len(kvs))
+	for _, kv := range kvs {
+		if _, ok := m[kv.Key]; !ok {
+			m[kv.Key] = kv
+		}
+	}
+	return m
+}
+
+// Merge returns a new list with the key-values of this list whose keys do not appear in
+// other, followed by all key-values of other, both in their original order. That is,
+// the key-values of other replace all key-values with the same key. Neither list is modified.
+func (kvs KeyValues) Merge(other KeyValues) KeyValues {
+	keys := make(map[string]struct{}, len(other))
+	for _, kv := range other {
+		keys[kv.Key] = struct{}{}
+	}
+	merged := make(KeyValues, 0, len(kvs)+len(other))
+	for _, kv := range kvs {
+		if _, ok := keys[kv.Key]; !ok {
+			merged = append(merged, kv)
+		}
+	}
+	return append(merged, other...)
+}
+
+// Diff returns the key-values of other that are not Equal to any in this list (added),
+// and the key-values of this list that are not Equal to any in other (removed), in their
+// original order. A key-value whose value changed appears in both lists.
+func (kvs KeyValues) Diff(other KeyValues) (added, removed KeyValues) {
+	for i := range other {
+		if !containsKeyValue(kvs, &other[i]) {
+			added = append(added, other[i])
+		}
+	}
+	for i := range kvs {
+		if !containsKeyValue(other, &kvs[i]) {
+			removed = append(removed, kvs[i])
+		}
+	}
+	return added, removed
+}
+
 // Hash implements Hash from Hashable.
 func (kvs KeyValues) Hash(w io.Writer) error {
 	for i := range kvs {
@@ -299,8 +453,9 @@ func (p ValueType) String() string {
 }
 
 // ValueTypeFromString converts a string into ValueType enum.
+// The names are matched case-insensitively, so "INT64" is accepted as well as "int64".
 func ValueTypeFromString(s string) (ValueType, error) {
-	switch s {
+	switch strings.ToLower(s) {
 	case stringTypeStr:
 		return StringType, nil
 	case boolTypeStr:
@@ -315,6 +470,11 @@ func ValueTypeFromString(s string) (ValueType, error) {
 	return ValueType(0), fmt.Errorf("not a valid ValueType string %s", s)
 }
 
+// AllValueTypes returns all valid value types, in the order of their numeric values.
+func AllValueTypes() []ValueType {
+	return []ValueType{StringType, BoolType, Int64Type, Float64Type, BinaryType}
+}
+
 // MarshalText allows ValueType to serialize itself in JSON as a string.
 func (p ValueType) MarshalText() ([]byte, error) {
 	return []byte(p.String()), nil
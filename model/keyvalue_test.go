@@ -16,6 +16,7 @@ package model_test
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,6 +49,25 @@ func TestValueTypeToFromString(t *testing.T) {
 	}
 }
 
+func TestValueTypeFromStringCaseInsensitive(t *testing.T) {
+	for _, s := range []string{"INT64", "Int64", "int64"} {
+		v, err := model.ValueTypeFromString(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, model.Int64Type, v, s)
+	}
+}
+
+func TestAllValueTypes(t *testing.T) {
+	types := model.AllValueTypes()
+	assert.Len(t, types, 5)
+	for i, v := range types {
+		assert.Equal(t, model.ValueType(i), v)
+		parsed, err := model.ValueTypeFromString(v.String())
+		assert.NoError(t, err)
+		assert.Equal(t, v, parsed)
+	}
+}
+
 func TestValueTypeToFromJSON(t *testing.T) {
 	kv := model.Int64("x", 123)
 	out, err := json.Marshal(kv)
@@ -101,6 +121,57 @@ func TestKeyValueBinary(t *testing.T) {
 	assert.Equal(t, []byte{123, 45}, kv.Binary())
 }
 
+func TestKeyValueTypedGetters(t *testing.T) {
+	testCases := []struct {
+		kv        model.KeyValue
+		asInt64   interface{}
+		asFloat64 interface{}
+		asBool    interface{}
+		asBinary  interface{}
+	}{
+		{kv: model.Int64("k", -7), asInt64: int64(-7), asFloat64: float64(-7)},
+		{kv: model.Int64("k", 1), asInt64: int64(1), asFloat64: float64(1), asBool: true},
+		{kv: model.String("k", " Yes "), asBool: true, asBinary: []byte(" Yes ")},
+		{kv: model.String("k", "off"), asBool: false, asBinary: []byte("off")},
+		{kv: model.Float64("k", 42), asInt64: int64(42), asFloat64: float64(42)},
+		{kv: model.Float64("k", 1.5), asFloat64: 1.5},
+		{kv: model.Float64("k", math.Inf(1)), asFloat64: math.Inf(1)},
+		{kv: model.Float64("k", 1<<63), asFloat64: float64(1 << 63)},
+		{kv: model.String("k", " 123 "), asInt64: int64(123), asFloat64: float64(123), asBinary: []byte(" 123 ")},
+		{kv: model.String("k", "1e3"), asFloat64: float64(1000), asBinary: []byte("1e3")},
+		{kv: model.String("k", "1"), asInt64: int64(1), asFloat64: float64(1), asBool: true, asBinary: []byte("1")},
+		{kv: model.String("k", "false"), asBool: false, asBinary: []byte("false")},
+		{kv: model.String("k", ""), asBinary: []byte{}},
+		{kv: model.Bool("k", true), asBool: true},
+		{kv: model.Binary("k", []byte{1, 2}), asBinary: []byte{1, 2}},
+	}
+	for _, testCase := range testCases {
+		check := func(expected interface{}, actual interface{}, err error) {
+			if expected == nil {
+				assert.Error(t, err, "%+v", testCase.kv)
+			} else if assert.NoError(t, err, "%+v", testCase.kv) {
+				assert.Equal(t, expected, actual, "%+v", testCase.kv)
+			}
+		}
+		i, err := testCase.kv.AsInt64Safe()
+		check(testCase.asInt64, i, err)
+		f, err := testCase.kv.AsFloat64Safe()
+		check(testCase.asFloat64, f, err)
+		b, err := testCase.kv.AsBoolSafe()
+		check(testCase.asBool, b, err)
+		blob, err := testCase.kv.AsBinary()
+		check(testCase.asBinary, blob, err)
+	}
+
+	nan := model.Float64("k", math.NaN())
+	_, err := nan.AsInt64Safe()
+	assert.Error(t, err)
+
+	kv := model.String("http.status_code", "OK")
+	_, err = kv.AsInt64Safe()
+	assert.EqualError(t, err, `cannot convert string value "OK" of http.status_code to int64`)
+}
+
 func TestKeyValueIsLessAndEqual(t *testing.T) {
 	testCases := []struct {
 		name  string
@@ -178,3 +249,20 @@ func TestKeyValueAsStringAndValue(t *testing.T) {
 		assert.EqualError(t, kv.Value().(error), "unknown type -1")
 	})
 }
+
+func TestKeyValueSize(t *testing.T) {
+	testCases := []struct {
+		kv   model.KeyValue
+		size int
+	}{
+		{kv: model.String("key", "value"), size: 8},
+		{kv: model.Bool("key", true), size: 11},
+		{kv: model.Int64("key", 1), size: 11},
+		{kv: model.Float64("key", 1), size: 11},
+		{kv: model.Binary("key", []byte{1, 2}), size: 5},
+		{kv: model.KeyValue{Key: "key", VType: model.ValueType(-1)}, size: 3},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.size, testCase.kv.Size(), testCase.kv.VType.String())
+	}
+}
@@ -107,6 +107,60 @@ func TestKeyValuesHashErrors(t *testing.T) {
 	assert.EqualError(t, kvs.Hash(w), "unknown type -1")
 }
 
+func TestKeyValuesDeduplicate(t *testing.T) {
+	kvs := model.KeyValues{
+		model.String("a", "1"),
+		model.String("b", "1"),
+		model.String("a", "1"),
+		model.Int64("a", 1),
+		model.String("a", "2"),
+		model.String("b", "1"),
+	}
+	assert.Equal(t, model.KeyValues{
+		model.String("a", "1"),
+		model.String("b", "1"),
+		model.Int64("a", 1),
+		model.String("a", "2"),
+	}, kvs.Deduplicate())
+	assert.Empty(t, model.KeyValues(nil).Deduplicate())
+}
+
+func TestKeyValuesToMap(t *testing.T) {
+	kvs := model.KeyValues{model.String("a", "1"), model.Int64("b", 2), model.String("a", "3")}
+	assert.Equal(t, map[string]model.KeyValue{
+		"a": model.String("a", "1"),
+		"b": model.Int64("b", 2),
+	}, kvs.ToMap())
+	assert.Empty(t, model.KeyValues(nil).ToMap())
+}
+
+func TestKeyValuesMerge(t *testing.T) {
+	kvs := model.KeyValues{model.String("a", "1"), model.String("b", "2"), model.String("a", "3"), model.Bool("c", true)}
+	other := model.KeyValues{model.Int64("a", 4), model.String("d", "5")}
+	assert.Equal(t, model.KeyValues{
+		model.String("b", "2"),
+		model.Bool("c", true),
+		model.Int64("a", 4),
+		model.String("d", "5"),
+	}, kvs.Merge(other))
+	assert.Len(t, kvs, 4, "inputs must not be modified")
+	assert.Equal(t, model.String("a", "1"), kvs[0], "inputs must not be modified")
+	assert.Equal(t, other, model.KeyValues(nil).Merge(other))
+	assert.Equal(t, kvs, kvs.Merge(nil))
+}
+
+func TestKeyValuesDiff(t *testing.T) {
+	kvs := model.KeyValues{model.String("a", "1"), model.String("b", "2"), model.Int64("c", 3)}
+	other := model.KeyValues{model.String("a", "1"), model.String("b", "x"), model.String("d", "4")}
+	added, removed := kvs.Diff(other)
+	assert.Equal(t, model.KeyValues{model.String("b", "x"), model.String("d", "4")}, added)
+	assert.Equal(t, model.KeyValues{model.String("b", "2"), model.Int64("c", 3)}, removed)
+
+	added, removed = kvs.Diff(kvs)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
 // No memory allocations for IsLess and Equal
 // 18.6 ns/op	       0 B/op	       0 allocs/op
 func BenchmarkKeyValueIsLessAndEquals(b *testing.B) {
@@ -143,3 +197,52 @@ func BenchmarkKeyValuesWrapping(b *testing.B) {
 		model.KeyValues(kv).Len()
 	}
 }
+
+func makeBenchmarkKeyValues() model.KeyValues {
+	return model.KeyValues{
+		model.String("http.method", "GET"),
+		model.Int64("http.status_code", 200),
+		model.String("http.url", "/api/traces"),
+		model.String("http.method", "GET"),
+		model.Bool("error", false),
+		model.String("component", "net/http"),
+		model.Float64("sampler.param", 1),
+		model.String("span.kind", "server"),
+	}
+}
+
+// 116 ns/op	       0 B/op	       0 allocs/op
+func BenchmarkKeyValuesDeduplicate(b *testing.B) {
+	kvs := makeBenchmarkKeyValues()
+	list := make(model.KeyValues, len(kvs))
+	for i := 0; i < b.N; i++ {
+		copy(list, kvs)
+		list.Deduplicate()
+	}
+}
+
+// 302 ns/op	       0 B/op	       0 allocs/op
+func BenchmarkKeyValuesToMap(b *testing.B) {
+	kvs := makeBenchmarkKeyValues()
+	for i := 0; i < b.N; i++ {
+		kvs.ToMap()
+	}
+}
+
+// 692 ns/op	     768 B/op	       1 allocs/op
+func BenchmarkKeyValuesMerge(b *testing.B) {
+	kvs := makeBenchmarkKeyValues()
+	other := model.KeyValues{model.Int64("http.status_code", 500), model.Bool("error", true)}
+	for i := 0; i < b.N; i++ {
+		kvs.Merge(other)
+	}
+}
+
+// 440 ns/op	     160 B/op	       2 allocs/op
+func BenchmarkKeyValuesDiff(b *testing.B) {
+	kvs := makeBenchmarkKeyValues()
+	other := kvs.Merge(model.KeyValues{model.Int64("http.status_code", 500)})
+	for i := 0; i < b.N; i++ {
+		kvs.Diff(other)
+	}
+}
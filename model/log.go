@@ -14,10 +14,39 @@
 
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Log describes a micro-log entry that consists of a timestamp and one or more key-value fields
 type Log struct {
 	Timestamp time.Time  `json:"timestamp"`
 	Fields    []KeyValue `json:"fields"`
 }
+
+// logLevelKeys are the log field keys that may hold the level of a structured log, in order of precedence.
+var logLevelKeys = []string{"level", "severity"}
+
+// Level returns the level of a structured log, read from the `level` or `severity` field.
+func (l *Log) Level() (string, bool) {
+	for _, key := range logLevelKeys {
+		if field, ok := KeyValues(l.Fields).FindByKey(key); ok {
+			return field.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// isError returns true if the log has level error or fatal, or an `event=error` field.
+func (l *Log) isError() bool {
+	if level, ok := l.Level(); ok {
+		if strings.EqualFold(level, "error") || strings.EqualFold(level, "fatal") {
+			return true
+		}
+	}
+	if event, ok := KeyValues(l.Fields).FindByKey(EventKey); ok {
+		return event.AsString() == "error"
+	}
+	return false
+}
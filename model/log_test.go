@@ -0,0 +1,71 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestLogLevel(t *testing.T) {
+	testCases := []struct {
+		fields []model.KeyValue
+		level  string
+		ok     bool
+	}{
+		{fields: nil},
+		{fields: []model.KeyValue{model.String("event", "error")}},
+		{fields: []model.KeyValue{model.String("level", "info")}, level: "info", ok: true},
+		{fields: []model.KeyValue{model.String("severity", "WARN")}, level: "WARN", ok: true},
+		{
+			fields: []model.KeyValue{model.String("severity", "warn"), model.String("level", "debug")},
+			level:  "debug",
+			ok:     true,
+		},
+		{fields: []model.KeyValue{model.Int64("severity", 17)}, level: "17", ok: true},
+	}
+	for _, testCase := range testCases {
+		log := model.Log{Fields: testCase.fields}
+		level, ok := log.Level()
+		assert.Equal(t, testCase.ok, ok)
+		assert.Equal(t, testCase.level, level)
+	}
+}
+
+func TestSpanHasErrorLog(t *testing.T) {
+	testCases := []struct {
+		fields   []model.KeyValue
+		hasError bool
+	}{
+		{fields: []model.KeyValue{model.String("level", "info")}, hasError: false},
+		{fields: []model.KeyValue{model.String("level", "ERROR")}, hasError: true},
+		{fields: []model.KeyValue{model.String("severity", "fatal")}, hasError: true},
+		{fields: []model.KeyValue{model.String("event", "error")}, hasError: true},
+		{fields: []model.KeyValue{model.String("event", "retry")}, hasError: false},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{
+			Logs: []model.Log{
+				{Fields: []model.KeyValue{model.String("message", "hello")}},
+				{Fields: testCase.fields},
+			},
+		}
+		assert.Equal(t, testCase.hasError, span.HasErrorLog(), "%+v", testCase.fields)
+	}
+	assert.False(t, (&model.Span{}).HasErrorLog())
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "fmt"
+
+// Merge merges another copy of the same span into this span, e.g. when the span
+// was received more than once. The merged span covers the time window of both spans,
+// and contains the union of their tags, logs, references, and warnings. For other
+// fields, the values of this span take precedence unless they are not set.
+func (s *Span) Merge(other *Span) {
+	s.mergeTimeWindow(other)
+	s.Tags = mergeKeyValues(s.Tags, other.Tags)
+	s.mergeDetails(other)
+}
+
+// PartialSpanTagKey is the tag with which clients mark the parts of a long-running span
+// that is flushed more than once, see Trace.MergePartialSpans.
+const PartialSpanTagKey = "jaeger.partial"
+
+// MergePartialSpans merges the spans marked with the `jaeger.partial` tag that have the
+// same trace and span IDs into a single span, see Span.Merge. The merged span replaces
+// the first of its parts in the trace and keeps a single partial marker, as more parts
+// may still arrive and be merged into it by a later call. When the parts have different
+// operation names or processes, the values of the first part are kept and a warning is
+// added to the merged span.
+func (t *Trace) MergePartialSpans() {
+	merged := make(map[[24]byte]*Span)
+	absorbed := make(map[*Span]struct{})
+	spans := t.Spans[:0]
+	for _, span := range t.Spans {
+		if !span.isPartial() {
+			spans = append(spans, span)
+			continue
+		}
+		key := span.CacheKey()
+		first, ok := merged[key]
+		if !ok {
+			merged[key] = span
+			spans = append(spans, span)
+			continue
+		}
+		if first.OperationName != "" && span.OperationName != "" && first.OperationName != span.OperationName {
+			first.Warnings = append(first.Warnings, fmt.Sprintf(
+				"cannot reconcile operation names of partial spans: kept %q, dropped %q", first.OperationName, span.OperationName))
+		}
+		if first.Process != nil && span.Process != nil && !first.Process.Equal(span.Process) {
+			first.Warnings = append(first.Warnings, fmt.Sprintf(
+				"cannot reconcile processes of partial spans: kept %q, dropped %q", first.Process.ServiceName, span.Process.ServiceName))
+		}
+		first.Merge(span)
+		absorbed[first] = struct{}{}
+	}
+	for span := range absorbed {
+		span.RemoveTag(PartialSpanTagKey)
+		span.Tags = append(span.Tags, Bool(PartialSpanTagKey, true))
+	}
+	t.Spans = spans
+}
+
+func (s *Span) isPartial() bool {
+	for i := range s.Tags {
+		if s.Tags[i].Key != PartialSpanTagKey {
+			continue
+		}
+		value, _ := s.Tags[i].AsBoolSafe()
+		return value
+	}
+	return false
+}
+
+// TagConflictPolicy describes how MergeWithPolicy resolves tags present in both
+// copies of a span with the same key but different values.
+type TagConflictPolicy int
+
+const (
+	// PreferExistingTags keeps the tags of the existing span and drops conflicting incoming tags
+	PreferExistingTags TagConflictPolicy = iota
+	// PreferIncomingTags replaces the conflicting tags of the existing span with the incoming tags
+	PreferIncomingTags
+	// KeepBothTagsWithSuffix keeps the existing tags and adds the conflicting incoming
+	// tags with MergePolicy.Suffix appended to their keys
+	KeepBothTagsWithSuffix
+)
+
+// TimingPolicy describes how MergeWithPolicy selects the start time and duration of the merged span.
+type TimingPolicy int
+
+const (
+	// UnionTiming makes the merged span cover the time windows of both copies, as Span.Merge does
+	UnionTiming TimingPolicy = iota
+	// PreferExistingTiming keeps the start time and duration of the existing span
+	PreferExistingTiming
+	// PreferIncomingTiming uses the start time and duration of the incoming span
+	PreferIncomingTiming
+)
+
+// DefaultMergeSuffix is appended to the keys of conflicting tags by KeepBothTagsWithSuffix
+// when MergePolicy.Suffix is empty.
+const DefaultMergeSuffix = ".incoming"
+
+// MergePolicy controls how conflicts are resolved when merging copies of the same span.
+// The zero value, used by Trace.Merge, keeps the existing tags on conflict and makes
+// the merged span cover the time windows of both copies.
+type MergePolicy struct {
+	Tags   TagConflictPolicy
+	Timing TimingPolicy
+	// Suffix is used by KeepBothTagsWithSuffix, DefaultMergeSuffix if empty.
+	Suffix string
+}
+
+// Merge merges the spans, warnings and attributes of another trace into this trace with the
+// default MergePolicy, see MergeWithPolicy.
+func (t *Trace) Merge(other *Trace) {
+	t.MergeWithPolicy(other, MergePolicy{})
+}
+
+// MergeWithPolicy merges the spans, warnings and attributes of another trace into this
+// trace, e.g. when the same trace was received by more than one collector. Spans with
+// the same trace and span IDs are merged into the existing span, resolving conflicting
+// tags and timing according to the policy, and taking the union of logs, references and
+// warnings. Other spans are added to the trace; they are not copied. Conflicting trace
+// attributes are resolved like span tags.
+func (t *Trace) MergeWithPolicy(other *Trace, policy MergePolicy) {
+	spansByKey := make(map[[24]byte]*Span, len(t.Spans))
+	for _, span := range t.Spans {
+		if key := span.CacheKey(); spansByKey[key] == nil {
+			spansByKey[key] = span
+		}
+	}
+	for _, span := range other.Spans {
+		key := span.CacheKey()
+		existing, ok := spansByKey[key]
+		if !ok {
+			t.Spans = append(t.Spans, span)
+			spansByKey[key] = span
+			continue
+		}
+		switch policy.Timing {
+		case PreferExistingTiming:
+		case PreferIncomingTiming:
+			existing.StartTime = span.StartTime
+			existing.Duration = span.Duration
+		default:
+			existing.mergeTimeWindow(span)
+		}
+		existing.Tags = mergeTagsWithPolicy(existing.Tags, span.Tags, policy)
+		existing.mergeDetails(span)
+	}
+	t.Warnings = mergeStrings(t.Warnings, other.Warnings)
+	t.Attributes = mergeTagsWithPolicy(t.Attributes, other.Attributes, policy)
+}
+
+// MergeTraces returns a new trace combining the spans, warnings and attributes of both traces,
+// e.g. parts of the same trace fetched from primary and archive storage. Copies of
+// the same span, within or across the traces, are merged as by Trace.Merge. Either
+// trace may be nil. The input traces and their spans are not modified.
+func MergeTraces(a, b *Trace) *Trace {
+	merged := &Trace{}
+	for _, t := range []*Trace{a, b} {
+		if t == nil {
+			continue
+		}
+		copied := &Trace{Spans: make([]*Span, len(t.Spans)), Warnings: t.Warnings, Attributes: t.Attributes}
+		for i, span := range t.Spans {
+			copied.Spans[i] = span.shallowCopy()
+		}
+		merged.Merge(copied)
+	}
+	return merged
+}
+
+// shallowCopy copies the span and its slices of tags, logs, references and warnings,
+// which merging appends to, but shares the process and the contents of the logs.
+func (s *Span) shallowCopy() *Span {
+	c := *s
+	c.References = append([]SpanRef(nil), s.References...)
+	c.Tags = append([]KeyValue(nil), s.Tags...)
+	c.Logs = append([]Log(nil), s.Logs...)
+	c.Warnings = append([]string(nil), s.Warnings...)
+	return &c
+}
+
+// mergeTimeWindow extends the span to cover the time window of the other span.
+func (s *Span) mergeTimeWindow(other *Span) {
+	end := s.StartTime.Add(s.Duration)
+	if otherEnd := other.StartTime.Add(other.Duration); otherEnd.After(end) {
+		end = otherEnd
+	}
+	if s.StartTime.IsZero() || (!other.StartTime.IsZero() && other.StartTime.Before(s.StartTime)) {
+		s.StartTime = other.StartTime
+	}
+	s.Duration = end.Sub(s.StartTime)
+}
+
+// mergeDetails merges all fields of the other span except the timing and tags.
+func (s *Span) mergeDetails(other *Span) {
+	if s.OperationName == "" {
+		s.OperationName = other.OperationName
+	}
+	if s.Process == nil {
+		s.Process = other.Process
+	}
+	s.Flags |= other.Flags
+	s.Logs = mergeLogs(s.Logs, other.Logs)
+	s.References = mergeReferences(s.References, other.References)
+	s.Warnings = mergeStrings(s.Warnings, other.Warnings)
+}
+
+func mergeTagsWithPolicy(tags, other []KeyValue, policy MergePolicy) []KeyValue {
+	keys := make(map[string]struct{}, len(tags))
+	conflictKeys := tags
+	if policy.Tags == PreferIncomingTags {
+		conflictKeys = other
+	}
+	for i := range conflictKeys {
+		keys[conflictKeys[i].Key] = struct{}{}
+	}
+	switch policy.Tags {
+	case PreferIncomingTags:
+		merged := make([]KeyValue, 0, len(tags)+len(other))
+		for i := range tags {
+			if _, ok := keys[tags[i].Key]; !ok {
+				merged = append(merged, tags[i])
+			}
+		}
+		return mergeKeyValues(merged, other)
+	case KeepBothTagsWithSuffix:
+		suffix := policy.Suffix
+		if suffix == "" {
+			suffix = DefaultMergeSuffix
+		}
+		for i := range other {
+			tag := other[i]
+			if containsKeyValue(tags, &tag) {
+				continue
+			}
+			if _, ok := keys[tag.Key]; ok {
+				tag.Key += suffix
+			}
+			tags = mergeKeyValues(tags, []KeyValue{tag})
+		}
+		return tags
+	default:
+		for i := range other {
+			if _, ok := keys[other[i].Key]; !ok {
+				tags = mergeKeyValues(tags, other[i:i+1])
+			}
+		}
+		return tags
+	}
+}
+
+func mergeKeyValues(kvs, other []KeyValue) []KeyValue {
+	for i := range other {
+		if !containsKeyValue(kvs, &other[i]) {
+			kvs = append(kvs, other[i])
+		}
+	}
+	return kvs
+}
+
+func containsKeyValue(kvs []KeyValue, kv *KeyValue) bool {
+	for i := range kvs {
+		if kvs[i].Equal(kv) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeLogs(logs, other []Log) []Log {
+	for _, log := range other {
+		found := false
+		for i := range logs {
+			if logs[i].Timestamp.Equal(log.Timestamp) && KeyValues(logs[i].Fields).Equal(log.Fields) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+func mergeReferences(refs, other []SpanRef) []SpanRef {
+	for _, ref := range other {
+		found := false
+		for i := range refs {
+			if refs[i].Equal(&ref) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func mergeStrings(values, other []string) []string {
+	for _, value := range other {
+		if !containsString(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanMerge(t *testing.T) {
+	base := time.Unix(100, 0)
+	traceID := model.TraceID{Low: 1}
+	span1 := &model.Span{
+		TraceID:    traceID,
+		SpanID:     2,
+		StartTime:  base.Add(time.Second),
+		Duration:   time.Second,
+		Flags:      model.Flags(1),
+		Tags:       model.KeyValues{model.String("a", "1"), model.String("b", "2")},
+		Logs:       []model.Log{{Timestamp: base, Fields: model.KeyValues{model.String("x", "y")}}},
+		References: []model.SpanRef{model.NewChildOfRef(traceID, 1)},
+		Warnings:   []string{"w1"},
+	}
+	span2 := &model.Span{
+		TraceID:       traceID,
+		SpanID:        2,
+		OperationName: "op",
+		StartTime:     base,
+		Duration:      1500 * time.Millisecond,
+		Flags:         model.Flags(2),
+		Tags:          model.KeyValues{model.String("b", "2"), model.String("b", "3")},
+		Logs: []model.Log{
+			{Timestamp: base, Fields: model.KeyValues{model.String("x", "y")}},
+			{Timestamp: base.Add(time.Second), Fields: model.KeyValues{model.String("x", "z")}},
+		},
+		References: []model.SpanRef{model.NewChildOfRef(traceID, 1), model.NewFollowsFromRef(traceID, 5)},
+		Process:    model.NewProcess("svc", nil),
+		Warnings:   []string{"w1", "w2"},
+	}
+	span1.Merge(span2)
+	assert.Equal(t, &model.Span{
+		TraceID:       traceID,
+		SpanID:        2,
+		OperationName: "op",
+		StartTime:     base,
+		Duration:      2 * time.Second,
+		Flags:         model.Flags(3),
+		Tags:          model.KeyValues{model.String("a", "1"), model.String("b", "2"), model.String("b", "3")},
+		Logs: []model.Log{
+			{Timestamp: base, Fields: model.KeyValues{model.String("x", "y")}},
+			{Timestamp: base.Add(time.Second), Fields: model.KeyValues{model.String("x", "z")}},
+		},
+		References: []model.SpanRef{model.NewChildOfRef(traceID, 1), model.NewFollowsFromRef(traceID, 5)},
+		Process:    model.NewProcess("svc", nil),
+		Warnings:   []string{"w1", "w2"},
+	}, span1)
+}
+
+func TestSpanMergeZeroStartTime(t *testing.T) {
+	base := time.Unix(100, 0)
+	span := &model.Span{OperationName: "a"}
+	span.Merge(&model.Span{OperationName: "b", StartTime: base, Duration: time.Second})
+	assert.Equal(t, "a", span.OperationName)
+	assert.Equal(t, base, span.StartTime)
+	assert.Equal(t, time.Second, span.Duration)
+}
+
+func makeMergeTraces() (*model.Trace, *model.Trace) {
+	base := time.Unix(100, 0)
+	traceID := model.TraceID{Low: 1}
+	existing := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:   traceID,
+				SpanID:    1,
+				StartTime: base,
+				Duration:  2 * time.Second,
+				Tags:      model.KeyValues{model.String("region", "us"), model.String("a", "1")},
+			},
+		},
+		Warnings:   []string{"w1"},
+		Attributes: model.KeyValues{model.String("tenant", "acme")},
+	}
+	incoming := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       traceID,
+				SpanID:        1,
+				OperationName: "op",
+				StartTime:     base.Add(time.Second),
+				Duration:      3 * time.Second,
+				Tags:          model.KeyValues{model.String("region", "eu"), model.String("a", "1"), model.String("b", "2")},
+			},
+			{TraceID: traceID, SpanID: 2},
+			{TraceID: model.TraceID{Low: 2}, SpanID: 1},
+		},
+		Warnings:   []string{"w1", "w2"},
+		Attributes: model.KeyValues{model.String("tenant", "other"), model.String("release", "v2")},
+	}
+	return existing, incoming
+}
+
+func TestTraceMerge(t *testing.T) {
+	trace, other := makeMergeTraces()
+	trace.Merge(other)
+	assert.Len(t, trace.Spans, 3)
+	assert.True(t, trace.Spans[1] == other.Spans[1], "new spans are added without copying")
+	assert.True(t, trace.Spans[2] == other.Spans[2], "spans of other traces are not merged")
+	assert.Equal(t, []string{"w1", "w2"}, trace.Warnings)
+	assert.Equal(t, model.KeyValues{model.String("tenant", "acme"), model.String("release", "v2")}, trace.Attributes)
+
+	span := trace.Spans[0]
+	assert.Equal(t, "op", span.OperationName)
+	assert.Equal(t, time.Unix(100, 0), span.StartTime)
+	assert.Equal(t, 4*time.Second, span.Duration)
+	assert.Equal(t, model.KeyValues{model.String("region", "us"), model.String("a", "1"), model.String("b", "2")}, model.KeyValues(span.Tags))
+
+	_, again := makeMergeTraces()
+	trace.Merge(again)
+	assert.Len(t, trace.Spans, 3, "spans of other traces are merged on repeated merges")
+}
+
+func TestMergeTraces(t *testing.T) {
+	a, b := makeMergeTraces()
+	b.Spans = append(b.Spans, &model.Span{TraceID: a.Spans[0].TraceID, SpanID: 2, Warnings: []string{"dup"}})
+	merged := model.MergeTraces(a, b)
+	assert.Len(t, merged.Spans, 3, "duplicate spans within a trace are merged too")
+	assert.Equal(t, []string{"w1", "w2"}, merged.Warnings)
+	assert.Equal(t, model.KeyValues{model.String("tenant", "acme"), model.String("release", "v2")}, merged.Attributes)
+
+	span := merged.Spans[0]
+	assert.Equal(t, "op", span.OperationName)
+	assert.Equal(t, 4*time.Second, span.Duration)
+	assert.Equal(t, model.KeyValues{model.String("region", "us"), model.String("a", "1"), model.String("b", "2")}, model.KeyValues(span.Tags))
+	assert.Equal(t, []string{"dup"}, merged.Spans[1].Warnings)
+
+	original, originalOther := makeMergeTraces()
+	originalOther.Spans = append(originalOther.Spans, &model.Span{TraceID: a.Spans[0].TraceID, SpanID: 2, Warnings: []string{"dup"}})
+	assert.Equal(t, original, a, "inputs must not be modified")
+	assert.Equal(t, originalOther, b, "inputs must not be modified")
+	assert.False(t, merged.Spans[0] == a.Spans[0])
+}
+
+func TestMergeTracesNil(t *testing.T) {
+	a, _ := makeMergeTraces()
+	merged := model.MergeTraces(a, nil)
+	assert.Equal(t, a, merged)
+	assert.False(t, merged.Spans[0] == a.Spans[0])
+	assert.Equal(t, &model.Trace{}, model.MergeTraces(nil, nil))
+}
+
+func TestTraceMergeWithPolicy(t *testing.T) {
+	base := time.Unix(100, 0)
+	testCases := []struct {
+		policy    model.MergePolicy
+		tags      model.KeyValues
+		startTime time.Time
+		duration  time.Duration
+	}{
+		{
+			policy:    model.MergePolicy{Tags: model.PreferIncomingTags, Timing: model.PreferExistingTiming},
+			tags:      model.KeyValues{model.String("region", "eu"), model.String("a", "1"), model.String("b", "2")},
+			startTime: base,
+			duration:  2 * time.Second,
+		},
+		{
+			policy:    model.MergePolicy{Tags: model.KeepBothTagsWithSuffix, Timing: model.PreferIncomingTiming},
+			tags:      model.KeyValues{model.String("region", "us"), model.String("a", "1"), model.String("region.incoming", "eu"), model.String("b", "2")},
+			startTime: base.Add(time.Second),
+			duration:  3 * time.Second,
+		},
+		{
+			policy:    model.MergePolicy{Tags: model.KeepBothTagsWithSuffix, Suffix: "_eu"},
+			tags:      model.KeyValues{model.String("region", "us"), model.String("a", "1"), model.String("region_eu", "eu"), model.String("b", "2")},
+			startTime: base,
+			duration:  4 * time.Second,
+		},
+	}
+	for _, testCase := range testCases {
+		trace, other := makeMergeTraces()
+		trace.MergeWithPolicy(other, testCase.policy)
+		span := trace.Spans[0]
+		assert.Equal(t, testCase.tags, model.KeyValues(span.Tags), "%+v", testCase.policy)
+		assert.Equal(t, testCase.startTime, span.StartTime, "%+v", testCase.policy)
+		assert.Equal(t, testCase.duration, span.Duration, "%+v", testCase.policy)
+	}
+}
+
+func TestTraceMergePartialSpans(t *testing.T) {
+	base := time.Unix(100, 0)
+	traceID := model.TraceID{Low: 1}
+	partial := model.Bool("jaeger.partial", true)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       traceID,
+				SpanID:        1,
+				OperationName: "batch",
+				StartTime:     base,
+				Duration:      time.Second,
+				Tags:          model.KeyValues{partial, model.Int64("items", 10)},
+				Process:       model.NewProcess("worker", nil),
+			},
+			{TraceID: traceID, SpanID: 2, Tags: model.KeyValues{model.String("k", "v")}},
+			{
+				TraceID:   traceID,
+				SpanID:    1,
+				StartTime: base.Add(time.Second),
+				Duration:  2 * time.Second,
+				Tags:      model.KeyValues{model.String("jaeger.partial", "true"), model.Int64("items", 20)},
+				Logs:      []model.Log{{Timestamp: base.Add(2 * time.Second)}},
+			},
+			{TraceID: traceID, SpanID: 1, Tags: model.KeyValues{model.String("k", "not partial")}},
+			{TraceID: model.TraceID{Low: 2}, SpanID: 1, Tags: model.KeyValues{partial}},
+		},
+	}
+	trace.MergePartialSpans()
+	require.Len(t, trace.Spans, 4)
+	assert.Equal(t, &model.Span{
+		TraceID:       traceID,
+		SpanID:        1,
+		OperationName: "batch",
+		StartTime:     base,
+		Duration:      3 * time.Second,
+		Tags:          model.KeyValues{model.Int64("items", 10), model.Int64("items", 20), partial},
+		Logs:          []model.Log{{Timestamp: base.Add(2 * time.Second)}},
+		Process:       model.NewProcess("worker", nil),
+	}, trace.Spans[0])
+	assert.Equal(t, model.SpanID(2), trace.Spans[1].SpanID)
+	assert.Equal(t, model.KeyValues{model.String("k", "not partial")}, model.KeyValues(trace.Spans[2].Tags))
+	assert.Equal(t, model.KeyValues{partial}, model.KeyValues(trace.Spans[3].Tags), "partial marker is kept on single parts")
+}
+
+func TestTraceMergePartialSpansAcrossCalls(t *testing.T) {
+	base := time.Unix(100, 0)
+	partial := model.Bool("jaeger.partial", true)
+	part := func(start, duration time.Duration) *model.Span {
+		return &model.Span{SpanID: 1, StartTime: base.Add(start), Duration: duration, Tags: model.KeyValues{partial}}
+	}
+	trace := &model.Trace{Spans: []*model.Span{part(0, time.Second), part(time.Second, time.Second)}}
+	trace.MergePartialSpans()
+	require.Len(t, trace.Spans, 1)
+
+	trace.Spans = append(trace.Spans, part(2*time.Second, time.Second))
+	trace.MergePartialSpans()
+	require.Len(t, trace.Spans, 1)
+	assert.Equal(t, base, trace.Spans[0].StartTime)
+	assert.Equal(t, 3*time.Second, trace.Spans[0].Duration)
+	assert.Equal(t, model.KeyValues{partial}, model.KeyValues(trace.Spans[0].Tags))
+}
+
+func TestTraceMergePartialSpansConflicts(t *testing.T) {
+	partial := model.Bool("jaeger.partial", true)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: 1, OperationName: "a", Process: model.NewProcess("svc1", nil), Tags: model.KeyValues{partial}},
+			{SpanID: 1, OperationName: "b", Process: model.NewProcess("svc2", nil), Tags: model.KeyValues{partial}},
+			{SpanID: 1, OperationName: "a", Process: model.NewProcess("svc1", nil), Tags: model.KeyValues{partial}},
+		},
+	}
+	trace.MergePartialSpans()
+	require.Len(t, trace.Spans, 1)
+	assert.Equal(t, "a", trace.Spans[0].OperationName)
+	assert.Equal(t, "svc1", trace.Spans[0].Process.ServiceName)
+	assert.Equal(t, []string{
+		`cannot reconcile operation names of partial spans: kept "a", dropped "b"`,
+		`cannot reconcile processes of partial spans: kept "svc1", dropped "svc2"`,
+	}, trace.Spans[0].Warnings)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "fmt"
+
+// CurrentSpanVersion is the version of the current Span schema. It must be incremented
+// whenever a migration from the previous version is added to spanMigrations.
+const CurrentSpanVersion = 1
+
+// spanMigrations maps a schema version to the function that migrates spans
+// from that version to the next one.
+var spanMigrations = map[int]func(*Span) error{
+	0: migrateSpanFromV0,
+}
+
+// MigrateSpan upgrades a span deserialized from an older schema version to
+// CurrentSpanVersion by applying all migrations from fromVersion in order.
+// The span is modified in place.
+func MigrateSpan(s *Span, fromVersion int) error {
+	if fromVersion < 0 || fromVersion > CurrentSpanVersion {
+		return fmt.Errorf("unknown span schema version %d, current version is %d", fromVersion, CurrentSpanVersion)
+	}
+	for version := fromVersion; version < CurrentSpanVersion; version++ {
+		migrate, ok := spanMigrations[version]
+		if !ok {
+			return fmt.Errorf("no migration registered for span schema version %d", version)
+		}
+		if err := migrate(s); err != nil {
+			return fmt.Errorf("cannot migrate span from schema version %d: %v", version, err)
+		}
+	}
+	return nil
+}
+
+// migrateSpanFromV0 renames the `jaeger.hostname` process tag reported by old clients
+// to `hostname`, dropping it if the process already has a `hostname` tag, and removes
+// references to the zero span ID written by old encoders for spans without a parent.
+func migrateSpanFromV0(s *Span) error {
+	if s.Process != nil {
+		_, hasHostname := KeyValues(s.Process.Tags).FindByKey("hostname")
+		tags := s.Process.Tags[:0]
+		for _, tag := range s.Process.Tags {
+			if tag.Key == "jaeger.hostname" {
+				if hasHostname {
+					continue
+				}
+				tag.Key = "hostname"
+			}
+			tags = append(tags, tag)
+		}
+		KeyValues(tags).Sort()
+		s.Process.Tags = tags
+	}
+	refs := s.References[:0]
+	for _, ref := range s.References {
+		if ref.SpanID != 0 {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		refs = nil
+	}
+	s.References = refs
+	return nil
+}
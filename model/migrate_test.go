@@ -0,0 +1,67 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateSpanFromV0(t *testing.T) {
+	traceID := TraceID{Low: 1}
+	span := &Span{
+		TraceID:    traceID,
+		SpanID:     2,
+		References: []SpanRef{NewChildOfRef(traceID, 0), NewFollowsFromRef(traceID, 1)},
+		Process:    NewProcess("svc", []KeyValue{String("jaeger.hostname", "h1"), String("ip", "10.0.0.1")}),
+	}
+	assert.NoError(t, MigrateSpan(span, 0))
+	assert.Equal(t, []SpanRef{NewFollowsFromRef(traceID, 1)}, span.References)
+	assert.Equal(t, []KeyValue{String("hostname", "h1"), String("ip", "10.0.0.1")}, span.Process.Tags)
+
+	span = &Span{Process: NewProcess("svc", []KeyValue{String("hostname", "h2"), String("jaeger.hostname", "h1")})}
+	assert.NoError(t, MigrateSpan(span, 0))
+	assert.Equal(t, []KeyValue{String("hostname", "h2")}, span.Process.Tags, "existing hostname takes precedence")
+
+	span = &Span{References: []SpanRef{NewChildOfRef(traceID, 0)}}
+	assert.NoError(t, MigrateSpan(span, 0))
+	assert.Nil(t, span.References)
+}
+
+func TestMigrateSpanCurrentVersion(t *testing.T) {
+	span := &Span{References: []SpanRef{NewChildOfRef(TraceID{Low: 1}, 0)}}
+	assert.NoError(t, MigrateSpan(span, CurrentSpanVersion))
+	assert.Len(t, span.References, 1, "span of the current version must not be modified")
+}
+
+func TestMigrateSpanErrors(t *testing.T) {
+	assert.EqualError(t, MigrateSpan(&Span{}, -1), "unknown span schema version -1, current version is 1")
+	assert.EqualError(t, MigrateSpan(&Span{}, CurrentSpanVersion+1), "unknown span schema version 2, current version is 1")
+
+	defer func(migrations map[int]func(*Span) error) { spanMigrations = migrations }(spanMigrations)
+	spanMigrations = map[int]func(*Span) error{}
+	assert.EqualError(t, MigrateSpan(&Span{}, 0), "no migration registered for span schema version 0")
+	spanMigrations = map[int]func(*Span) error{0: func(*Span) error { return errors.New("boom") }}
+	assert.EqualError(t, MigrateSpan(&Span{}, 0), "cannot migrate span from schema version 0: boom")
+}
+
+func TestSpanMigrationsRegistered(t *testing.T) {
+	for version := 0; version < CurrentSpanVersion; version++ {
+		assert.Contains(t, spanMigrations, version)
+	}
+	assert.Len(t, spanMigrations, CurrentSpanVersion)
+}
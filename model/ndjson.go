@@ -0,0 +1,61 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteSpansNDJSON writes the spans to w as newline-delimited JSON (JSON Lines):
+// each span is encoded with encoding/json on a line of its own.
+func WriteSpansNDJSON(w io.Writer, spans []*Span) error {
+	encoder := json.NewEncoder(w)
+	for _, span := range spans {
+		if err := encoder.Encode(span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSpansNDJSON reads newline-delimited JSON spans written by WriteSpansNDJSON
+// from r, and calls fn for each span as soon as it is decoded, so that large dumps
+// do not need to fit in memory. Empty lines are skipped. Reading stops at the first
+// error, including errors returned by fn.
+func ReadSpansNDJSON(r io.Reader, fn func(*Span) error) error {
+	reader := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			span := &Span{}
+			if jsonErr := json.Unmarshal(trimmed, span); jsonErr != nil {
+				return fmt.Errorf("cannot decode span on line %d: %v", lineNum, jsonErr)
+			}
+			if fnErr := fn(span); fnErr != nil {
+				return fnErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpansNDJSONRoundTrip(t *testing.T) {
+	spans := []*model.Span{
+		makeSpan(model.String("k", "v")),
+		makeSpan(model.String("multi", "line\nvalue")),
+	}
+	for _, span := range spans {
+		span.NormalizeTimestamps()
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, model.WriteSpansNDJSON(buf, spans))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2, "each span must be on a single line")
+	for i, line := range lines {
+		decoded, err := model.DecodeSpanAuto([]byte(line))
+		require.NoError(t, err)
+		assert.Equal(t, spans[i], decoded)
+	}
+
+	var read []*model.Span
+	err := model.ReadSpansNDJSON(buf, func(span *model.Span) error {
+		read = append(read, span)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, spans, read)
+}
+
+func TestReadSpansNDJSON(t *testing.T) {
+	input := "\n{\"spanID\":\"1\"}\n  \n{\"spanID\":\"2\"}"
+	var ids []model.SpanID
+	err := model.ReadSpansNDJSON(strings.NewReader(input), func(span *model.Span) error {
+		ids = append(ids, span.SpanID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanID{1, 2}, ids)
+}
+
+func TestReadSpansNDJSONErrors(t *testing.T) {
+	input := "{\"spanID\":\"1\"}\n{\"spanID\":\n{\"spanID\":\"3\"}\n"
+	count := 0
+	err := model.ReadSpansNDJSON(strings.NewReader(input), func(span *model.Span) error {
+		count++
+		return nil
+	})
+	assert.EqualError(t, err, "cannot decode span on line 2: unexpected end of JSON input")
+	assert.Equal(t, 1, count)
+
+	err = model.ReadSpansNDJSON(strings.NewReader(input), func(span *model.Span) error {
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "stop")
+}
+
+func TestWriteSpansNDJSONError(t *testing.T) {
+	err := model.WriteSpansNDJSON(failingWriter{}, []*model.Span{{SpanID: 1}})
+	assert.EqualError(t, err, "write failed")
+}
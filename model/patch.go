@@ -0,0 +1,75 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TracePatch is a declarative description of edits to a trace, which can be
+// reviewed (e.g. stored as JSON) before being applied with Trace.ApplyPatch.
+type TracePatch struct {
+	// RedactTags lists the keys of span tags and log fields whose values are replaced with RedactedValue.
+	RedactTags []string `json:"redactTags,omitempty"`
+	// RemoveTags lists the keys of span tags that are removed.
+	RemoveTags []string `json:"removeTags,omitempty"`
+	// RenameOperations maps old operation names to new ones. Each span is renamed
+	// according to its original operation name, so renames can be chained or swapped.
+	RenameOperations map[string]string `json:"renameOperations,omitempty"`
+	// RemoveSpans lists the IDs of spans that are removed from the trace.
+	RemoveSpans []SpanID `json:"removeSpans,omitempty"`
+}
+
+// ApplyPatch applies the edits described by the patch to the trace.
+// The patch is validated before any changes are made, so if an error
+// is returned the trace is left unmodified.
+func (t *Trace) ApplyPatch(p TracePatch) error {
+	if err := t.validatePatch(p); err != nil {
+		return err
+	}
+	t.RemoveSpans(p.RemoveSpans...)
+	for _, span := range t.Spans {
+		if to, ok := p.RenameOperations[span.OperationName]; ok {
+			span.OperationName = to
+		}
+		for _, key := range p.RemoveTags {
+			span.RemoveTag(key)
+		}
+		for _, key := range p.RedactTags {
+			span.RedactTag(key)
+		}
+	}
+	return nil
+}
+
+func (t *Trace) validatePatch(p TracePatch) error {
+	froms := make([]string, 0, len(p.RenameOperations))
+	for from := range p.RenameOperations {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	for _, from := range froms {
+		if p.RenameOperations[from] == "" {
+			return fmt.Errorf("cannot rename operation %q to an empty name", from)
+		}
+	}
+	for _, id := range p.RemoveSpans {
+		if t.FindSpanByID(id) == nil {
+			return fmt.Errorf("cannot remove span %v: not found in trace", id)
+		}
+	}
+	return nil
+}
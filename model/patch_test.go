@@ -0,0 +1,104 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makePatchTrace() *model.Trace {
+	return &model.Trace{
+		Spans: []*model.Span{
+			{
+				SpanID:        model.SpanID(1),
+				OperationName: "GET /user",
+				Tags: model.KeyValues{
+					model.String("user.email", "x@example.com"),
+					model.String("debug.dump", "..."),
+				},
+				Logs: []model.Log{
+					{Fields: model.KeyValues{model.String("user.email", "x@example.com")}},
+				},
+			},
+			{SpanID: model.SpanID(2), OperationName: "query"},
+			{SpanID: model.SpanID(3), OperationName: "GET /user"},
+		},
+	}
+}
+
+func TestTraceApplyPatch(t *testing.T) {
+	trace := makePatchTrace()
+	err := trace.ApplyPatch(model.TracePatch{
+		RedactTags:       []string{"user.email"},
+		RemoveTags:       []string{"debug.dump"},
+		RenameOperations: map[string]string{"GET /user": "GET /user/{id}"},
+		RemoveSpans:      []model.SpanID{2},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, trace.Spans, 2)
+	assert.Nil(t, trace.FindSpanByID(model.SpanID(2)))
+
+	span := trace.FindSpanByID(model.SpanID(1))
+	assert.Equal(t, "GET /user/{id}", span.OperationName)
+	assert.Equal(t, model.KeyValues{model.String("user.email", model.RedactedValue)}, model.KeyValues(span.Tags))
+	assert.Equal(t, model.RedactedValue, span.Logs[0].Fields[0].VStr)
+	assert.Equal(t, "GET /user/{id}", trace.FindSpanByID(model.SpanID(3)).OperationName)
+}
+
+func TestTraceApplyPatchRenameChainAndSwap(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: 1, OperationName: "a"},
+			{SpanID: 2, OperationName: "b"},
+			{SpanID: 3, OperationName: "c"},
+			{SpanID: 4, OperationName: "x"},
+			{SpanID: 5, OperationName: "y"},
+		},
+	}
+	err := trace.ApplyPatch(model.TracePatch{
+		RenameOperations: map[string]string{"a": "b", "b": "c", "x": "y", "y": "x"},
+	})
+	assert.NoError(t, err)
+	var names []string
+	for _, span := range trace.Spans {
+		names = append(names, span.OperationName)
+	}
+	assert.Equal(t, []string{"b", "c", "c", "y", "x"}, names)
+}
+
+func TestTraceApplyPatchInvalid(t *testing.T) {
+	testCases := []struct {
+		patch model.TracePatch
+		err   string
+	}{
+		{
+			patch: model.TracePatch{RenameOperations: map[string]string{"query": ""}},
+			err:   `cannot rename operation "query" to an empty name`,
+		},
+		{
+			patch: model.TracePatch{RedactTags: []string{"user.email"}, RemoveSpans: []model.SpanID{7}},
+			err:   "cannot remove span 7: not found in trace",
+		},
+	}
+	for _, testCase := range testCases {
+		trace := makePatchTrace()
+		assert.EqualError(t, trace.ApplyPatch(testCase.patch), testCase.err)
+		assert.Equal(t, makePatchTrace(), trace, "trace must not be modified")
+	}
+}
@@ -14,7 +14,11 @@
 
 package model
 
-import "io"
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
 
 // Process describes an instance of an application or service that emits tracing data.
 type Process struct {
@@ -47,3 +51,61 @@ func (p *Process) Hash(w io.Writer) (err error) {
 	}
 	return KeyValues(p.Tags).Hash(w)
 }
+
+// ServiceVersion returns the value of the `service.version` process tag, if present.
+func (p *Process) ServiceVersion() (string, bool) {
+	return p.findTagAsString("service.version")
+}
+
+// SchemaURLTagKey is the process tag holding the OpenTelemetry schema URL of the
+// semantic conventions used by the process tags and span tags.
+const SchemaURLTagKey = "otel.schema_url"
+
+// SchemaURL returns the value of the `otel.schema_url` process tag, if present.
+func (p *Process) SchemaURL() (string, bool) {
+	return p.findTagAsString(SchemaURLTagKey)
+}
+
+// Hostname returns the value of the `hostname` process tag, or of the `host.name` tag
+// used by OpenTelemetry, if present.
+func (p *Process) Hostname() (string, bool) {
+	return p.findTagAsString("hostname", "host.name")
+}
+
+// IP returns the value of the `ip` process tag, if present. In addition to strings,
+// it accepts IPv4 addresses packed into int64 (as reported by Jaeger clients) and
+// binary IPv4/IPv6 addresses.
+func (p *Process) IP() (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	tag, ok := KeyValues(p.Tags).FindByKey("ip")
+	if !ok {
+		return "", false
+	}
+	switch tag.VType {
+	case Int64Type:
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, uint32(tag.Int64()))
+		return ip.String(), true
+	case BinaryType:
+		if l := len(tag.Binary()); l == net.IPv4len || l == net.IPv6len {
+			return net.IP(tag.Binary()).String(), true
+		}
+	}
+	return tag.AsString(), true
+}
+
+// findTagAsString returns the string representation of the first tag found
+// among the given keys, checked in order. It is safe to call on nil Process.
+func (p *Process) findTagAsString(keys ...string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	for _, key := range keys {
+		if tag, ok := KeyValues(p.Tags).FindByKey(key); ok {
+			return tag.AsString(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// ProcessInterner deduplicates equal Process objects, so that spans of the same service
+// instance share a single Process in memory. The hash of each interned Process is cached
+// by pointer, so interning spans that already share a Process is cheap. Duplicates are
+// not referenced once Intern returns.
+// Processes must not be modified after they have been interned.
+// ProcessInterner is not safe for concurrent use.
+type ProcessInterner struct {
+	processes map[uint64][]*Process
+	hashes    map[*Process]uint64
+}
+
+// NewProcessInterner creates an empty ProcessInterner.
+func NewProcessInterner() *ProcessInterner {
+	return &ProcessInterner{
+		processes: make(map[uint64][]*Process),
+		hashes:    make(map[*Process]uint64),
+	}
+}
+
+// Intern returns the first interned Process equal to process, interning process
+// itself if there is none. Processes that cannot be hashed, e.g. because of tags
+// of unknown type, are returned as is.
+func (pi *ProcessInterner) Intern(process *Process) *Process {
+	if process == nil {
+		return nil
+	}
+	if _, ok := pi.hashes[process]; ok {
+		return process
+	}
+	hash, err := HashCode(process)
+	if err != nil {
+		return process
+	}
+	for _, p := range pi.processes[hash] {
+		if p.Equal(process) {
+			return p
+		}
+	}
+	pi.processes[hash] = append(pi.processes[hash], process)
+	pi.hashes[process] = hash
+	return process
+}
+
+// InternTrace replaces the Process of each span of the trace with its interned Process.
+func (pi *ProcessInterner) InternTrace(trace *Trace) {
+	for _, span := range trace.Spans {
+		span.Process = pi.Intern(span.Process)
+	}
+}
+
+// Len returns the number of distinct interned processes.
+func (pi *ProcessInterner) Len() int {
+	count := 0
+	for _, processes := range pi.processes {
+		count += len(processes)
+	}
+	return count
+}
@@ -102,3 +102,50 @@ func TestProcessHashError(t *testing.T) {
 	}
 	assert.Equal(t, someErr, p1.Hash(w))
 }
+
+func TestProcessTagAccessors(t *testing.T) {
+	p := model.NewProcess("svc", []model.KeyValue{
+		model.String("service.version", "1.2.3"),
+		model.String("host.name", "host-b"),
+		model.Int64("ip", 0x08080404),
+	})
+	version, ok := p.ServiceVersion()
+	assert.True(t, ok)
+	assert.Equal(t, "1.2.3", version)
+	hostname, ok := p.Hostname()
+	assert.True(t, ok)
+	assert.Equal(t, "host-b", hostname)
+	ip, ok := p.IP()
+	assert.True(t, ok)
+	assert.Equal(t, "8.8.4.4", ip)
+
+	p = model.NewProcess("svc", []model.KeyValue{
+		model.String("hostname", "host-a"),
+		model.String("host.name", "host-b"),
+		model.String("ip", "10.0.0.1"),
+	})
+	hostname, _ = p.Hostname()
+	assert.Equal(t, "host-a", hostname, "hostname takes precedence over host.name")
+	ip, _ = p.IP()
+	assert.Equal(t, "10.0.0.1", ip)
+
+	p = model.NewProcess("svc", []model.KeyValue{model.String("otel.schema_url", "https://opentelemetry.io/schemas/1.9.0")})
+	schemaURL, ok := p.SchemaURL()
+	assert.True(t, ok)
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.9.0", schemaURL)
+
+	p = model.NewProcess("svc", []model.KeyValue{model.Binary("ip", []byte{127, 0, 0, 1})})
+	ip, _ = p.IP()
+	assert.Equal(t, "127.0.0.1", ip)
+
+	for _, p := range []*model.Process{nil, model.NewProcess("svc", nil)} {
+		_, ok = p.ServiceVersion()
+		assert.False(t, ok)
+		_, ok = p.Hostname()
+		assert.False(t, ok)
+		_, ok = p.IP()
+		assert.False(t, ok)
+		_, ok = p.SchemaURL()
+		assert.False(t, ok)
+	}
+}
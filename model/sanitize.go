@@ -0,0 +1,86 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// SanitizeOptions control the normalization performed by Span.Sanitize and NormalizeSpans.
+type SanitizeOptions struct {
+	// BoolTags lists the tag keys normalized with Span.NormalizeBoolTags.
+	BoolTags []string
+	// MaxTagValueLength, if positive, truncates string tag values longer than this
+	// number of bytes, without splitting multi-byte UTF-8 characters.
+	MaxTagValueLength int
+	// Deduplicate enables merging of spans with the same trace and span IDs in NormalizeSpans.
+	Deduplicate bool
+}
+
+// Sanitize normalizes the span in place: it converts all timestamps to UTC,
+// normalizes the configured Boolean tags, and truncates long string tag values.
+func (s *Span) Sanitize(opts SanitizeOptions) {
+	s.NormalizeTimestamps()
+	if len(opts.BoolTags) > 0 {
+		s.NormalizeBoolTags(opts.BoolTags...)
+	}
+	if opts.MaxTagValueLength > 0 {
+		for i := range s.Tags {
+			tag := &s.Tags[i]
+			if tag.VType == StringType && len(tag.VStr) > opts.MaxTagValueLength {
+				tag.VStr = truncateString(tag.VStr, opts.MaxTagValueLength)
+				s.Warnings = append(s.Warnings, fmt.Sprintf("tag %s truncated to %d bytes", tag.Key, opts.MaxTagValueLength))
+			}
+		}
+	}
+}
+
+// truncateString truncates s to at most maxLength bytes on a rune boundary.
+func truncateString(s string, maxLength int) string {
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
+// NormalizeSpans sanitizes each span in the batch and, if opts.Deduplicate is set,
+// merges spans with the same trace and span IDs into the first occurrence (see Span.Merge).
+// It returns the normalized batch, which reuses the underlying array of spans.
+func NormalizeSpans(spans []*Span, opts SanitizeOptions) []*Span {
+	for _, span := range spans {
+		span.Sanitize(opts)
+	}
+	if !opts.Deduplicate {
+		return spans
+	}
+	type spanKey struct {
+		traceID TraceID
+		spanID  SpanID
+	}
+	seen := make(map[spanKey]*Span, len(spans))
+	deduped := spans[:0]
+	for _, span := range spans {
+		key := spanKey{traceID: span.TraceID, spanID: span.SpanID}
+		if first, ok := seen[key]; ok {
+			first.Merge(span)
+			continue
+		}
+		seen[key] = span
+		deduped = append(deduped, span)
+	}
+	return deduped
+}
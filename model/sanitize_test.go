@@ -0,0 +1,63 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanSanitize(t *testing.T) {
+	span := &model.Span{
+		StartTime: time.Unix(100, 0).In(time.FixedZone("X", 3600)),
+		Tags: model.KeyValues{
+			model.String("error", "yes"),
+			model.String("long", "abcdef"),
+			model.String("short", "abc"),
+			model.String("utf8", "abé"),
+		},
+	}
+	span.Sanitize(model.SanitizeOptions{BoolTags: []string{"error"}, MaxTagValueLength: 3})
+	assert.Equal(t, time.UTC, span.StartTime.Location())
+	assert.Equal(t, model.KeyValues{
+		model.Bool("error", true),
+		model.String("long", "abc"),
+		model.String("short", "abc"),
+		model.String("utf8", "ab"),
+	}, model.KeyValues(span.Tags))
+	assert.Equal(t, []string{"tag long truncated to 3 bytes", "tag utf8 truncated to 3 bytes"}, span.Warnings)
+}
+
+func TestNormalizeSpans(t *testing.T) {
+	makeSpans := func() []*model.Span {
+		return []*model.Span{
+			{TraceID: model.TraceID{Low: 1}, SpanID: 1, Tags: model.KeyValues{model.String("a", "1")}},
+			{TraceID: model.TraceID{Low: 1}, SpanID: 2},
+			{TraceID: model.TraceID{Low: 2}, SpanID: 1},
+			{TraceID: model.TraceID{Low: 1}, SpanID: 1, Tags: model.KeyValues{model.String("b", "2")}},
+		}
+	}
+	spans := model.NormalizeSpans(makeSpans(), model.SanitizeOptions{})
+	assert.Len(t, spans, 4)
+
+	spans = model.NormalizeSpans(makeSpans(), model.SanitizeOptions{Deduplicate: true})
+	assert.Len(t, spans, 3)
+	assert.Equal(t, []model.SpanID{1, 2, 1}, spanIDs(spans))
+	assert.Equal(t, model.KeyValues{model.String("a", "1"), model.String("b", "2")}, model.KeyValues(spans[0].Tags))
+}
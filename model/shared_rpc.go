@@ -0,0 +1,124 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// Zipkin core annotations, as found in the `event` field of span logs.
+const (
+	clientSendEvent = "cs"
+	clientRecvEvent = "cr"
+	serverSendEvent = "ss"
+	serverRecvEvent = "sr"
+)
+
+// SplitSharedRPC splits a Zipkin-style shared span, which records both the client and
+// the server side of an RPC, into separate client and server spans, as used by Jaeger.
+// The shared span is recognized by logs with `event` fields holding the Zipkin core
+// annotations `cs` and `sr` (and optionally `cr` and `ss`); if either of them is missing,
+// ok is false.
+//
+// The client span keeps the span ID, references, tags, and remaining logs of the
+// original span. The server span receives a span ID derived from the trace ID and span
+// ID of the original span, so that splitting the same span again gives the same result,
+// and a child-of reference to the client span. The original span is not modified.
+func (s *Span) SplitSharedRPC() (client, server *Span, ok bool) {
+	events := make(map[string]time.Time)
+	var logs []Log
+	for _, log := range s.Logs {
+		if event, found := KeyValues(log.Fields).FindByKey("event"); found && isCoreAnnotation(event.AsString()) {
+			events[event.AsString()] = log.Timestamp
+		} else {
+			logs = append(logs, log)
+		}
+	}
+	cs, hasCS := events[clientSendEvent]
+	sr, hasSR := events[serverRecvEvent]
+	if !hasCS || !hasSR {
+		return nil, nil, false
+	}
+
+	client = &Span{
+		TraceID:       s.TraceID,
+		SpanID:        s.SpanID,
+		OperationName: s.OperationName,
+		References:    append([]SpanRef(nil), s.References...),
+		Flags:         s.Flags,
+		StartTime:     cs,
+		Duration:      s.Duration,
+		Tags:          withSpanKind(s.Tags, ext.SpanKindRPCClientEnum),
+		Logs:          logs,
+		Process:       s.Process,
+		Warnings:      append([]string(nil), s.Warnings...),
+	}
+	if cr, ok := events[clientRecvEvent]; ok {
+		client.Duration = cr.Sub(cs)
+	}
+	server = &Span{
+		TraceID:       s.TraceID,
+		SpanID:        derivedSpanID(s.TraceID, s.SpanID, string(ext.SpanKindRPCServerEnum)),
+		OperationName: s.OperationName,
+		References:    []SpanRef{NewChildOfRef(s.TraceID, s.SpanID)},
+		Flags:         s.Flags,
+		StartTime:     sr,
+		Tags:          withSpanKind(nil, ext.SpanKindRPCServerEnum),
+		Process:       s.Process,
+	}
+	if ss, ok := events[serverSendEvent]; ok {
+		server.Duration = ss.Sub(sr)
+	}
+	return client, server, true
+}
+
+// derivedSpanID returns a non-zero span ID derived from the trace ID, span ID and kind,
+// which differs from spanID.
+func derivedSpanID(traceID TraceID, spanID SpanID, kind string) SpanID {
+	h := fnv.New64a()
+	w := &hashWriter{w: h}
+	w.uint64(traceID.High)
+	w.uint64(traceID.Low)
+	w.uint64(uint64(spanID))
+	w.string(kind)
+	for {
+		if id := SpanID(h.Sum64()); id != 0 && id != spanID {
+			return id
+		}
+		w.string(kind)
+	}
+}
+
+func isCoreAnnotation(event string) bool {
+	switch event {
+	case clientSendEvent, clientRecvEvent, serverSendEvent, serverRecvEvent:
+		return true
+	}
+	return false
+}
+
+// withSpanKind returns a copy of tags with the `span.kind` tag set to kind.
+func withSpanKind(tags []KeyValue, kind ext.SpanKindEnum) []KeyValue {
+	result := make([]KeyValue, 0, len(tags)+1)
+	for _, tag := range tags {
+		if tag.Key != string(ext.SpanKind) {
+			result = append(result, tag)
+		}
+	}
+	return append(result, String(string(ext.SpanKind), string(kind)))
+}
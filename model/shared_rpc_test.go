@@ -0,0 +1,84 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanSplitSharedRPC(t *testing.T) {
+	base := time.Unix(100, 0)
+	event := func(offset time.Duration, value string) model.Log {
+		return model.Log{Timestamp: base.Add(offset), Fields: model.KeyValues{model.String("event", value)}}
+	}
+	traceID := model.TraceID{Low: 1}
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        2,
+		OperationName: "get",
+		References:    []model.SpanRef{model.NewChildOfRef(traceID, 1)},
+		StartTime:     base,
+		Duration:      10 * time.Millisecond,
+		Tags:          model.KeyValues{model.String("span.kind", "server"), model.String("http.method", "GET")},
+		Logs: []model.Log{
+			event(0, "cs"),
+			event(2*time.Millisecond, "sr"),
+			event(3*time.Millisecond, "cache miss"),
+			event(8*time.Millisecond, "ss"),
+			event(10*time.Millisecond, "cr"),
+		},
+		Process: model.NewProcess("svc", nil),
+	}
+
+	client, server, ok := span.SplitSharedRPC()
+	assert.True(t, ok)
+	assert.True(t, client.IsRPCClient())
+	assert.Equal(t, model.SpanID(2), client.SpanID)
+	assert.Equal(t, model.SpanID(1), client.ParentSpanID())
+	assert.Equal(t, base, client.StartTime)
+	assert.Equal(t, 10*time.Millisecond, client.Duration)
+	assert.Equal(t, []model.Log{event(3*time.Millisecond, "cache miss")}, client.Logs)
+	assert.Equal(t, model.KeyValues{model.String("http.method", "GET"), model.String("span.kind", "client")}, model.KeyValues(client.Tags))
+
+	assert.True(t, server.IsRPCServer())
+	assert.NotEqual(t, client.SpanID, server.SpanID)
+	assert.Equal(t, client.SpanID, server.ParentSpanID())
+	assert.Equal(t, base.Add(2*time.Millisecond), server.StartTime)
+	assert.Equal(t, 6*time.Millisecond, server.Duration)
+	assert.Equal(t, span.Process, server.Process)
+
+	client2, server2, ok := span.SplitSharedRPC()
+	assert.True(t, ok)
+	assert.Equal(t, client, client2, "splitting is deterministic")
+	assert.Equal(t, server, server2, "splitting is deterministic")
+
+	assert.Len(t, span.Logs, 5, "original span must not be modified")
+	assert.Equal(t, "server", span.Tags[0].VStr)
+}
+
+func TestSpanSplitSharedRPCNotShared(t *testing.T) {
+	span := &model.Span{
+		Logs: []model.Log{{Fields: model.KeyValues{model.String("event", "cs")}}},
+	}
+	_, _, ok := span.SplitSharedRPC()
+	assert.False(t, ok)
+	_, _, ok = (&model.Span{}).SplitSharedRPC()
+	assert.False(t, ok)
+}
@@ -15,6 +15,7 @@
 package model
 
 import (
+	"container/heap"
 	"sort"
 )
 
@@ -62,6 +63,103 @@ func SortSpan(span *Span) {
 	sortProcess(span.Process)
 }
 
+type spanByStartTime []*Span
+
+func (s spanByStartTime) Len() int      { return len(s) }
+func (s spanByStartTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s spanByStartTime) Less(i, j int) bool {
+	if !s[i].StartTime.Equal(s[j].StartTime) {
+		return s[i].StartTime.Before(s[j].StartTime)
+	}
+	return s[i].SpanID < s[j].SpanID
+}
+
+// sortSpansByStartTime sorts spans by start time, using span ID as a tiebreaker.
+func sortSpansByStartTime(spans []*Span) {
+	sort.Sort(spanByStartTime(spans))
+}
+
+// SortSpansByStartTime sorts the spans of the trace by start time, using span ID
+// as a tiebreaker.
+func (t *Trace) SortSpansByStartTime() {
+	sortSpansByStartTime(t.Spans)
+}
+
+// SortSpansHierarchically sorts the spans of the trace in depth-first order of the span
+// tree (see ChildIndex), so that each span comes before its children and after its parent
+// and its parent's earlier children. Root spans and siblings keep their relative order,
+// so calling SortSpansByStartTime first orders them by start time, as in the timeline
+// view of the UI. Spans that are not reachable from a root span, i.e. that form
+// reference cycles, are placed at the end in their original order.
+func (t *Trace) SortSpansHierarchically() {
+	spansByID := t.spansByID()
+	children := t.ChildIndex()
+	sorted := make([]*Span, 0, len(t.Spans))
+	visited := make(map[*Span]struct{}, len(t.Spans))
+	var visit func(span *Span)
+	visit = func(span *Span) {
+		if _, ok := visited[span]; ok {
+			return
+		}
+		visited[span] = struct{}{}
+		sorted = append(sorted, span)
+		for _, child := range children[span.SpanID] {
+			visit(child)
+		}
+	}
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.ParentSpanID()]; !ok {
+			visit(span)
+		}
+	}
+	for _, span := range t.Spans {
+		if _, ok := visited[span]; !ok {
+			sorted = append(sorted, span)
+		}
+	}
+	copy(t.Spans, sorted)
+}
+
+type spanByTraceID []*Span
+
+func (s spanByTraceID) Len() int      { return len(s) }
+func (s spanByTraceID) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s spanByTraceID) Less(i, j int) bool {
+	if c := s[i].TraceID.Compare(s[j].TraceID); c != 0 {
+		return c < 0
+	}
+	return spanByStartTime(s).Less(i, j)
+}
+
+// SortSpansByTrace sorts spans by TraceID, then by start time, then by SpanID,
+// so that the spans of each trace are adjacent and in a deterministic order.
+func SortSpansByTrace(spans []*Span) {
+	sort.Sort(spanByTraceID(spans))
+}
+
+type refByTypeAndID []SpanRef
+
+func (r refByTypeAndID) Len() int      { return len(r) }
+func (r refByTypeAndID) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r refByTypeAndID) Less(i, j int) bool {
+	if r[i].RefType != r[j].RefType {
+		return r[i].RefType < r[j].RefType
+	}
+	if r[i].TraceID.High != r[j].TraceID.High {
+		return r[i].TraceID.High < r[j].TraceID.High
+	}
+	if r[i].TraceID.Low != r[j].TraceID.Low {
+		return r[i].TraceID.Low < r[j].TraceID.Low
+	}
+	return r[i].SpanID < r[j].SpanID
+}
+
+// SortReferences sorts span references by type, then by trace ID, then by span ID,
+// to produce a canonical order regardless of the order in which clients emit them.
+func (s *Span) SortReferences() {
+	sort.Sort(refByTypeAndID(s.References))
+}
+
 type tagByKey []KeyValue
 
 func (t tagByKey) Len() int           { return len(t) }
@@ -90,3 +188,65 @@ func sortProcess(process *Process) {
 		sortTags(process.Tags)
 	}
 }
+
+// SortCausally sorts the spans of the trace in a causal order, i.e. a topological order
+// of the span tree (see ChildIndex) in which each span comes after its parent even when
+// timestamps are affected by clock skew. Of all spans whose parent has already been
+// placed, the one with the earliest start time comes next, using span ID as a tiebreaker.
+// Spans that form reference cycles are placed after the other spans, starting from
+// the earliest span of each cycle.
+func (t *Trace) SortCausally() {
+	spansByID := t.spansByID()
+	children := t.ChildIndex()
+	sorted := make([]*Span, 0, len(t.Spans))
+	visited := make(map[*Span]struct{}, len(t.Spans))
+	ready := &spansByStartTimeHeap{}
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.ParentSpanID()]; !ok {
+			heap.Push(ready, span)
+		}
+	}
+	// byStartTime is used to find the earliest span that is not reachable from a root
+	byStartTime := append(spanByStartTime(nil), t.Spans...)
+	sort.Sort(byStartTime)
+	next := 0
+	for {
+		for ready.Len() > 0 {
+			span := heap.Pop(ready).(*Span)
+			if _, ok := visited[span]; ok {
+				continue
+			}
+			visited[span] = struct{}{}
+			sorted = append(sorted, span)
+			for _, child := range children[span.SpanID] {
+				if _, ok := visited[child]; !ok {
+					heap.Push(ready, child)
+				}
+			}
+		}
+		for next < len(byStartTime) {
+			if _, ok := visited[byStartTime[next]]; !ok {
+				break
+			}
+			next++
+		}
+		if next == len(byStartTime) {
+			break
+		}
+		heap.Push(ready, byStartTime[next])
+	}
+	copy(t.Spans, sorted)
+}
+
+type spansByStartTimeHeap []*Span
+
+func (h spansByStartTimeHeap) Len() int            { return len(h) }
+func (h spansByStartTimeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h spansByStartTimeHeap) Less(i, j int) bool  { return spanByStartTime(h).Less(i, j) }
+func (h *spansByStartTimeHeap) Push(x interface{}) { *h = append(*h, x.(*Span)) }
+func (h *spansByStartTimeHeap) Pop() interface{} {
+	old := *h
+	span := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return span
+}
@@ -118,3 +118,120 @@ func TestSortListOfTraces(t *testing.T) {
 	SortTraces(list2)
 	assert.EqualValues(t, list1, list2)
 }
+
+func TestSortReferences(t *testing.T) {
+	t1 := TraceID{Low: 1}
+	t2 := TraceID{High: 1, Low: 0}
+	t3 := TraceID{Low: 2}
+	span := &Span{
+		References: []SpanRef{
+			NewFollowsFromRef(t1, 1),
+			NewChildOfRef(t2, 1),
+			NewChildOfRef(t1, 2),
+			NewChildOfRef(t3, 1),
+			NewChildOfRef(t1, 1),
+		},
+	}
+	span.SortReferences()
+	assert.Equal(t, []SpanRef{
+		NewChildOfRef(t1, 1),
+		NewChildOfRef(t1, 2),
+		NewChildOfRef(t3, 1),
+		NewChildOfRef(t2, 1),
+		NewFollowsFromRef(t1, 1),
+	}, span.References)
+}
+
+func TestSortSpansByTrace(t *testing.T) {
+	spans := []*Span{
+		{TraceID: TraceID{High: 1, Low: 1}, SpanID: 1, StartTime: currTime},
+		{TraceID: TraceID{Low: 2}, SpanID: 2, StartTime: currTime},
+		{TraceID: TraceID{Low: 2}, SpanID: 1, StartTime: currTime},
+		{TraceID: TraceID{Low: 3}, SpanID: 9, StartTime: currTime.Add(-time.Second)},
+		{TraceID: TraceID{Low: 2}, SpanID: 3, StartTime: currTime.Add(-time.Second)},
+	}
+	SortSpansByTrace(spans)
+	var keys [][2]uint64
+	for _, span := range spans {
+		keys = append(keys, [2]uint64{span.TraceID.Low, uint64(span.SpanID)})
+	}
+	assert.Equal(t, [][2]uint64{{2, 3}, {2, 1}, {2, 2}, {3, 9}, {1, 1}}, keys)
+	assert.Equal(t, uint64(1), spans[4].TraceID.High)
+}
+
+func TestTraceSortSpans(t *testing.T) {
+	traceID := TraceID{Low: 1}
+	span := func(id, parent SpanID, start time.Duration) *Span {
+		return &Span{
+			TraceID:    traceID,
+			SpanID:     id,
+			References: MaybeAddParentSpanID(traceID, parent, nil),
+			StartTime:  currTime.Add(start),
+		}
+	}
+	ids := func(trace *Trace) []SpanID {
+		var ids []SpanID
+		for _, span := range trace.Spans {
+			ids = append(ids, span.SpanID)
+		}
+		return ids
+	}
+	makeTrace := func() *Trace {
+		// 1 -> {3, 2}, 3 -> 4, 5 is an orphan, 6 <-> 7 form a cycle
+		return &Trace{Spans: []*Span{
+			span(4, 3, 4),
+			span(7, 6, 0),
+			span(2, 1, 2),
+			span(6, 7, 0),
+			span(5, 9, 1),
+			span(3, 1, 3),
+			span(1, 0, 0),
+		}}
+	}
+
+	trace := makeTrace()
+	trace.SortSpansByStartTime()
+	assert.Equal(t, []SpanID{1, 6, 7, 5, 2, 3, 4}, ids(trace))
+
+	trace = makeTrace()
+	trace.SortSpansHierarchically()
+	assert.Equal(t, []SpanID{5, 1, 2, 3, 4, 7, 6}, ids(trace), "siblings and roots keep their order")
+
+	trace = makeTrace()
+	trace.SortSpansByStartTime()
+	trace.SortSpansHierarchically()
+	assert.Equal(t, []SpanID{1, 2, 3, 4, 5, 6, 7}, ids(trace))
+
+	trace = makeTrace()
+	trace.SortCausally()
+	assert.Equal(t, []SpanID{1, 5, 2, 3, 4, 6, 7}, ids(trace))
+}
+
+func TestTraceSortCausally(t *testing.T) {
+	traceID := TraceID{Low: 1}
+	span := func(id, parent SpanID, start time.Duration) *Span {
+		return &Span{
+			TraceID:    traceID,
+			SpanID:     id,
+			References: MaybeAddParentSpanID(traceID, parent, nil),
+			StartTime:  currTime.Add(start),
+		}
+	}
+	// the parent starts after its child and after an unrelated span due to clock skew
+	parent := span(1, 0, 10)
+	child := span(2, 1, 0)
+	unrelated := span(3, 0, 5)
+	for _, spans := range [][]*Span{
+		{parent, child, unrelated},
+		{child, unrelated, parent},
+		{unrelated, parent, child},
+	} {
+		trace := &Trace{Spans: append([]*Span(nil), spans...)}
+		trace.SortCausally()
+		assert.Equal(t, []*Span{unrelated, parent, child}, trace.Spans)
+	}
+
+	empty := &Trace{}
+	empty.SortCausally()
+	assert.Empty(t, empty.Spans)
+}
@@ -15,20 +15,31 @@
 package model
 
 import (
-	"encoding/gob"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/opentracing/opentracing-go/ext"
 )
 
 const (
-	// sampledFlag is the bit set in Flags in order to define a span as a sampled span
-	sampledFlag = Flags(1)
-	// debugFlag is the bit set in Flags in order to define a span as a debug span
-	debugFlag = Flags(2)
+	// SampledFlag is the bit set in Flags in order to define a span as a sampled span
+	SampledFlag = Flags(1)
+	// DebugFlag is the bit set in Flags in order to define a span as a debug span
+	DebugFlag = Flags(2)
+	// FirehoseFlag is the bit set in Flags in order to define a span as a firehose span,
+	// which storage backends may store without indexing
+	FirehoseFlag = Flags(8)
+
+	// RedactedValue is the value that replaces redacted tags and log fields.
+	RedactedValue = "<redacted>"
 )
 
 // TraceID is a random 128bit identifier for a trace
@@ -58,12 +69,80 @@ type Span struct {
 	Warnings      []string      `json:"warnings,omitempty"`
 }
 
-// Hash implements Hash from Hashable.
-func (s *Span) Hash(w io.Writer) (err error) {
-	// gob is not the most efficient way, but it ensures we don't miss any fields.
-	// See BenchmarkSpanHash in span_test.go
-	enc := gob.NewEncoder(w)
-	return enc.Encode(s)
+// Hash implements Hash from Hashable. It writes all fields of the span in a fixed
+// order, with timestamps as nanoseconds since the Unix epoch, so spans that are
+// Equal have the same hash. See BenchmarkSpanHash in span_test.go.
+func (s *Span) Hash(w io.Writer) error {
+	h := &hashWriter{w: w}
+	h.uint64(s.TraceID.High)
+	h.uint64(s.TraceID.Low)
+	h.uint64(uint64(s.SpanID))
+	h.string(s.OperationName)
+	s.hashReferences(h)
+	h.uint64(uint64(s.Flags))
+	h.time(s.StartTime)
+	h.uint64(uint64(s.Duration))
+	h.keyValues(s.Tags)
+	h.uint64(uint64(len(s.Logs)))
+	for i := range s.Logs {
+		h.time(s.Logs[i].Timestamp)
+		h.keyValues(s.Logs[i].Fields)
+	}
+	if s.Process == nil {
+		h.uint64(0)
+	} else {
+		h.uint64(1)
+		h.string(s.Process.ServiceName)
+		h.keyValues(s.Process.Tags)
+	}
+	h.uint64(uint64(len(s.Warnings)))
+	for _, warning := range s.Warnings {
+		h.string(warning)
+	}
+	return h.err
+}
+
+func (s *Span) hashReferences(h *hashWriter) {
+	h.uint64(uint64(len(s.References)))
+	for _, ref := range s.References {
+		h.uint64(uint64(ref.RefType))
+		h.uint64(ref.TraceID.High)
+		h.uint64(ref.TraceID.Low)
+		h.uint64(uint64(ref.SpanID))
+		h.keyValues(ref.Tags)
+	}
+}
+
+// HashIgnoringTime returns a FNV-1a hash of the span IDs, operation name, references,
+// tags, and log fields, excluding the start time, duration and log timestamps. Spans
+// that differ only in their timestamps, e.g. retransmissions re-stamped by the reporter,
+// have the same hash. Process, flags and warnings are not included either.
+func (s *Span) HashIgnoringTime() uint64 {
+	hash := fnv.New64a()
+	// fnv hash never returns errors, so the only possible error is an unknown value
+	// type, after which the rest of the span is left out of the hash
+	h := &hashWriter{w: hash}
+	h.uint64(s.TraceID.High)
+	h.uint64(s.TraceID.Low)
+	h.uint64(uint64(s.SpanID))
+	h.string(s.OperationName)
+	s.hashReferences(h)
+	h.keyValues(s.Tags)
+	h.uint64(uint64(len(s.Logs)))
+	for i := range s.Logs {
+		h.keyValues(s.Logs[i].Fields)
+	}
+	return hash.Sum64()
+}
+
+// CacheKey returns the trace ID and span ID of the span packed into a fixed-size array,
+// which can be used as a map key without allocating a string.
+func (s *Span) CacheKey() [24]byte {
+	var key [24]byte
+	binary.BigEndian.PutUint64(key[0:8], s.TraceID.High)
+	binary.BigEndian.PutUint64(key[8:16], s.TraceID.Low)
+	binary.BigEndian.PutUint64(key[16:24], uint64(s.SpanID))
+	return key
 }
 
 // HasSpanKind returns true if the span has a `span.kind` tag set to `kind`.
@@ -86,6 +165,214 @@ func (s *Span) IsRPCServer() bool {
 	return s.HasSpanKind(ext.SpanKindRPCServerEnum)
 }
 
+// peerServiceKeys are the tags identifying the remote service of a span, in order of precedence.
+var peerServiceKeys = []string{string(ext.PeerService), string(ext.PeerHostname), "net.peer.name"}
+
+// PeerService returns the name of the remote service the span communicates with,
+// read from the `peer.service` tag, with fallback to `peer.hostname` and `net.peer.name`.
+func (s *Span) PeerService() (string, bool) {
+	peerService := s.firstTagAsString(peerServiceKeys...)
+	return peerService, peerService != ""
+}
+
+// messagingDestinationKeys and messagingOperationKeys are the tags holding the messaging
+// destination and operation, in order of precedence: newer OpenTelemetry semantic
+// conventions first, then the keys used by earlier versions.
+var (
+	messagingDestinationKeys = []string{"messaging.destination.name", "messaging.destination"}
+	messagingOperationKeys   = []string{"messaging.operation.type", "messaging.operation"}
+)
+
+// MessagingInfo returns the messaging system (e.g. kafka), destination (topic or queue)
+// and operation (e.g. publish) of a messaging span, read from the `messaging.*` tags.
+// Both current and earlier OpenTelemetry key names are accepted. Returns false if the
+// span has no `messaging.system` tag, i.e. it is not a messaging span.
+func (s *Span) MessagingInfo() (system, destination, operation string, ok bool) {
+	system = s.firstTagAsString("messaging.system")
+	if system == "" {
+		return "", "", "", false
+	}
+	return system, s.firstTagAsString(messagingDestinationKeys...), s.firstTagAsString(messagingOperationKeys...), true
+}
+
+// firstTagAsString returns the string representation of the first non-empty span tag
+// found among the given keys, checked in order, or an empty string.
+func (s *Span) firstTagAsString(keys ...string) string {
+	for _, key := range keys {
+		if tag, ok := KeyValues(s.Tags).FindByKey(key); ok && tag.AsString() != "" {
+			return tag.AsString()
+		}
+	}
+	return ""
+}
+
+// UnknownOperationName is returned by Span.InferOperationName when no name can be derived.
+const UnknownOperationName = "unknown"
+
+// InferOperationName returns the operation name of the span, or if it is empty,
+// a name derived from the span tags with OperationNameFromTags, or UnknownOperationName.
+func (s *Span) InferOperationName() string {
+	if s.OperationName != "" {
+		return s.OperationName
+	}
+	if name := OperationNameFromTags(s.Tags); name != "" {
+		return name
+	}
+	return UnknownOperationName
+}
+
+// OperationNameFromTags derives an operation name from semantic convention tags.
+// The first applicable rule wins:
+//   - `http.method` and `http.route`, e.g. "GET /users/{id}"
+//   - `db.system` and `db.operation`, e.g. "postgresql SELECT"
+//   - `rpc.method`
+//
+// It returns an empty string if none of the rules apply.
+func OperationNameFromTags(tags []KeyValue) string {
+	kvs := KeyValues(tags)
+	find := func(key string) string {
+		if tag, ok := kvs.FindByKey(key); ok {
+			return tag.AsString()
+		}
+		return ""
+	}
+	if method, route := find("http.method"), find("http.route"); method != "" && route != "" {
+		return method + " " + route
+	}
+	if system, operation := find("db.system"), find("db.operation"); system != "" && operation != "" {
+		return system + " " + operation
+	}
+	return find("rpc.method")
+}
+
+// SamplingPriority returns the value of the `sampling.priority` tag. Besides int64,
+// it accepts integral float64 values and strings holding decimal integers.
+func (s *Span) SamplingPriority() (int64, bool) {
+	tag, ok := KeyValues(s.Tags).FindByKey(string(ext.SamplingPriority))
+	if !ok {
+		return 0, false
+	}
+	switch tag.VType {
+	case Int64Type:
+		return tag.Int64(), true
+	case Float64Type:
+		if f := tag.Float64(); f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			return int64(f), true
+		}
+	case StringType:
+		if p, err := strconv.ParseInt(strings.TrimSpace(tag.VStr), 10, 64); err == nil {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// SetSamplingPriority sets the `sampling.priority` tag to the given value,
+// replacing any existing tags with that key.
+func (s *Span) SetSamplingPriority(p int64) {
+	s.setTag(Int64(string(ext.SamplingPriority), p))
+}
+
+// IsError returns true if the span has the `error` tag set to true. Besides Bool
+// tags, boolean-like values such as "true" or int64 1 are accepted.
+func (s *Span) IsError() bool {
+	tag, ok := KeyValues(s.Tags).FindByKey(string(ext.Error))
+	if !ok {
+		return false
+	}
+	value, _ := tag.AsBoolSafe()
+	return value
+}
+
+// AddDerivedTags adds tags computed from other span fields, so that they can be
+// queried like regular tags: `duration.ms` (Float64), `service.name` (String, if
+// the span has a process) and `is.error` (Bool, see IsError). Existing `duration.ms`
+// and `is.error` tags are replaced, so repeated calls do not add duplicates. An
+// existing `service.name` tag is kept, so that ServiceConsistent can still report
+// a mismatch with the process.
+func (s *Span) AddDerivedTags() {
+	s.setTag(Float64("duration.ms", float64(s.Duration)/float64(time.Millisecond)))
+	if serviceName := s.serviceName(); serviceName != "" {
+		if _, ok := KeyValues(s.Tags).FindByKey("service.name"); !ok {
+			s.Tags = append(s.Tags, String("service.name", serviceName))
+		}
+	}
+	s.setTag(Bool("is.error", s.IsError()))
+}
+
+// setTag replaces all span tags with the key of the given tag with that tag.
+func (s *Span) setTag(tag KeyValue) {
+	s.RemoveTag(tag.Key)
+	s.Tags = append(s.Tags, tag)
+}
+
+// EstimateSize returns an estimate of the size of the span in bytes when serialized,
+// counting the IDs, timestamps and flags as fixed-size fields and adding the sizes of
+// the operation name, references, tags, logs, process and warnings. It is meant for
+// enforcing size limits, e.g. of transport messages, not as an exact encoded size.
+func (s *Span) EstimateSize() int {
+	// trace ID, span ID, flags, start time, duration
+	size := 16 + 8 + 4 + 8 + 8 + len(s.OperationName)
+	// reference type, trace ID, span ID
+	size += len(s.References) * (4 + 16 + 8)
+	for i := range s.References {
+		size += keyValuesSize(s.References[i].Tags)
+	}
+	size += keyValuesSize(s.Tags)
+	for i := range s.Logs {
+		size += 8 + keyValuesSize(s.Logs[i].Fields)
+	}
+	if s.Process != nil {
+		size += len(s.Process.ServiceName) + keyValuesSize(s.Process.Tags)
+	}
+	for _, warning := range s.Warnings {
+		size += len(warning)
+	}
+	return size
+}
+
+// Size returns the approximate number of bytes of memory held by the span: the span
+// struct itself, the backing arrays of its slices (by capacity), strings and binary
+// values, and the process. Unlike EstimateSize, which approximates the serialized size,
+// it is meant for memory accounting, e.g. byte-based queue limits. A process shared by
+// several spans is counted in full for each of them.
+func (s *Span) Size() int {
+	size := int(unsafe.Sizeof(*s)) + len(s.OperationName)
+	size += cap(s.References) * int(unsafe.Sizeof(SpanRef{}))
+	for i := range s.References {
+		size += keyValuesMemorySize(s.References[i].Tags)
+	}
+	size += keyValuesMemorySize(s.Tags)
+	size += cap(s.Logs) * int(unsafe.Sizeof(Log{}))
+	for i := range s.Logs {
+		size += keyValuesMemorySize(s.Logs[i].Fields)
+	}
+	if s.Process != nil {
+		size += int(unsafe.Sizeof(*s.Process)) + len(s.Process.ServiceName) + keyValuesMemorySize(s.Process.Tags)
+	}
+	size += cap(s.Warnings) * int(unsafe.Sizeof(""))
+	for _, warning := range s.Warnings {
+		size += len(warning)
+	}
+	return size
+}
+
+func keyValuesMemorySize(kvs []KeyValue) int {
+	size := cap(kvs) * int(unsafe.Sizeof(KeyValue{}))
+	for i := range kvs {
+		size += len(kvs[i].Key) + len(kvs[i].VStr) + cap(kvs[i].VBlob)
+	}
+	return size
+}
+
+func keyValuesSize(kvs []KeyValue) int {
+	size := 0
+	for i := range kvs {
+		size += kvs[i].Size()
+	}
+	return size
+}
+
 // NormalizeTimestamps changes all timestamps in this span to UTC.
 func (s *Span) NormalizeTimestamps() {
 	s.StartTime = s.StartTime.UTC()
@@ -106,6 +393,22 @@ func (s *Span) ParentSpanID() SpanID {
 	return SpanID(0)
 }
 
+// ReferenceCounts returns the number of span references of each type, counting
+// references of types other than ChildOf and FollowsFrom as other.
+func (s *Span) ReferenceCounts() (childOf, followsFrom, other int) {
+	for i := range s.References {
+		switch s.References[i].RefType {
+		case ChildOf:
+			childOf++
+		case FollowsFrom:
+			followsFrom++
+		default:
+			other++
+		}
+	}
+	return childOf, followsFrom, other
+}
+
 // ReplaceParentID replaces span ID in the parent span reference.
 // See also ParentSpanID.
 func (s *Span) ReplaceParentID(newParentID SpanID) {
@@ -119,35 +422,341 @@ func (s *Span) ReplaceParentID(newParentID SpanID) {
 	s.References = MaybeAddParentSpanID(s.TraceID, newParentID, s.References)
 }
 
+// EnsureSingleParent makes sure the span has at most one parent: if it has more than
+// one ChildOf reference to a span in its own trace, the first one is kept as the parent
+// (see ParentSpanID) and the others are changed to FollowsFrom references.
+// Returns true and adds a warning to the span if any references were changed.
+func (s *Span) EnsureSingleParent() bool {
+	demoted := 0
+	hasParent := false
+	for i := range s.References {
+		ref := &s.References[i]
+		if ref.TraceID != s.TraceID || ref.RefType != ChildOf {
+			continue
+		}
+		if !hasParent {
+			hasParent = true
+			continue
+		}
+		ref.RefType = FollowsFrom
+		demoted++
+	}
+	if demoted == 0 {
+		return false
+	}
+	s.Warnings = append(s.Warnings, fmt.Sprintf("span has multiple parents, changed %d ChildOf references to FollowsFrom", demoted))
+	return true
+}
+
+// serviceName returns the service name of the span's process, or an empty string if the span has no process.
+func (s *Span) serviceName() string {
+	if s.Process == nil {
+		return ""
+	}
+	return s.Process.ServiceName
+}
+
+// OperationKey returns the key identifying the operation of the span across services,
+// in the form "service:operation". It is used for aggregations by operation.
+func (s *Span) OperationKey() string {
+	return s.serviceName() + ":" + s.OperationName
+}
+
+// ServiceConsistent returns false if the span has a `service.name` tag that differs
+// from the service name of its process. The process is authoritative: spans without
+// a process or without the tag are considered consistent.
+func (s *Span) ServiceConsistent() bool {
+	if s.Process == nil {
+		return true
+	}
+	for i := range s.Tags {
+		if s.Tags[i].Key == "service.name" && s.Tags[i].AsString() != s.Process.ServiceName {
+			return false
+		}
+	}
+	return true
+}
+
+// HasLogs returns true if the span has at least one log.
+func (s *Span) HasLogs() bool {
+	return len(s.Logs) > 0
+}
+
+// FirstLogTime returns the earliest log timestamp, or false if the span has no logs.
+// The logs do not need to be sorted.
+func (s *Span) FirstLogTime() (time.Time, bool) {
+	if !s.HasLogs() {
+		return time.Time{}, false
+	}
+	first := s.Logs[0].Timestamp
+	for i := range s.Logs {
+		if s.Logs[i].Timestamp.Before(first) {
+			first = s.Logs[i].Timestamp
+		}
+	}
+	return first, true
+}
+
+// LastLogTime returns the latest log timestamp, or false if the span has no logs.
+// The logs do not need to be sorted.
+func (s *Span) LastLogTime() (time.Time, bool) {
+	if !s.HasLogs() {
+		return time.Time{}, false
+	}
+	last := s.Logs[0].Timestamp
+	for i := range s.Logs {
+		if s.Logs[i].Timestamp.After(last) {
+			last = s.Logs[i].Timestamp
+		}
+	}
+	return last, true
+}
+
+// RecomputeDurationFromLogs sets the duration of a span that has no duration to the
+// time between its start and its last log, as an approximation for clients that do
+// not report durations. A warning is added to the span when the duration is changed.
+// Returns false if the duration was already set, or there are no logs after the start.
+func (s *Span) RecomputeDurationFromLogs() bool {
+	if s.Duration != 0 {
+		return false
+	}
+	last, ok := s.LastLogTime()
+	if !ok || !last.After(s.StartTime) {
+		return false
+	}
+	s.Duration = last.Sub(s.StartTime)
+	s.Warnings = append(s.Warnings, fmt.Sprintf("span duration is missing, recomputed as %v from logs", s.Duration))
+	return true
+}
+
+// PromoteLogField copies the latest occurrence of the given log field to a span tag
+// with the key tagKey, replacing existing tags with that key. The latest occurrence
+// is taken from the log with the latest timestamp, regardless of the order of logs.
+// Returns false if none of the logs has the field.
+func (s *Span) PromoteLogField(field, tagKey string) bool {
+	var latest *KeyValue
+	var latestTime time.Time
+	for i := range s.Logs {
+		log := &s.Logs[i]
+		if latest != nil && log.Timestamp.Before(latestTime) {
+			continue
+		}
+		for j := range log.Fields {
+			if log.Fields[j].Key == field {
+				latest = &log.Fields[j]
+				latestTime = log.Timestamp
+			}
+		}
+	}
+	if latest == nil {
+		return false
+	}
+	tag := *latest
+	tag.Key = tagKey
+	s.setTag(tag)
+	return true
+}
+
+// HasErrorLog returns true if any of the span logs has level error or fatal
+// (see Log.Level), or an `event=error` field.
+func (s *Span) HasErrorLog() bool {
+	for i := range s.Logs {
+		if s.Logs[i].isError() {
+			return true
+		}
+	}
+	return false
+}
+
+// errorEssentialTags and errorEssentialTagPrefixes identify the tags kept by TrimToErrorEssentials.
+var (
+	errorEssentialTags = []string{
+		string(ext.Error),
+		string(ext.SpanKind),
+		string(ext.HTTPStatusCode),
+		"rpc.grpc.status_code",
+		"otel.status_code",
+		"otel.status_description",
+	}
+	errorEssentialTagPrefixes = []string{"error.", "exception."}
+)
+
+// TrimToErrorEssentials removes the data not needed to investigate errors, e.g. before
+// archiving spans for a long time. For error spans (see IsError), only the error,
+// exception and status tags, the `span.kind` tag and the error logs are kept. Other
+// spans keep their tags but lose all logs. IDs, references, timing, process and
+// warnings are never modified.
+func (s *Span) TrimToErrorEssentials() {
+	if !s.IsError() {
+		s.Logs = nil
+		return
+	}
+	tags := s.Tags[:0]
+	for _, tag := range s.Tags {
+		if isErrorEssentialTag(tag.Key) {
+			tags = append(tags, tag)
+		}
+	}
+	s.Tags = tags
+	var logs []Log
+	for i := range s.Logs {
+		if s.Logs[i].isError() {
+			logs = append(logs, s.Logs[i])
+		}
+	}
+	s.Logs = logs
+}
+
+func isErrorEssentialTag(key string) bool {
+	if containsString(errorEssentialTags, key) {
+		return true
+	}
+	for _, prefix := range errorEssentialTagPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactTag replaces the values of all span tags and log fields with the given key
+// with RedactedValue. Returns the number of values that were redacted.
+func (s *Span) RedactTag(key string) int {
+	count := redactKeyValues(s.Tags, key)
+	for i := range s.Logs {
+		count += redactKeyValues(s.Logs[i].Fields, key)
+	}
+	return count
+}
+
+// RedactValuesMatching replaces all matches of re in string values of span tags and
+// log fields with the literal replacement, regardless of the keys.
+// Returns the number of matches that were replaced.
+func (s *Span) RedactValuesMatching(re *regexp.Regexp, replacement string) int {
+	count := redactValuesMatching(s.Tags, re, replacement)
+	for i := range s.Logs {
+		count += redactValuesMatching(s.Logs[i].Fields, re, replacement)
+	}
+	return count
+}
+
+// RemoveTag removes all span tags with the given key.
+// Returns the number of tags that were removed.
+func (s *Span) RemoveTag(key string) int {
+	tags := s.Tags[:0]
+	for _, tag := range s.Tags {
+		if tag.Key != key {
+			tags = append(tags, tag)
+		}
+	}
+	removed := len(s.Tags) - len(tags)
+	s.Tags = tags
+	return removed
+}
+
+// RemoveTagsByType removes the span tags with the given key whose value type is not
+// keepType, e.g. string values of a tag that is expected to be Int64.
+// Returns the number of tags that were removed.
+func (s *Span) RemoveTagsByType(key string, keepType ValueType) int {
+	tags := s.Tags[:0]
+	for _, tag := range s.Tags {
+		if tag.Key != key || tag.VType == keepType {
+			tags = append(tags, tag)
+		}
+	}
+	removed := len(s.Tags) - len(tags)
+	s.Tags = tags
+	return removed
+}
+
+// NormalizeBoolTags converts the span tags with the given keys that hold recognized
+// boolean-like values, such as "true", "yes", "0", or int64 1 (see KeyValue.AsBoolSafe),
+// into Bool-typed tags.
+// Tags with unrecognized values are left untouched and reported in span warnings.
+func (s *Span) NormalizeBoolTags(keys ...string) {
+	for i := range s.Tags {
+		tag := &s.Tags[i]
+		if tag.VType == BoolType || !containsString(keys, tag.Key) {
+			continue
+		}
+		if value, err := tag.AsBoolSafe(); err == nil {
+			*tag = Bool(tag.Key, value)
+		} else {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("cannot normalize tag %s=%s to bool", tag.Key, tag.AsString()))
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func redactKeyValues(kvs []KeyValue, key string) int {
+	count := 0
+	for i := range kvs {
+		if kvs[i].Key == key {
+			kvs[i] = String(key, RedactedValue)
+			count++
+		}
+	}
+	return count
+}
+
+func redactValuesMatching(kvs []KeyValue, re *regexp.Regexp, replacement string) int {
+	count := 0
+	for i := range kvs {
+		if kvs[i].VType != StringType {
+			continue
+		}
+		if matches := len(re.FindAllStringIndex(kvs[i].VStr, -1)); matches > 0 {
+			kvs[i].VStr = re.ReplaceAllLiteralString(kvs[i].VStr, replacement)
+			count += matches
+		}
+	}
+	return count
+}
+
 // ------- Flags -------
 
 // SetSampled sets the Flags as sampled
 func (f *Flags) SetSampled() {
-	f.setFlags(sampledFlag)
+	f.SetFlag(SampledFlag)
 }
 
-// SetDebug set the Flags as sampled
+// SetDebug set the Flags as debug
 func (f *Flags) SetDebug() {
-	f.setFlags(debugFlag)
+	f.SetFlag(DebugFlag)
+}
+
+// SetFlag sets all bits of mask in the Flags
+func (f *Flags) SetFlag(mask Flags) {
+	*f = *f | mask
 }
 
-func (f *Flags) setFlags(bit Flags) {
-	*f = *f | bit
+// ClearFlag clears all bits of mask in the Flags
+func (f *Flags) ClearFlag(mask Flags) {
+	*f = *f &^ mask
 }
 
 // IsSampled returns true if the Flags denote sampling
 func (f Flags) IsSampled() bool {
-	return f.checkFlags(sampledFlag)
+	return f.HasFlag(SampledFlag)
 }
 
 // IsDebug returns true if the Flags denote debugging
 // Debugging can be useful in testing tracing availability or correctness
 func (f Flags) IsDebug() bool {
-	return f.checkFlags(debugFlag)
+	return f.HasFlag(DebugFlag)
 }
 
-func (f Flags) checkFlags(bit Flags) bool {
-	return f&bit == bit
+// HasFlag returns true if all bits of mask are set in the Flags
+func (f Flags) HasFlag(mask Flags) bool {
+	return f&mask == mask
 }
 
 // ------- TraceID -------
@@ -159,6 +768,22 @@ func (t TraceID) String() string {
 	return fmt.Sprintf("%x%016x", t.High, t.Low)
 }
 
+// Compare returns -1, 0 or 1 if the trace ID is respectively less than, equal to,
+// or greater than the other trace ID, comparing the High parts first.
+func (t TraceID) Compare(other TraceID) int {
+	switch {
+	case t.High < other.High:
+		return -1
+	case t.High > other.High:
+		return 1
+	case t.Low < other.Low:
+		return -1
+	case t.Low > other.Low:
+		return 1
+	}
+	return 0
+}
+
 // TraceIDFromString creates a TraceID from a hexadecimal string
 func TraceIDFromString(s string) (TraceID, error) {
 	var hi, lo uint64
@@ -181,6 +806,39 @@ func TraceIDFromString(s string) (TraceID, error) {
 	return TraceID{High: hi, Low: lo}, nil
 }
 
+// TraceIDFromStringLenient creates a TraceID from the forms commonly produced by other
+// systems, rather than only the hexadecimal form of TraceIDFromString. It accepts
+//
+//   - a "0x" prefix, surrounding whitespace, and upper-case hex digits;
+//   - leading zeros beyond 32 hex characters;
+//   - the UUID form with dashes, e.g. 463ac35c-9f64-13ad-4848-5a3953bb6124;
+//   - the AWS X-Ray form 1-<8 hex digits>-<24 hex digits>, whose parts are
+//     concatenated into the 32 hex characters of the trace ID (see ToXRayString).
+func TraceIDFromStringLenient(s string) (TraceID, error) {
+	str := strings.TrimSpace(s)
+	if parts := strings.Split(str, "-"); len(parts) == 3 && parts[0] == "1" && len(parts[1]) == 8 && len(parts[2]) == 24 {
+		str = parts[1] + parts[2]
+	} else if len(parts) == 5 && len(str) == 36 {
+		str = strings.Join(parts, "")
+	} else if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		str = str[2:]
+	}
+	if extra := len(str) - 32; extra > 0 {
+		str = strings.TrimLeft(str[:extra], "0") + str[extra:]
+	}
+	if str == "" || strings.Trim(str, "0123456789abcdefABCDEF") != "" {
+		return TraceID{}, fmt.Errorf("cannot parse TraceID from %q", s)
+	}
+	return TraceIDFromString(str)
+}
+
+// ToXRayString returns the trace ID in the AWS X-Ray form 1-<8 hex digits>-<24 hex digits>,
+// where the first part is the top 32 bits of the trace ID, which X-Ray interprets as the
+// time in Unix seconds (see IDGeneratorOptions.TimePrefixed).
+func (t TraceID) ToXRayString() string {
+	return fmt.Sprintf("1-%08x-%08x%016x", t.High>>32, uint32(t.High), t.Low)
+}
+
 // MarshalText allows TraceID to serialize itself in JSON as a string.
 func (t TraceID) MarshalText() ([]byte, error) {
 	return []byte(t.String()), nil
@@ -196,6 +854,28 @@ func (t *TraceID) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// MarshalYAML allows TraceID to serialize itself in YAML as a string.
+// Unlike MarshalText, it always uses 32 hex characters, to make the IDs
+// in configuration files easier to compare.
+func (t TraceID) MarshalYAML() (interface{}, error) {
+	return t.paddedHex(), nil
+}
+
+// paddedHex returns the trace ID as 32 lowercase hex characters, including leading zeros.
+func (t TraceID) paddedHex() string {
+	return fmt.Sprintf("%016x%016x", t.High, t.Low)
+}
+
+// UnmarshalYAML allows TraceID to deserialize itself from a YAML string,
+// which may or may not be zero-padded.
+func (t *TraceID) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(s))
+}
+
 // ------- SpanID -------
 
 func (s SpanID) String() string {
@@ -214,6 +894,42 @@ func SpanIDFromString(s string) (SpanID, error) {
 	return SpanID(id), nil
 }
 
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Base62 converts SpanID to a base62 string using the digits 0-9, A-Z and a-z,
+// a shorter alternative to the hex form for use in URLs.
+func (s SpanID) Base62() string {
+	if s == 0 {
+		return "0"
+	}
+	var buf [11]byte // 62^11 > 2^64
+	i := len(buf)
+	for id := uint64(s); id > 0; id /= 62 {
+		i--
+		buf[i] = base62Alphabet[id%62]
+	}
+	return string(buf[i:])
+}
+
+// SpanIDFromBase62 creates a SpanID from a base62 string, see SpanID.Base62.
+func SpanIDFromBase62(s string) (SpanID, error) {
+	if s == "" {
+		return SpanID(0), fmt.Errorf("cannot parse empty base62 SpanID")
+	}
+	var id uint64
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base62Alphabet, s[i])
+		if digit < 0 {
+			return SpanID(0), fmt.Errorf("invalid character %q in base62 SpanID: %s", s[i], s)
+		}
+		if id > (math.MaxUint64-uint64(digit))/62 {
+			return SpanID(0), fmt.Errorf("base62 SpanID is out of range: %s", s)
+		}
+		id = id*62 + uint64(digit)
+	}
+	return SpanID(id), nil
+}
+
 // MarshalText allows SpanID to serialize itself in JSON as a string.
 func (s SpanID) MarshalText() ([]byte, error) {
 	return []byte(s.String()), nil
@@ -228,3 +944,17 @@ func (s *SpanID) UnmarshalText(text []byte) error {
 	*s = q
 	return nil
 }
+
+// MarshalYAML allows SpanID to serialize itself in YAML as a string.
+func (s SpanID) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// UnmarshalYAML allows SpanID to deserialize itself from a YAML string.
+func (s *SpanID) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	return s.UnmarshalText([]byte(str))
+}
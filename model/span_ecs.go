@@ -0,0 +1,68 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ecsLabelKeyReplacer replaces the characters that are not allowed in ECS label keys.
+var ecsLabelKeyReplacer = strings.NewReplacer(".", "_", "*", "_", `"`, "_")
+
+type ecsDocument struct {
+	Timestamp time.Time         `json:"@timestamp"`
+	Trace     ecsID             `json:"trace"`
+	Span      ecsID             `json:"span"`
+	Service   ecsService        `json:"service"`
+	Event     ecsEvent          `json:"event"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type ecsID struct {
+	ID string `json:"id"`
+}
+
+type ecsService struct {
+	Name string `json:"name,omitempty"`
+}
+
+type ecsEvent struct {
+	Duration int64 `json:"duration"`
+}
+
+// ToECS returns the span as a JSON document following the Elastic Common Schema:
+// `@timestamp` is the start time in UTC, `trace.id` and `span.id` are hex IDs
+// zero-padded to 32 and 16 characters, `service.name` comes from the process,
+// `event.duration` is in nanoseconds, and span tags are stored in `labels`.
+// Labels are keywords, so all tag values are converted to strings, and '.', '*'
+// and '"' in tag keys are replaced with '_' as ECS does not allow them in label keys.
+func (s *Span) ToECS() ([]byte, error) {
+	doc := ecsDocument{
+		Timestamp: s.StartTime.UTC(),
+		Trace:     ecsID{ID: s.TraceID.ToW3CString()},
+		Span:      ecsID{ID: s.SpanID.ToW3CString()},
+		Service:   ecsService{Name: s.serviceName()},
+		Event:     ecsEvent{Duration: s.Duration.Nanoseconds()},
+	}
+	if len(s.Tags) > 0 {
+		doc.Labels = make(map[string]string, len(s.Tags))
+		for i := range s.Tags {
+			doc.Labels[ecsLabelKeyReplacer.Replace(s.Tags[i].Key)] = s.Tags[i].AsString()
+		}
+	}
+	return json.Marshal(doc)
+}
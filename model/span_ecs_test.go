@@ -0,0 +1,73 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanToECS(t *testing.T) {
+	span := &model.Span{
+		TraceID:   model.TraceID{High: 1, Low: 2},
+		SpanID:    model.SpanID(0xab),
+		StartTime: time.Date(2018, 1, 2, 3, 4, 5, 6000, time.FixedZone("CET", 3600)),
+		Duration:  1500 * time.Microsecond,
+		Tags: model.KeyValues{
+			model.String("http.method", "GET"),
+			model.Int64("http.status_code", 200),
+			model.Bool("error", false),
+			model.Binary("a*b\"c", []byte{0xca, 0xfe}),
+		},
+		Process: model.NewProcess("frontend", nil),
+	}
+	doc, err := span.ToECS()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@timestamp": "2018-01-02T02:04:05.000006Z",
+		"trace": {"id": "00000000000000010000000000000002"},
+		"span": {"id": "00000000000000ab"},
+		"service": {"name": "frontend"},
+		"event": {"duration": 1500000},
+		"labels": {
+			"http_method": "GET",
+			"http_status_code": "200",
+			"error": "false",
+			"a_b_c": "cafe"
+		}
+	}`, string(doc))
+}
+
+func TestSpanToECSMinimal(t *testing.T) {
+	span := &model.Span{
+		TraceID:   model.TraceID{Low: 1},
+		SpanID:    model.SpanID(1),
+		StartTime: time.Unix(0, 0),
+	}
+	doc, err := span.ToECS()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@timestamp": "1970-01-01T00:00:00Z",
+		"trace": {"id": "00000000000000000000000000000001"},
+		"span": {"id": "0000000000000001"},
+		"service": {},
+		"event": {"duration": 0}
+	}`, string(doc))
+}
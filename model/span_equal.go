@@ -0,0 +1,95 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "math"
+
+// Equal compares Span object with another Span. Tags, logs and process tags
+// are compared in order, so they should be in canonical form, e.g. sorted.
+func (s *Span) Equal(other *Span) bool {
+	return s.EqualWithTolerance(other, 0)
+}
+
+// EqualWithTolerance compares spans like Equal, but treats float64 values of tags,
+// log fields and process tags as equal if they differ by at most floatTol.
+// NaN values are equal to each other, so that a span is always equal to itself.
+func (s *Span) EqualWithTolerance(other *Span, floatTol float64) bool {
+	if s.TraceID != other.TraceID ||
+		s.SpanID != other.SpanID ||
+		s.OperationName != other.OperationName ||
+		s.Flags != other.Flags ||
+		!s.StartTime.Equal(other.StartTime) ||
+		s.Duration != other.Duration {
+		return false
+	}
+	if len(s.References) != len(other.References) {
+		return false
+	}
+	for i := range s.References {
+		ref, otherRef := &s.References[i], &other.References[i]
+		if ref.RefType != otherRef.RefType ||
+			ref.TraceID != otherRef.TraceID ||
+			ref.SpanID != otherRef.SpanID ||
+			!keyValuesEqualWithTolerance(ref.Tags, otherRef.Tags, floatTol) {
+			return false
+		}
+	}
+	if !keyValuesEqualWithTolerance(s.Tags, other.Tags, floatTol) {
+		return false
+	}
+	if len(s.Logs) != len(other.Logs) {
+		return false
+	}
+	for i := range s.Logs {
+		if !s.Logs[i].Timestamp.Equal(other.Logs[i].Timestamp) ||
+			!keyValuesEqualWithTolerance(s.Logs[i].Fields, other.Logs[i].Fields, floatTol) {
+			return false
+		}
+	}
+	if (s.Process == nil) != (other.Process == nil) {
+		return false
+	}
+	if s.Process != nil && (s.Process.ServiceName != other.Process.ServiceName ||
+		!keyValuesEqualWithTolerance(s.Process.Tags, other.Process.Tags, floatTol)) {
+		return false
+	}
+	if len(s.Warnings) != len(other.Warnings) {
+		return false
+	}
+	for i := range s.Warnings {
+		if s.Warnings[i] != other.Warnings[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func keyValuesEqualWithTolerance(kvs, other []KeyValue, floatTol float64) bool {
+	if len(kvs) != len(other) {
+		return false
+	}
+	for i := range kvs {
+		a, b := &kvs[i], &other[i]
+		if a.VType == Float64Type && b.VType == Float64Type && a.Key == b.Key {
+			x, y := a.Float64(), b.Float64()
+			if !(math.Abs(x-y) <= floatTol || math.IsNaN(x) && math.IsNaN(y)) {
+				return false
+			}
+		} else if !a.Equal(b) {
+			return false
+		}
+	}
+	return true
+}
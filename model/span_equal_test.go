@@ -0,0 +1,99 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeEqualSpan() *model.Span {
+	return &model.Span{
+		TraceID:       model.TraceID{Low: 1},
+		SpanID:        model.SpanID(2),
+		OperationName: "op",
+		References:    []model.SpanRef{model.NewChildOfRef(model.TraceID{Low: 1}, 1)},
+		StartTime:     time.Unix(100, 0),
+		Duration:      time.Second,
+		Tags:          []model.KeyValue{model.Float64("load", 0.3), model.String("k", "v")},
+		Logs:          []model.Log{{Timestamp: time.Unix(100, 0), Fields: []model.KeyValue{model.Float64("ratio", 1.0/3)}}},
+		Process:       model.NewProcess("svc", []model.KeyValue{model.Float64("sampler.param", 0.1)}),
+		Warnings:      []string{"w"},
+	}
+}
+
+func TestSpanEqual(t *testing.T) {
+	span := makeEqualSpan()
+	assert.True(t, span.Equal(makeEqualSpan()))
+
+	other := makeEqualSpan()
+	other.StartTime = other.StartTime.In(time.FixedZone("UTC+1", 3600))
+	assert.True(t, span.Equal(other), "start times are compared as instants")
+
+	other.Tags[0] = model.Float64("load", 0.30000000000000004)
+	assert.False(t, span.Equal(other))
+
+	span.Tags[0] = model.Float64("load", math.NaN())
+	other.Tags[0] = model.Float64("load", math.NaN())
+	assert.True(t, span.Equal(span), "NaN tags")
+	assert.True(t, span.Equal(other), "NaN tags")
+	assert.True(t, span.EqualWithTolerance(other, 1e-6), "NaN tags")
+}
+
+func TestSpanEqualWithTolerance(t *testing.T) {
+	testCases := []struct {
+		caption string
+		modify  func(s *model.Span)
+		equal   bool
+	}{
+		{caption: "tag jitter", modify: func(s *model.Span) { s.Tags[0] = model.Float64("load", 0.30000000000000004) }, equal: true},
+		{caption: "log field jitter", modify: func(s *model.Span) { s.Logs[0].Fields[0] = model.Float64("ratio", 0.3333333333) }, equal: true},
+		{caption: "process tag jitter", modify: func(s *model.Span) { s.Process.Tags[0] = model.Float64("sampler.param", 0.1000000001) }, equal: true},
+		{caption: "float beyond tolerance", modify: func(s *model.Span) { s.Tags[0] = model.Float64("load", 0.31) }, equal: false},
+		{caption: "NaN", modify: func(s *model.Span) { s.Tags[0] = model.Float64("load", math.NaN()) }, equal: false},
+		{caption: "float key", modify: func(s *model.Span) { s.Tags[0] = model.Float64("cpu", 0.3) }, equal: false},
+		{caption: "float vs int", modify: func(s *model.Span) { s.Tags[0] = model.Int64("load", 0) }, equal: false},
+		{caption: "string tag", modify: func(s *model.Span) { s.Tags[1] = model.String("k", "x") }, equal: false},
+		{caption: "missing tag", modify: func(s *model.Span) { s.Tags = s.Tags[:1] }, equal: false},
+		{caption: "span ID", modify: func(s *model.Span) { s.SpanID = 3 }, equal: false},
+		{caption: "operation", modify: func(s *model.Span) { s.OperationName = "other" }, equal: false},
+		{caption: "flags", modify: func(s *model.Span) { s.Flags = 1 }, equal: false},
+		{caption: "duration", modify: func(s *model.Span) { s.Duration++ }, equal: false},
+		{caption: "start time", modify: func(s *model.Span) { s.StartTime = s.StartTime.Add(1) }, equal: false},
+		{caption: "reference", modify: func(s *model.Span) { s.References[0].SpanID = 5 }, equal: false},
+		{caption: "references", modify: func(s *model.Span) { s.References = nil }, equal: false},
+		{caption: "log timestamp", modify: func(s *model.Span) { s.Logs[0].Timestamp = time.Unix(101, 0) }, equal: false},
+		{caption: "logs", modify: func(s *model.Span) { s.Logs = nil }, equal: false},
+		{caption: "nil process", modify: func(s *model.Span) { s.Process = nil }, equal: false},
+		{caption: "service name", modify: func(s *model.Span) { s.Process.ServiceName = "other" }, equal: false},
+		{caption: "warning", modify: func(s *model.Span) { s.Warnings[0] = "x" }, equal: false},
+		{caption: "warnings", modify: func(s *model.Span) { s.Warnings = nil }, equal: false},
+	}
+	for _, testCase := range testCases {
+		span, other := makeEqualSpan(), makeEqualSpan()
+		testCase.modify(other)
+		assert.Equal(t, testCase.equal, span.EqualWithTolerance(other, 1e-6), testCase.caption)
+		assert.Equal(t, testCase.equal, other.EqualWithTolerance(span, 1e-6), testCase.caption)
+	}
+
+	span, other := makeEqualSpan(), makeEqualSpan()
+	span.Process, other.Process = nil, nil
+	assert.True(t, span.EqualWithTolerance(other, 0))
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SpanFilter is a predicate over spans, e.g. compiled by NewSpanFilter.
+type SpanFilter func(span *Span) bool
+
+// NewSpanFilter compiles a filter expression into a SpanFilter, for example
+//
+//	service="api" && duration>100ms && tag.http.status_code>=500
+//
+// An expression combines comparisons with && (and), || (or), ! (not) and parentheses,
+// with && binding tighter than ||. Each comparison has a field on the left, one of the
+// operators =, ==, !=, <, <=, > and >=, and a value on the right. The fields are:
+//
+//   - service, operation and traceID, compared as strings;
+//   - duration, compared with a value in time.ParseDuration syntax, such as 1.5s;
+//   - error, compared with true or false (see Span.IsError);
+//   - tag.<key> and process.<key>, the first span or process tag with the key.
+//
+// Values are double-quoted strings, numbers, durations, true or false, or bare words,
+// which are treated as strings. Tags are compared as numbers with number values, as
+// Booleans with true and false, and as strings (see KeyValue.AsString) otherwise, using
+// the coercion rules of KeyValue.AsFloat64Safe and KeyValue.AsBoolSafe. Comparisons of
+// missing tags, or of tags that cannot be coerced, are false. Non-finite numbers, such
+// as nan or inf, are rejected unless quoted.
+func NewSpanFilter(expr string) (SpanFilter, error) {
+	tokens, err := tokenizeSpanFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &spanFilterParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != filterEOF {
+		return nil, token.errorf("unexpected %s", token)
+	}
+	return filter, nil
+}
+
+type filterTokenKind int
+
+const (
+	filterEOF filterTokenKind = iota
+	filterWord
+	filterString
+	filterOperator
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+func (t filterToken) String() string {
+	switch t.kind {
+	case filterEOF:
+		return "end of filter"
+	case filterString:
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+func (t filterToken) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid span filter at position %d: %s", t.pos, fmt.Sprintf(format, args...))
+}
+
+// filterOperators lists the operators, with two-character operators first.
+var filterOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=", "<", ">", "!", "(", ")"}
+
+func tokenizeSpanFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for pos := 0; pos < len(expr); {
+		c := expr[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c == '"':
+			end := pos + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, filterToken{pos: pos}.errorf("unterminated string")
+			}
+			text, err := strconv.Unquote(expr[pos : end+1])
+			if err != nil {
+				return nil, filterToken{pos: pos}.errorf("invalid string %s: %v", expr[pos:end+1], err)
+			}
+			tokens = append(tokens, filterToken{kind: filterString, text: text, pos: pos})
+			pos = end + 1
+		case isFilterOperatorChar(c):
+			op := ""
+			for _, candidate := range filterOperators {
+				if strings.HasPrefix(expr[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, filterToken{pos: pos}.errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, filterToken{kind: filterOperator, text: op, pos: pos})
+			pos += len(op)
+		default:
+			end := pos
+			for end < len(expr) && !isFilterOperatorChar(expr[end]) && !strings.ContainsRune(" \t\n\r\"", rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: filterWord, text: expr[pos:end], pos: pos})
+			pos = end
+		}
+	}
+	return append(tokens, filterToken{kind: filterEOF, pos: len(expr)}), nil
+}
+
+func isFilterOperatorChar(c byte) bool {
+	return strings.IndexByte("&|=!<>()", c) >= 0
+}
+
+type spanFilterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *spanFilterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *spanFilterParser) next() filterToken {
+	token := p.tokens[p.pos]
+	if token.kind != filterEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *spanFilterParser) acceptOperator(op string) bool {
+	if token := p.peek(); token.kind == filterOperator && token.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *spanFilterParser) parseOr() (SpanFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOperator("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter(left, right)
+	}
+	return left, nil
+}
+
+func (p *spanFilterParser) parseAnd() (SpanFilter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOperator("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter(left, right)
+	}
+	return left, nil
+}
+
+func (p *spanFilterParser) parseUnary() (SpanFilter, error) {
+	if p.acceptOperator("!") {
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(span *Span) bool { return !filter(span) }, nil
+	}
+	if p.acceptOperator("(") {
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token := p.next(); token.kind != filterOperator || token.text != ")" {
+			return nil, token.errorf("expected ')', got %s", token)
+		}
+		return filter, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *spanFilterParser) parseComparison() (SpanFilter, error) {
+	field := p.next()
+	if field.kind != filterWord {
+		return nil, field.errorf("expected field, got %s", field)
+	}
+	opToken := p.next()
+	op, ok := filterComparisons[opToken.text]
+	if opToken.kind != filterOperator || !ok {
+		return nil, opToken.errorf("expected comparison operator, got %s", opToken)
+	}
+	value := p.next()
+	if value.kind != filterWord && value.kind != filterString {
+		return nil, value.errorf("expected value, got %s", value)
+	}
+	return compileComparison(field, op, value)
+}
+
+func orFilter(left, right SpanFilter) SpanFilter {
+	return func(span *Span) bool { return left(span) || right(span) }
+}
+
+func andFilter(left, right SpanFilter) SpanFilter {
+	return func(span *Span) bool { return left(span) && right(span) }
+}
+
+// filterComparison returns whether the result of a three-way comparison satisfies the operator.
+type filterComparison func(cmp int) bool
+
+var filterComparisons = map[string]filterComparison{
+	"=":  func(cmp int) bool { return cmp == 0 },
+	"==": func(cmp int) bool { return cmp == 0 },
+	"!=": func(cmp int) bool { return cmp != 0 },
+	"<":  func(cmp int) bool { return cmp < 0 },
+	"<=": func(cmp int) bool { return cmp <= 0 },
+	">":  func(cmp int) bool { return cmp > 0 },
+	">=": func(cmp int) bool { return cmp >= 0 },
+}
+
+func compileComparison(field filterToken, op filterComparison, value filterToken) (SpanFilter, error) {
+	switch name := field.text; {
+	case name == "service":
+		return stringComparison(func(span *Span) string { return span.serviceName() }, op, value.text), nil
+	case name == "operation":
+		return stringComparison(func(span *Span) string { return span.OperationName }, op, value.text), nil
+	case name == "traceID":
+		return stringComparison(func(span *Span) string { return span.TraceID.String() }, op, value.text), nil
+	case name == "duration":
+		d, err := time.ParseDuration(value.text)
+		if err != nil {
+			return nil, value.errorf("invalid duration %s", value)
+		}
+		return func(span *Span) bool { return op(compareInt64(int64(span.Duration), int64(d))) }, nil
+	case name == "error":
+		b, ok := parseFilterBool(value)
+		if !ok {
+			return nil, value.errorf("expected true or false, got %s", value)
+		}
+		return func(span *Span) bool { return op(compareBool(span.IsError(), b)) }, nil
+	case strings.HasPrefix(name, "tag.") && len(name) > len("tag."):
+		key := name[len("tag."):]
+		return tagComparison(func(span *Span) (KeyValue, bool) {
+			return KeyValues(span.Tags).FindByKey(key)
+		}, op, value)
+	case strings.HasPrefix(name, "process.") && len(name) > len("process."):
+		key := name[len("process."):]
+		return tagComparison(func(span *Span) (KeyValue, bool) {
+			if span.Process == nil {
+				return KeyValue{}, false
+			}
+			return KeyValues(span.Process.Tags).FindByKey(key)
+		}, op, value)
+	}
+	return nil, field.errorf("unknown field %s", field)
+}
+
+func stringComparison(get func(*Span) string, op filterComparison, value string) SpanFilter {
+	return func(span *Span) bool { return op(strings.Compare(get(span), value)) }
+}
+
+func tagComparison(get func(*Span) (KeyValue, bool), op filterComparison, value filterToken) (SpanFilter, error) {
+	if b, ok := parseFilterBool(value); ok {
+		return func(span *Span) bool {
+			tag, ok := get(span)
+			if !ok {
+				return false
+			}
+			actual, err := tag.AsBoolSafe()
+			return err == nil && op(compareBool(actual, b))
+		}, nil
+	}
+	if f, err := strconv.ParseFloat(value.text, 64); err == nil && value.kind == filterWord {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, value.errorf("invalid number %s", value)
+		}
+		return func(span *Span) bool {
+			tag, ok := get(span)
+			if !ok {
+				return false
+			}
+			actual, err := tag.AsFloat64Safe()
+			if err != nil || actual != actual {
+				// NaN is not comparable
+				return false
+			}
+			return op(compareFloat64(actual, f))
+		}, nil
+	}
+	return func(span *Span) bool {
+		tag, ok := get(span)
+		return ok && op(strings.Compare(tag.AsString(), value.text))
+	}, nil
+}
+
+func parseFilterBool(value filterToken) (bool, bool) {
+	if value.kind == filterWord {
+		switch value.text {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestNewSpanFilter(t *testing.T) {
+	span := &model.Span{
+		TraceID:       model.TraceID{Low: 0xabc},
+		OperationName: "GET /users",
+		Duration:      150 * time.Millisecond,
+		Tags: []model.KeyValue{
+			model.String("http.status_code", "503"),
+			model.Int64("retries", 2),
+			model.Bool("error", true),
+			model.String("region", "us-east-1"),
+			model.String("cached", "yes"),
+		},
+		Process: model.NewProcess("api", []model.KeyValue{model.String("hostname", "host-1")}),
+	}
+	testCases := []struct {
+		expr  string
+		match bool
+	}{
+		{`service="api"`, true},
+		{`service == api`, true},
+		{`service!="api"`, false},
+		{`operation="GET /users"`, true},
+		{`traceID=abc`, true},
+		{`duration>100ms`, true},
+		{`duration>=150ms && duration<=0.15s`, true},
+		{`duration<1s && duration>1m`, false},
+		{`error=true`, true},
+		{`error!=true`, false},
+		{`tag.http.status_code>=500`, true},
+		{`tag.http.status_code<500`, false},
+		{`tag.http.status_code="503"`, true},
+		{`tag.http.status_code>"6"`, false},
+		{`tag.retries=2`, true},
+		{`tag.retries>1.5`, true},
+		{`tag.error=true`, true},
+		{`tag.cached=true`, true},
+		{`tag.region=us-east-1`, true},
+		{`tag.region>=10`, false},
+		{`tag.missing!=1`, false},
+		{`process.hostname="host-1"`, true},
+		{`process.hostname="host-2"`, false},
+		{`service="api" && duration>100ms && tag.http.status_code>=500`, true},
+		{`service="web" || tag.retries=2`, true},
+		{`service="web" || tag.retries=3 && error=true`, false},
+		{`(service="web" || tag.retries=2) && error=true`, true},
+		{`!service="web"`, true},
+		{`!(service="api" && error=true)`, false},
+	}
+	for _, testCase := range testCases {
+		filter, err := model.NewSpanFilter(testCase.expr)
+		require.NoError(t, err, testCase.expr)
+		assert.Equal(t, testCase.match, filter(span), testCase.expr)
+	}
+
+	filter, err := model.NewSpanFilter(`service="api" || process.hostname=x`)
+	require.NoError(t, err)
+	assert.False(t, filter(&model.Span{}), "spans without process")
+}
+
+func TestNewSpanFilterErrors(t *testing.T) {
+	testCases := []struct {
+		expr string
+		err  string
+	}{
+		{``, "invalid span filter at position 0: expected field, got end of filter"},
+		{`service`, "invalid span filter at position 7: expected comparison operator, got end of filter"},
+		{`service=`, "invalid span filter at position 8: expected value, got end of filter"},
+		{`service && x`, "invalid span filter at position 8: expected comparison operator, got '&&'"},
+		{`host="a"`, "invalid span filter at position 0: unknown field 'host'"},
+		{`tag.=1`, "invalid span filter at position 0: unknown field 'tag.'"},
+		{`duration>fast`, "invalid span filter at position 9: invalid duration 'fast'"},
+		{`error=1`, "invalid span filter at position 6: expected true or false, got '1'"},
+		{`tag.x>nan`, "invalid span filter at position 6: invalid number 'nan'"},
+		{`process.x<-Inf`, "invalid span filter at position 10: invalid number '-Inf'"},
+		{`service="api`, "invalid span filter at position 8: unterminated string"},
+		{`service="a\q"`, `invalid span filter at position 8: invalid string "a\q": invalid syntax`},
+		{`service="a" & x=1`, "invalid span filter at position 12: unexpected character '&'"},
+		{`(service="a"`, "invalid span filter at position 12: expected ')', got end of filter"},
+		{`service="a" service="b"`, `invalid span filter at position 12: unexpected 'service'`},
+		{`service="a")`, "invalid span filter at position 11: unexpected ')'"},
+	}
+	for _, testCase := range testCases {
+		_, err := model.NewSpanFilter(testCase.expr)
+		assert.EqualError(t, err, testCase.err, testCase.expr)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// lokiLabelKeys maps process tags to the Loki labels used to correlate spans with logs,
+// in order of precedence when several tags map to the same label.
+var lokiLabelKeys = []struct {
+	tag   string
+	label string
+}{
+	{tag: "hostname", label: "hostname"},
+	{tag: "host.name", label: "hostname"},
+	{tag: "k8s.cluster.name", label: "cluster"},
+	{tag: "k8s.namespace.name", label: "namespace"},
+	{tag: "k8s.pod.name", label: "pod"},
+}
+
+// TempoTraceID returns the trace ID of the span as 32 lowercase hex characters,
+// the form required by Grafana Tempo and by trace-to-logs links. Unlike
+// TraceID.String, it keeps leading zeros.
+func (s *Span) TempoTraceID() string {
+	return s.TraceID.paddedHex()
+}
+
+// LokiLabels returns the Loki stream labels identifying the logs produced by the
+// process of the span: `service_name` from the process service name, and `hostname`,
+// `cluster`, `namespace` and `pod` from the corresponding process tags (`hostname` or
+// `host.name`, `k8s.cluster.name`, `k8s.namespace.name`, `k8s.pod.name`). Labels that
+// cannot be determined are omitted. The trace ID is not a label, as it would create a
+// stream per trace; use TempoTraceID to find the trace in the log lines instead.
+func (s *Span) LokiLabels() map[string]string {
+	labels := make(map[string]string)
+	if s.Process == nil {
+		return labels
+	}
+	if s.Process.ServiceName != "" {
+		labels["service_name"] = s.Process.ServiceName
+	}
+	for _, key := range lokiLabelKeys {
+		if _, ok := labels[key.label]; ok {
+			continue
+		}
+		if value, ok := s.Process.findTagAsString(key.tag); ok && value != "" {
+			labels[key.label] = value
+		}
+	}
+	return labels
+}
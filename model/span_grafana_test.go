@@ -0,0 +1,61 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanTempoTraceID(t *testing.T) {
+	testCases := []struct {
+		traceID  model.TraceID
+		expected string
+	}{
+		{traceID: model.TraceID{Low: 1}, expected: "00000000000000000000000000000001"},
+		{traceID: model.TraceID{High: 0xab, Low: 0xcd}, expected: "00000000000000ab00000000000000cd"},
+		{traceID: model.TraceID{High: 0xffffffffffffffff, Low: 0xffffffffffffffff}, expected: "ffffffffffffffffffffffffffffffff"},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{TraceID: testCase.traceID}
+		assert.Equal(t, testCase.expected, span.TempoTraceID())
+	}
+}
+
+func TestSpanLokiLabels(t *testing.T) {
+	span := &model.Span{
+		Process: model.NewProcess("frontend", []model.KeyValue{
+			model.String("host.name", "host-b"),
+			model.String("hostname", "host-a"),
+			model.String("k8s.namespace.name", "prod"),
+			model.String("k8s.pod.name", "frontend-1"),
+			model.String("k8s.cluster.name", ""),
+			model.String("ip", "10.0.0.1"),
+		}),
+	}
+	assert.Equal(t, map[string]string{
+		"service_name": "frontend",
+		"hostname":     "host-a",
+		"namespace":    "prod",
+		"pod":          "frontend-1",
+	}, span.LokiLabels())
+
+	span = &model.Span{Process: model.NewProcess("", []model.KeyValue{model.String("host.name", "host-b")})}
+	assert.Equal(t, map[string]string{"hostname": "host-b"}, span.LokiLabels())
+	assert.Empty(t, (&model.Span{}).LokiLabels())
+}
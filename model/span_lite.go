@@ -0,0 +1,42 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "time"
+
+// SpanLite is a summary of a span with just the fields needed to render lists of spans.
+type SpanLite struct {
+	TraceID   string        `json:"traceID"`
+	SpanID    string        `json:"spanID"`
+	Service   string        `json:"service"`
+	Operation string        `json:"operation"`
+	StartTime time.Time     `json:"startTime"`
+	Duration  time.Duration `json:"duration"`
+	IsError   bool          `json:"isError"`
+}
+
+// Lite returns the summary of the span, see SpanLite. The service is empty
+// if the span has no process.
+func (s *Span) Lite() SpanLite {
+	return SpanLite{
+		TraceID:   s.TraceID.String(),
+		SpanID:    s.SpanID.String(),
+		Service:   s.serviceName(),
+		Operation: s.OperationName,
+		StartTime: s.StartTime,
+		Duration:  s.Duration,
+		IsError:   s.IsError(),
+	}
+}
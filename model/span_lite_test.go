@@ -0,0 +1,50 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanLite(t *testing.T) {
+	base := time.Unix(100, 0)
+	span := &model.Span{
+		TraceID:       model.TraceID{High: 1, Low: 2},
+		SpanID:        model.SpanID(0xab),
+		OperationName: "GET /",
+		StartTime:     base,
+		Duration:      time.Second,
+		Tags:          model.KeyValues{model.Bool("error", true)},
+		Logs:          []model.Log{{Timestamp: base}},
+		Process:       model.NewProcess("frontend", nil),
+	}
+	assert.Equal(t, model.SpanLite{
+		TraceID:   "10000000000000002",
+		SpanID:    "ab",
+		Service:   "frontend",
+		Operation: "GET /",
+		StartTime: base,
+		Duration:  time.Second,
+		IsError:   true,
+	}, span.Lite())
+
+	span = &model.Span{TraceID: model.TraceID{Low: 1}, SpanID: model.SpanID(1)}
+	assert.Equal(t, model.SpanLite{TraceID: "1", SpanID: "1"}, span.Lite())
+}
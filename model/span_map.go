@@ -0,0 +1,50 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "time"
+
+// ToMap returns a generic map representation of the span for use in templates,
+// e.g. text/template. All values are strings, numbers or booleans:
+//
+//   - traceID, spanID: hex strings
+//   - operationName, serviceName: strings
+//   - startTime: RFC3339 string with nanoseconds, in UTC
+//   - durationMs: float64 number of milliseconds
+//   - tags: map from tag key to value, with binary values as hex strings
+func (s *Span) ToMap() map[string]interface{} {
+	tags := make(map[string]interface{}, len(s.Tags))
+	for i := range s.Tags {
+		tags[s.Tags[i].Key] = templateValue(&s.Tags[i])
+	}
+	return map[string]interface{}{
+		"traceID":       s.TraceID.String(),
+		"spanID":        s.SpanID.String(),
+		"operationName": s.OperationName,
+		"serviceName":   s.serviceName(),
+		"startTime":     s.StartTime.UTC().Format(time.RFC3339Nano),
+		"durationMs":    float64(s.Duration) / float64(time.Millisecond),
+		"tags":          tags,
+	}
+}
+
+func templateValue(kv *KeyValue) interface{} {
+	switch kv.VType {
+	case BoolType, Int64Type, Float64Type:
+		return kv.Value()
+	default:
+		return kv.AsString()
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanToMap(t *testing.T) {
+	span := &model.Span{
+		TraceID:       model.TraceID{High: 1, Low: 2},
+		SpanID:        model.SpanID(0xab),
+		OperationName: "GET /",
+		StartTime:     time.Date(2018, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Duration:      1500 * time.Microsecond,
+		Tags: model.KeyValues{
+			model.String("s", "v"),
+			model.Bool("b", true),
+			model.Int64("i", 7),
+			model.Float64("f", 0.5),
+			model.Binary("bin", []byte{0xca, 0xfe}),
+		},
+		Process: model.NewProcess("frontend", nil),
+	}
+	assert.Equal(t, map[string]interface{}{
+		"traceID":       "10000000000000002",
+		"spanID":        "ab",
+		"operationName": "GET /",
+		"serviceName":   "frontend",
+		"startTime":     "2018-01-02T03:04:05.000006Z",
+		"durationMs":    1.5,
+		"tags": map[string]interface{}{
+			"s":   "v",
+			"b":   true,
+			"i":   int64(7),
+			"f":   0.5,
+			"bin": "cafe",
+		},
+	}, span.ToMap())
+
+	tmpl := template.Must(template.New("alert").Parse(`{{.serviceName}} {{.operationName}} took {{.durationMs}}ms ({{index .tags "s"}})`))
+	buf := &bytes.Buffer{}
+	require.NoError(t, tmpl.Execute(buf, span.ToMap()))
+	assert.Equal(t, "frontend GET / took 1.5ms (v)", buf.String())
+
+	span.Process = nil
+	assert.Equal(t, "", span.ToMap()["serviceName"])
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "strings"
+
+// resourceAttributePrefixes are the key prefixes of OpenTelemetry semantic conventions
+// that describe the entity producing telemetry, i.e. attributes of the Resource.
+var resourceAttributePrefixes = []string{
+	"service.",
+	"host.",
+	"telemetry.sdk.",
+	"process.",
+	"os.",
+	"container.",
+	"k8s.",
+	"cloud.",
+	"deployment.",
+}
+
+// SplitAttributes partitions the span attributes for OpenTelemetry export.
+// Process tags with well-known resource key prefixes (service.*, host.*, telemetry.sdk.*, etc.)
+// are returned as resource attributes, along with `service.name` derived from the Process
+// if it is not set explicitly. The span tags and the remaining process tags are returned
+// as span attributes. The `otel.schema_url` process tag is omitted, as OpenTelemetry
+// carries the schema URL in a separate field (see Process.SchemaURL).
+func (s *Span) SplitAttributes() (resourceAttrs, spanAttrs KeyValues) {
+	spanAttrs = append(spanAttrs, s.Tags...)
+	if s.Process == nil {
+		return nil, spanAttrs
+	}
+	hasServiceName := false
+	for _, tag := range s.Process.Tags {
+		if tag.Key == SchemaURLTagKey {
+			continue
+		}
+		if isResourceAttribute(tag.Key) {
+			hasServiceName = hasServiceName || tag.Key == "service.name"
+			resourceAttrs = append(resourceAttrs, tag)
+		} else {
+			spanAttrs = append(spanAttrs, tag)
+		}
+	}
+	if !hasServiceName && s.Process.ServiceName != "" {
+		resourceAttrs = append(KeyValues{String("service.name", s.Process.ServiceName)}, resourceAttrs...)
+	}
+	return resourceAttrs, spanAttrs
+}
+
+func isResourceAttribute(key string) bool {
+	for _, prefix := range resourceAttributePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
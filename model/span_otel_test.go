@@ -0,0 +1,68 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanSplitAttributes(t *testing.T) {
+	span := &model.Span{
+		Tags: model.KeyValues{model.String("http.method", "GET")},
+		Process: &model.Process{
+			ServiceName: "frontend",
+			Tags: model.KeyValues{
+				model.String("host.name", "h1"),
+				model.String("telemetry.sdk.language", "go"),
+				model.String("custom", "x"),
+				model.String("service.version", "1.0"),
+				model.String("otel.schema_url", "https://opentelemetry.io/schemas/1.9.0"),
+			},
+		},
+	}
+	resourceAttrs, spanAttrs := span.SplitAttributes()
+	assert.Equal(t, model.KeyValues{
+		model.String("service.name", "frontend"),
+		model.String("host.name", "h1"),
+		model.String("telemetry.sdk.language", "go"),
+		model.String("service.version", "1.0"),
+	}, resourceAttrs)
+	assert.Equal(t, model.KeyValues{
+		model.String("http.method", "GET"),
+		model.String("custom", "x"),
+	}, spanAttrs)
+	assert.Len(t, span.Tags, 1, "span tags must not be modified")
+}
+
+func TestSpanSplitAttributesExplicitServiceName(t *testing.T) {
+	span := &model.Span{
+		Process: &model.Process{
+			ServiceName: "frontend",
+			Tags:        model.KeyValues{model.String("service.name", "frontend-v2")},
+		},
+	}
+	resourceAttrs, spanAttrs := span.SplitAttributes()
+	assert.Equal(t, model.KeyValues{model.String("service.name", "frontend-v2")}, resourceAttrs)
+	assert.Len(t, spanAttrs, 0)
+
+	span = &model.Span{Tags: model.KeyValues{model.String("k", "v")}}
+	resourceAttrs, spanAttrs = span.SplitAttributes()
+	assert.Nil(t, resourceAttrs)
+	assert.Equal(t, model.KeyValues{model.String("k", "v")}, spanAttrs)
+}
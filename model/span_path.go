@@ -0,0 +1,140 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Get returns the value of a span field identified by a path, or false if the path
+// is invalid or the field does not exist. The supported paths are:
+//
+//   - traceID, spanID, operationName, flags, startTime, duration, warnings[N]
+//   - tags.<key>: the value of the first tag with the key, e.g. tags.http.method
+//   - process.serviceName, process.tags.<key>
+//   - references[N].refType, references[N].traceID, references[N].spanID,
+//     references[N].tags.<key>
+//   - logs[N].timestamp, logs[N].fields.<key>
+//
+// Values keep their types, e.g. TraceID, time.Duration or SpanRefType. Tag values are
+// returned as string, bool, int64, float64 or []byte depending on their ValueType.
+func (s *Span) Get(path string) (interface{}, bool) {
+	field, rest := path, ""
+	if i := strings.IndexByte(path, '.'); i >= 0 {
+		field, rest = path[:i], path[i+1:]
+	}
+	name, index, ok := parsePathIndex(field)
+	if !ok {
+		return nil, false
+	}
+	indexed := index >= 0
+	if indexed != (name == "references" || name == "logs" || name == "warnings") {
+		return nil, false
+	}
+	switch name {
+	case "tags":
+		return findValue(s.Tags, rest)
+	case "process":
+		if s.Process == nil {
+			return nil, false
+		}
+		if rest == "serviceName" {
+			return s.Process.ServiceName, true
+		}
+		if strings.HasPrefix(rest, "tags.") {
+			return findValue(s.Process.Tags, strings.TrimPrefix(rest, "tags."))
+		}
+		return nil, false
+	case "references":
+		if index >= len(s.References) {
+			return nil, false
+		}
+		ref := s.References[index]
+		switch rest {
+		case "refType":
+			return ref.RefType, true
+		case "traceID":
+			return ref.TraceID, true
+		case "spanID":
+			return ref.SpanID, true
+		}
+		if strings.HasPrefix(rest, "tags.") {
+			return findValue(ref.Tags, strings.TrimPrefix(rest, "tags."))
+		}
+		return nil, false
+	case "logs":
+		if index >= len(s.Logs) {
+			return nil, false
+		}
+		if rest == "timestamp" {
+			return s.Logs[index].Timestamp, true
+		}
+		if strings.HasPrefix(rest, "fields.") {
+			return findValue(s.Logs[index].Fields, strings.TrimPrefix(rest, "fields."))
+		}
+		return nil, false
+	}
+	if rest != "" {
+		return nil, false
+	}
+	switch name {
+	case "traceID":
+		return s.TraceID, true
+	case "spanID":
+		return s.SpanID, true
+	case "operationName":
+		return s.OperationName, true
+	case "flags":
+		return s.Flags, true
+	case "startTime":
+		return s.StartTime, true
+	case "duration":
+		return s.Duration, true
+	case "warnings":
+		if index < len(s.Warnings) {
+			return s.Warnings[index], true
+		}
+	}
+	return nil, false
+}
+
+// parsePathIndex splits a path element like "logs[2]" into its name and index.
+// The index is -1 if the element has none.
+func parsePathIndex(field string) (name string, index int, ok bool) {
+	open := strings.IndexByte(field, '[')
+	if open < 0 {
+		return field, -1, true
+	}
+	if !strings.HasSuffix(field, "]") {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(field[open+1 : len(field)-1])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return field[:open], index, true
+}
+
+func findValue(kvs []KeyValue, key string) (interface{}, bool) {
+	if key == "" {
+		return nil, false
+	}
+	kv, ok := KeyValues(kvs).FindByKey(key)
+	if !ok {
+		return nil, false
+	}
+	return kv.Value(), true
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanGet(t *testing.T) {
+	base := time.Unix(100, 0)
+	traceID := model.TraceID{Low: 1}
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.SpanID(2),
+		OperationName: "GET /",
+		Flags:         model.Flags(1),
+		StartTime:     base,
+		Duration:      time.Second,
+		References:    []model.SpanRef{model.NewFollowsFromRef(traceID, 1)},
+		Tags:          []model.KeyValue{model.String("http.method", "GET"), model.Int64("http.status_code", 200)},
+		Logs:          []model.Log{{Timestamp: base, Fields: []model.KeyValue{model.Bool("retry", true)}}},
+		Process:       model.NewProcess("frontend", []model.KeyValue{model.Float64("sampler.param", 0.5)}),
+		Warnings:      []string{"w0", "w1"},
+	}
+	testCases := []struct {
+		path  string
+		value interface{}
+	}{
+		{path: "traceID", value: traceID},
+		{path: "spanID", value: model.SpanID(2)},
+		{path: "operationName", value: "GET /"},
+		{path: "flags", value: model.Flags(1)},
+		{path: "startTime", value: base},
+		{path: "duration", value: time.Second},
+		{path: "warnings[1]", value: "w1"},
+		{path: "tags.http.method", value: "GET"},
+		{path: "tags.http.status_code", value: int64(200)},
+		{path: "process.serviceName", value: "frontend"},
+		{path: "process.tags.sampler.param", value: 0.5},
+		{path: "references[0].refType", value: model.FollowsFrom},
+		{path: "references[0].traceID", value: traceID},
+		{path: "references[0].spanID", value: model.SpanID(1)},
+		{path: "logs[0].timestamp", value: base},
+		{path: "logs[0].fields.retry", value: true},
+	}
+	for _, testCase := range testCases {
+		value, ok := span.Get(testCase.path)
+		assert.True(t, ok, testCase.path)
+		assert.Equal(t, testCase.value, value, testCase.path)
+	}
+}
+
+func TestSpanGetInvalidPath(t *testing.T) {
+	span := &model.Span{
+		References: []model.SpanRef{model.NewChildOfRef(model.TraceID{Low: 1}, 1)},
+		Logs:       []model.Log{{}},
+		Tags:       []model.KeyValue{model.String("k", "v")},
+	}
+	paths := []string{
+		"",
+		"unknown",
+		"duration.seconds",
+		"duration[0]",
+		"tags",
+		"tags.",
+		"tags.missing",
+		"tags[0].k",
+		"process.serviceName",
+		"references",
+		"references[1].spanID",
+		"references[0]",
+		"references[0].unknown",
+		"references[-1].spanID",
+		"references[x].spanID",
+		"references[0.spanID",
+		"logs[0].fields.missing",
+		"logs[0].unknown",
+		"warnings[0]",
+	}
+	for _, path := range paths {
+		value, ok := span.Get(path)
+		assert.False(t, ok, path)
+		assert.Nil(t, value, path)
+	}
+
+	span.Process = model.NewProcess("svc", nil)
+	for _, path := range []string{"process", "process.tags.missing", "process.unknown"} {
+		_, ok := span.Get(path)
+		assert.False(t, ok, path)
+	}
+}
@@ -16,13 +16,19 @@ package model_test
 
 import (
 	"bytes"
+	"encoding/gob"
 	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 
 	"github.com/jaegertracing/jaeger/model"
 )
@@ -84,10 +90,89 @@ func TestTraceIDUnmarshalText(t *testing.T) {
 	}
 }
 
+func TestIDsJSONRoundTrip(t *testing.T) {
+	type ids struct {
+		TraceID model.TraceID `json:"traceID"`
+		SpanID  model.SpanID  `json:"spanID"`
+	}
+	testCases := []ids{
+		{TraceID: model.TraceID{Low: 1}, SpanID: 1},
+		{TraceID: model.TraceID{High: 0xff, Low: 0xff}, SpanID: 0xffffffffffffffff},
+		{TraceID: model.TraceID{High: 0xffffffffffffffff, Low: 0}, SpanID: 0x10},
+	}
+	for _, testCase := range testCases {
+		out, err := json.Marshal(testCase)
+		require.NoError(t, err)
+		var actual ids
+		require.NoError(t, json.Unmarshal(out, &actual), string(out))
+		assert.Equal(t, testCase, actual, string(out))
+	}
+
+	var padded ids
+	require.NoError(t, json.Unmarshal([]byte(`{"traceID":"00000000000000010000000000000002","spanID":"0000000000000003"}`), &padded))
+	assert.Equal(t, ids{TraceID: model.TraceID{High: 1, Low: 2}, SpanID: 3}, padded, "zero-padded IDs are accepted")
+}
+
+func TestTraceIDFromStringLenient(t *testing.T) {
+	testCases := []struct {
+		in  string
+		out model.TraceID
+	}{
+		{in: "1", out: model.TraceID{Low: 1}},
+		{in: "abc", out: model.TraceID{Low: 0xabc}},
+		{in: " 0xABC\n", out: model.TraceID{Low: 0xabc}},
+		{in: "0X12345678901234567", out: model.TraceID{High: 1, Low: 0x2345678901234567}},
+		{in: "00000000000000010000000000000002", out: model.TraceID{High: 1, Low: 2}},
+		{in: "0000000000000000000000000000000000000002", out: model.TraceID{Low: 2}},
+		{in: "0", out: model.TraceID{}},
+		{in: "0000000000000000000000000000000000000000", out: model.TraceID{}},
+		{in: "463ac35c-9f64-13ad-4848-5a3953bb6124", out: model.TraceID{High: 0x463ac35c9f6413ad, Low: 0x48485a3953bb6124}},
+		{in: "1-5759e988-bd862e3fe1be46a994272793", out: model.TraceID{High: 0x5759e988bd862e3f, Low: 0xe1be46a994272793}},
+	}
+	for _, testCase := range testCases {
+		id, err := model.TraceIDFromStringLenient(testCase.in)
+		if assert.NoError(t, err, testCase.in) {
+			assert.Equal(t, testCase.out, id, testCase.in)
+		}
+	}
+
+	for _, in := range []string{"", "0x", "xyz", "-1", "0x-1", "+1", "1-5759e988-bd862e3f", "123456789012345678901234567890123"} {
+		_, err := model.TraceIDFromStringLenient(in)
+		assert.Error(t, err, in)
+	}
+	_, err := model.TraceIDFromStringLenient("xyz")
+	assert.EqualError(t, err, `cannot parse TraceID from "xyz"`)
+}
+
+func TestTraceIDToXRayString(t *testing.T) {
+	id := model.TraceID{High: 0x5759e988bd862e3f, Low: 0xe1be46a994272793}
+	assert.Equal(t, "1-5759e988-bd862e3fe1be46a994272793", id.ToXRayString())
+	assert.Equal(t, "1-00000000-000000000000000000000001", model.TraceID{Low: 1}.ToXRayString())
+	parsed, err := model.TraceIDFromStringLenient(id.ToXRayString())
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+}
+
 type SpanIDContainer struct {
 	SpanID model.SpanID `json:"id"`
 }
 
+func TestTraceIDCompare(t *testing.T) {
+	testCases := []struct {
+		a, b     model.TraceID
+		expected int
+	}{
+		{a: model.TraceID{Low: 1}, b: model.TraceID{Low: 1}, expected: 0},
+		{a: model.TraceID{Low: 1}, b: model.TraceID{Low: 2}, expected: -1},
+		{a: model.TraceID{Low: 2}, b: model.TraceID{Low: 1}, expected: 1},
+		{a: model.TraceID{High: 1}, b: model.TraceID{Low: 0xffffffffffffffff}, expected: 1},
+		{a: model.TraceID{High: 1, Low: 5}, b: model.TraceID{High: 2, Low: 1}, expected: -1},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, testCase.a.Compare(testCase.b), "%v vs %v", testCase.a, testCase.b)
+	}
+}
+
 func TestSpanIDMarshalText(t *testing.T) {
 	max := int64(-1)
 	testCases := []struct {
@@ -137,6 +222,101 @@ func TestSpanIDUnmarshalText(t *testing.T) {
 	}
 }
 
+type yamlIDContainer struct {
+	TraceID model.TraceID `yaml:"traceID"`
+	SpanID  model.SpanID  `yaml:"spanID"`
+}
+
+func TestSpanIDBase62(t *testing.T) {
+	testCases := []struct {
+		id  model.SpanID
+		str string
+	}{
+		{id: 0, str: "0"},
+		{id: 9, str: "9"},
+		{id: 10, str: "A"},
+		{id: 61, str: "z"},
+		{id: 62, str: "10"},
+		{id: 0xffffffffffffffff, str: "LygHa16AHYF"},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.str, testCase.id.Base62())
+		id, err := model.SpanIDFromBase62(testCase.str)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.id, id)
+	}
+	for _, id := range []model.SpanID{1, 123456789, 1 << 63, 0xfedcba9876543210} {
+		parsed, err := model.SpanIDFromBase62(id.Base62())
+		require.NoError(t, err)
+		assert.Equal(t, id, parsed)
+	}
+}
+
+func TestSpanIDFromBase62Errors(t *testing.T) {
+	testCases := []struct {
+		str string
+		err string
+	}{
+		{str: "", err: "cannot parse empty base62 SpanID"},
+		{str: "ab-c", err: `invalid character '-' in base62 SpanID: ab-c`},
+		{str: "LygHa16AHYG", err: "base62 SpanID is out of range: LygHa16AHYG"},
+		{str: "100000000000", err: "base62 SpanID is out of range: 100000000000"},
+	}
+	for _, testCase := range testCases {
+		_, err := model.SpanIDFromBase62(testCase.str)
+		assert.EqualError(t, err, testCase.err)
+	}
+}
+
+func TestIDsMarshalYAML(t *testing.T) {
+	c := yamlIDContainer{
+		TraceID: model.TraceID{High: 1, Low: 0xf},
+		SpanID:  model.SpanID(0x1f),
+	}
+	out, err := yaml.Marshal(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "traceID: 0000000000000001000000000000000f\nspanID: 1f\n", string(out))
+
+	for _, c := range []yamlIDContainer{
+		c,
+		{TraceID: model.TraceID{Low: 0x123}, SpanID: model.SpanID(0x10)}, // hex IDs that look like numbers
+	} {
+		out, err := yaml.Marshal(&c)
+		require.NoError(t, err)
+		var c2 yamlIDContainer
+		require.NoError(t, yaml.Unmarshal(out, &c2))
+		assert.Equal(t, c, c2)
+	}
+}
+
+func TestIDsUnmarshalYAML(t *testing.T) {
+	testCases := []struct {
+		in      string
+		traceID model.TraceID
+		spanID  model.SpanID
+		err     bool
+	}{
+		{in: `{traceID: "1f", spanID: "f"}`, traceID: model.TraceID{Low: 0x1f}, spanID: 0xf},
+		{in: `{traceID: "00000000000000000000000000000001"}`, traceID: model.TraceID{Low: 1}},
+		{in: `{traceID: "10000000000000001"}`, traceID: model.TraceID{High: 1, Low: 1}},
+		{in: `{spanID: "ffffffffffffffff"}`, spanID: model.SpanID(0xffffffffffffffff)},
+		{in: `{traceID: "x"}`, err: true},
+		{in: `{traceID: [1]}`, err: true},
+		{in: `{spanID: "10123456789abcdef"}`, err: true},
+		{in: `{spanID: {a: b}}`, err: true},
+	}
+	for _, testCase := range testCases {
+		var c yamlIDContainer
+		err := yaml.Unmarshal([]byte(testCase.in), &c)
+		if testCase.err {
+			assert.Error(t, err, testCase.in)
+		} else if assert.NoError(t, err, testCase.in) {
+			assert.Equal(t, testCase.traceID, c.TraceID)
+			assert.Equal(t, testCase.spanID, c.SpanID)
+		}
+	}
+}
+
 func TestIsRPCClientServer(t *testing.T) {
 	span1 := &model.Span{
 		Tags: model.KeyValues{
@@ -150,6 +330,219 @@ func TestIsRPCClientServer(t *testing.T) {
 	assert.False(t, span2.IsRPCServer())
 }
 
+func TestSpanPeerService(t *testing.T) {
+	testCases := []struct {
+		tags    []model.KeyValue
+		service string
+		ok      bool
+	}{
+		{tags: nil},
+		{tags: []model.KeyValue{model.String("peer.service", "")}},
+		{
+			tags:    []model.KeyValue{model.String("net.peer.name", "db.local"), model.String("peer.service", "mysql")},
+			service: "mysql",
+			ok:      true,
+		},
+		{
+			tags:    []model.KeyValue{model.String("net.peer.name", "db.local"), model.String("peer.hostname", "db1")},
+			service: "db1",
+			ok:      true,
+		},
+		{tags: []model.KeyValue{model.String("net.peer.name", "db.local")}, service: "db.local", ok: true},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{Tags: testCase.tags}
+		service, ok := span.PeerService()
+		assert.Equal(t, testCase.ok, ok)
+		assert.Equal(t, testCase.service, service)
+	}
+}
+
+func TestSpanOperationKey(t *testing.T) {
+	span := &model.Span{OperationName: "GET /", Process: model.NewProcess("frontend", nil)}
+	assert.Equal(t, "frontend:GET /", span.OperationKey())
+	assert.Equal(t, ":op", (&model.Span{OperationName: "op"}).OperationKey())
+}
+
+func TestSpanServiceConsistent(t *testing.T) {
+	testCases := []struct {
+		process    *model.Process
+		tags       []model.KeyValue
+		consistent bool
+	}{
+		{process: model.NewProcess("frontend", nil), consistent: true},
+		{process: model.NewProcess("frontend", nil), tags: []model.KeyValue{model.String("service.name", "frontend")}, consistent: true},
+		{process: model.NewProcess("frontend", nil), tags: []model.KeyValue{model.String("service.name", "backend")}},
+		{
+			process: model.NewProcess("frontend", nil),
+			tags:    []model.KeyValue{model.String("service.name", "frontend"), model.String("service.name", "backend")},
+		},
+		{tags: []model.KeyValue{model.String("service.name", "backend")}, consistent: true},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{Process: testCase.process, Tags: testCase.tags}
+		assert.Equal(t, testCase.consistent, span.ServiceConsistent(), "%+v", testCase.tags)
+	}
+}
+
+func TestSpanMessagingInfo(t *testing.T) {
+	testCases := []struct {
+		tags                           []model.KeyValue
+		system, destination, operation string
+		ok                             bool
+	}{
+		{
+			tags: []model.KeyValue{
+				model.String("messaging.system", "kafka"),
+				model.String("messaging.destination.name", "orders"),
+				model.String("messaging.destination", "legacy"),
+				model.String("messaging.operation.type", "publish"),
+				model.String("messaging.operation", "send"),
+			},
+			system: "kafka", destination: "orders", operation: "publish", ok: true,
+		},
+		{
+			tags: []model.KeyValue{
+				model.String("messaging.system", "aws_sqs"),
+				model.String("messaging.destination", "jobs"),
+				model.String("messaging.operation", "receive"),
+			},
+			system: "aws_sqs", destination: "jobs", operation: "receive", ok: true,
+		},
+		{tags: []model.KeyValue{model.String("messaging.system", "rabbitmq")}, system: "rabbitmq", ok: true},
+		{tags: []model.KeyValue{model.String("messaging.destination", "jobs")}},
+		{},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{Tags: testCase.tags}
+		system, destination, operation, ok := span.MessagingInfo()
+		assert.Equal(t, testCase.ok, ok, "%+v", testCase.tags)
+		assert.Equal(t, testCase.system, system)
+		assert.Equal(t, testCase.destination, destination)
+		assert.Equal(t, testCase.operation, operation)
+	}
+}
+
+func TestSpanInferOperationName(t *testing.T) {
+	testCases := []struct {
+		operation string
+		tags      []model.KeyValue
+		expected  string
+	}{
+		{operation: "op", tags: []model.KeyValue{model.String("rpc.method", "Get")}, expected: "op"},
+		{
+			tags:     []model.KeyValue{model.String("http.method", "GET"), model.String("http.route", "/users/{id}")},
+			expected: "GET /users/{id}",
+		},
+		{
+			tags:     []model.KeyValue{model.String("http.method", "GET"), model.String("rpc.method", "Get")},
+			expected: "Get",
+		},
+		{
+			tags:     []model.KeyValue{model.String("db.operation", "SELECT"), model.String("db.system", "postgresql")},
+			expected: "postgresql SELECT",
+		},
+		{tags: []model.KeyValue{model.String("rpc.method", "Get")}, expected: "Get"},
+		{tags: []model.KeyValue{model.String("db.system", "redis")}, expected: model.UnknownOperationName},
+		{expected: model.UnknownOperationName},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{OperationName: testCase.operation, Tags: testCase.tags}
+		assert.Equal(t, testCase.expected, span.InferOperationName(), "%+v", testCase.tags)
+	}
+	assert.Equal(t, "", model.OperationNameFromTags(nil))
+}
+
+func TestSpanIsError(t *testing.T) {
+	testCases := []struct {
+		tags    []model.KeyValue
+		isError bool
+	}{
+		{tags: []model.KeyValue{model.Bool("error", true)}, isError: true},
+		{tags: []model.KeyValue{model.Bool("error", false)}},
+		{tags: []model.KeyValue{model.String("error", "true")}, isError: true},
+		{tags: []model.KeyValue{model.Int64("error", 1)}, isError: true},
+		{tags: []model.KeyValue{model.String("error", "timeout")}},
+		{tags: []model.KeyValue{model.Bool("is.error", true)}},
+		{},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{Tags: testCase.tags}
+		assert.Equal(t, testCase.isError, span.IsError(), "%+v", testCase.tags)
+	}
+}
+
+func TestSpanAddDerivedTags(t *testing.T) {
+	span := &model.Span{
+		Duration: 1500 * time.Microsecond,
+		Process:  model.NewProcess("frontend", nil),
+		Tags: []model.KeyValue{
+			model.Bool("error", true),
+			model.String("service.name", "stale"),
+		},
+	}
+	span.AddDerivedTags()
+	span.AddDerivedTags()
+	assert.Equal(t, []model.KeyValue{
+		model.Bool("error", true),
+		model.String("service.name", "stale"),
+		model.Float64("duration.ms", 1.5),
+		model.Bool("is.error", true),
+	}, span.Tags)
+	assert.False(t, span.ServiceConsistent())
+
+	span = &model.Span{Process: model.NewProcess("frontend", nil)}
+	span.AddDerivedTags()
+	span.AddDerivedTags()
+	assert.Equal(t, []model.KeyValue{
+		model.String("service.name", "frontend"),
+		model.Float64("duration.ms", 0),
+		model.Bool("is.error", false),
+	}, span.Tags, "duration.ms and is.error are replaced, service.name is kept")
+	assert.True(t, span.ServiceConsistent())
+
+	span = &model.Span{}
+	span.AddDerivedTags()
+	assert.Equal(t, []model.KeyValue{model.Float64("duration.ms", 0), model.Bool("is.error", false)}, span.Tags)
+}
+
+func TestSpanSamplingPriority(t *testing.T) {
+	testCases := []struct {
+		tag      model.KeyValue
+		priority int64
+		ok       bool
+	}{
+		{tag: model.Int64("sampling.priority", 1), priority: 1, ok: true},
+		{tag: model.Int64("sampling.priority", 0), priority: 0, ok: true},
+		{tag: model.Float64("sampling.priority", 2), priority: 2, ok: true},
+		{tag: model.Float64("sampling.priority", 1.5)},
+		{tag: model.String("sampling.priority", " 3 "), priority: 3, ok: true},
+		{tag: model.String("sampling.priority", "high")},
+		{tag: model.Bool("sampling.priority", true)},
+		{tag: model.Int64("other", 1)},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{Tags: []model.KeyValue{testCase.tag}}
+		priority, ok := span.SamplingPriority()
+		assert.Equal(t, testCase.ok, ok, "%+v", testCase.tag)
+		assert.Equal(t, testCase.priority, priority, "%+v", testCase.tag)
+	}
+}
+
+func TestSpanSetSamplingPriority(t *testing.T) {
+	span := &model.Span{
+		Tags: []model.KeyValue{
+			model.String("sampling.priority", "1"),
+			model.String("x", "y"),
+		},
+	}
+	span.SetSamplingPriority(0)
+	assert.Equal(t, []model.KeyValue{model.String("x", "y"), model.Int64("sampling.priority", 0)}, span.Tags)
+	priority, ok := span.SamplingPriority()
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), priority)
+}
+
 func TestIsDebug(t *testing.T) {
 	flags := model.Flags(0)
 	flags.SetDebug()
@@ -172,6 +565,21 @@ func TestIsSampled(t *testing.T) {
 	assert.False(t, flags.IsSampled())
 }
 
+func TestFlags(t *testing.T) {
+	var flags model.Flags
+	flags.SetFlag(model.FirehoseFlag | model.DebugFlag)
+	assert.True(t, flags.HasFlag(model.FirehoseFlag))
+	assert.True(t, flags.IsDebug())
+	assert.False(t, flags.HasFlag(model.FirehoseFlag|model.SampledFlag), "all bits of the mask must be set")
+	assert.True(t, flags.HasFlag(0))
+
+	flags.SetSampled()
+	flags.ClearFlag(model.DebugFlag | model.SampledFlag)
+	assert.Equal(t, model.FirehoseFlag, flags)
+	flags.ClearFlag(model.DebugFlag)
+	assert.Equal(t, model.FirehoseFlag, flags, "clearing an unset bit is a no-op")
+}
+
 func TestSpanHash(t *testing.T) {
 	kvs := model.KeyValues{
 		model.String("x", "y"),
@@ -191,6 +599,174 @@ func TestSpanHash(t *testing.T) {
 	assert.NotEqual(t, codes[0], codes[2])
 }
 
+func TestSpanHashCoversAllFields(t *testing.T) {
+	hashOf := func(span *model.Span) uint64 {
+		hc, err := model.HashCode(span)
+		require.NoError(t, err)
+		return hc
+	}
+	hash := hashOf(makeSpan(model.String("k", "v")))
+
+	// one modification per field of the span, to fail when a field is added but not hashed
+	modifications := map[string][]func(*model.Span){
+		"TraceID": {
+			func(s *model.Span) { s.TraceID.High++ },
+			func(s *model.Span) { s.TraceID.Low++ },
+		},
+		"SpanID":        {func(s *model.Span) { s.SpanID++ }},
+		"OperationName": {func(s *model.Span) { s.OperationName += "x" }},
+		"References": {
+			func(s *model.Span) { s.References = nil },
+			func(s *model.Span) { s.References[0].RefType = model.FollowsFrom },
+			func(s *model.Span) { s.References[0].TraceID.High++ },
+			func(s *model.Span) { s.References[0].TraceID.Low++ },
+			func(s *model.Span) { s.References[0].SpanID++ },
+			func(s *model.Span) { s.References[0].Tags = []model.KeyValue{model.String("k", "v")} },
+		},
+		"Flags":     {func(s *model.Span) { s.Flags.SetDebug() }},
+		"StartTime": {func(s *model.Span) { s.StartTime = s.StartTime.Add(1) }},
+		"Duration":  {func(s *model.Span) { s.Duration++ }},
+		"Tags": {
+			func(s *model.Span) { s.Tags = nil },
+			func(s *model.Span) { s.Tags[0].Key = "x" },
+			func(s *model.Span) { s.Tags[0].VStr = "w" },
+			func(s *model.Span) { s.Tags[0] = model.Bool("k", true) },
+			func(s *model.Span) { s.Tags[0] = model.Binary("k", []byte("v")) },
+			func(s *model.Span) { s.Tags = append(s.Tags, model.Int64("i", 1)) },
+		},
+		"Logs": {
+			func(s *model.Span) { s.Logs = nil },
+			func(s *model.Span) { s.Logs[0].Timestamp = s.Logs[0].Timestamp.Add(1) },
+			func(s *model.Span) { s.Logs[0].Fields[0].VStr = "w" },
+		},
+		"Process": {
+			func(s *model.Span) { s.Process = nil },
+			func(s *model.Span) { s.Process.ServiceName = "abc" },
+			func(s *model.Span) { s.Process.Tags = nil },
+		},
+		"Warnings": {func(s *model.Span) { s.Warnings = []string{"w"} }},
+	}
+	spanType := reflect.TypeOf(model.Span{})
+	assert.Len(t, modifications, spanType.NumField())
+	for i := 0; i < spanType.NumField(); i++ {
+		field := spanType.Field(i).Name
+		assert.NotEmpty(t, modifications[field], "no modification for field %s", field)
+		for j, modify := range modifications[field] {
+			modified := makeSpan(model.String("k", "v"))
+			modify(modified)
+			assert.NotEqual(t, hash, hashOf(modified), "modification %d of field %s", j, field)
+		}
+	}
+
+	// length prefixes keep adjacent strings from running into each other
+	a, b := makeSpan(model.String("k", "v")), makeSpan(model.String("k", "v"))
+	a.Warnings, b.Warnings = []string{"ab", "c"}, []string{"a", "bc"}
+	assert.NotEqual(t, hashOf(a), hashOf(b))
+
+	// the same instant in another location has the same hash, as with Span.Equal
+	relocated := makeSpan(model.String("k", "v"))
+	relocated.StartTime = relocated.StartTime.In(time.FixedZone("UTC+1", 3600))
+	assert.Equal(t, hash, hashOf(relocated))
+}
+
+func TestSpanHashUnknownValueType(t *testing.T) {
+	span := makeSpan(model.KeyValue{Key: "k", VType: model.ValueType(-1)})
+	_, err := model.HashCode(span)
+	assert.EqualError(t, err, "unknown type -1")
+}
+
+func TestSpanHashWriteError(t *testing.T) {
+	span := makeSpan(model.String("k", "v"))
+	w := &mockHashWwiter{answers: []mockHashWwiterAnswer{{1, nil}, {1, nil}, {1, errors.New("write failed")}}}
+	assert.EqualError(t, span.Hash(w), "write failed")
+}
+
+func TestSpanHashIgnoringTime(t *testing.T) {
+	span := makeSpan(model.String("k", "v"))
+	span.Logs = []model.Log{{Timestamp: span.StartTime, Fields: []model.KeyValue{model.String("event", "x")}}}
+	hash := span.HashIgnoringTime()
+
+	restamped := makeSpan(model.String("k", "v"))
+	restamped.StartTime = restamped.StartTime.Add(time.Hour)
+	restamped.Duration *= 2
+	restamped.Logs = []model.Log{{Timestamp: span.StartTime.Add(time.Minute), Fields: []model.KeyValue{model.String("event", "x")}}}
+	assert.Equal(t, hash, restamped.HashIgnoringTime())
+
+	modifications := []func(*model.Span){
+		func(s *model.Span) { s.SpanID++ },
+		func(s *model.Span) { s.TraceID.High++ },
+		func(s *model.Span) { s.OperationName += "x" },
+		func(s *model.Span) { s.Tags[0].VStr = "w" },
+		func(s *model.Span) { s.Logs[0].Fields[0].VStr = "y" },
+		func(s *model.Span) { s.Logs = append(s.Logs, model.Log{}) },
+		func(s *model.Span) { s.References = nil },
+		func(s *model.Span) { s.References[0].RefType = model.FollowsFrom },
+	}
+	for i, modify := range modifications {
+		modified := makeSpan(model.String("k", "v"))
+		modified.Logs = []model.Log{{Fields: []model.KeyValue{model.String("event", "x")}}}
+		modify(modified)
+		assert.NotEqual(t, hash, modified.HashIgnoringTime(), "modification %d", i)
+	}
+}
+
+func TestSpanCacheKey(t *testing.T) {
+	span := &model.Span{TraceID: model.TraceID{High: 0x0102, Low: 0x0304}, SpanID: model.SpanID(0x05)}
+	assert.Equal(t, [24]byte{
+		0, 0, 0, 0, 0, 0, 1, 2,
+		0, 0, 0, 0, 0, 0, 3, 4,
+		0, 0, 0, 0, 0, 0, 0, 5,
+	}, span.CacheKey())
+
+	other := &model.Span{TraceID: model.TraceID{High: 0x0304, Low: 0x0102}, SpanID: model.SpanID(0x05)}
+	cache := map[[24]byte]*model.Span{span.CacheKey(): span, other.CacheKey(): other}
+	assert.Len(t, cache, 2)
+	assert.Equal(t, span, cache[span.CacheKey()])
+}
+
+func TestSpanEstimateSize(t *testing.T) {
+	span := &model.Span{}
+	assert.Equal(t, 44, span.EstimateSize())
+
+	span = &model.Span{
+		OperationName: "op",                                                     // 2
+		References:    []model.SpanRef{model.NewChildOfRef(model.TraceID{}, 1)}, // 28
+		Tags:          []model.KeyValue{model.String("k", "value")},             // 6
+		Logs: []model.Log{
+			{Fields: []model.KeyValue{model.Int64("n", 1)}}, // 8 + 9
+		},
+		Process:  model.NewProcess("svc", []model.KeyValue{model.Bool("b", true)}), // 3 + 9
+		Warnings: []string{"warn"},                                                 // 4
+	}
+	assert.Equal(t, 44+2+28+6+17+12+4, span.EstimateSize())
+}
+
+func TestSpanSize(t *testing.T) {
+	spanSize := int(unsafe.Sizeof(model.Span{}))
+	kvSize := int(unsafe.Sizeof(model.KeyValue{}))
+	span := &model.Span{}
+	assert.Equal(t, spanSize, span.Size())
+
+	span = &model.Span{
+		OperationName: "op",
+		References:    make([]model.SpanRef, 1, 2),
+		Tags:          []model.KeyValue{model.String("k", "value"), model.Binary("b", []byte{1, 2})},
+		Logs: []model.Log{
+			{Fields: []model.KeyValue{model.Int64("n", 1)}},
+		},
+		Process:  model.NewProcess("svc", []model.KeyValue{model.Bool("b", true)}),
+		Warnings: []string{"warn"},
+	}
+	expected := spanSize + 2 +
+		2*int(unsafe.Sizeof(model.SpanRef{})) +
+		2*kvSize + 6 + 3 +
+		int(unsafe.Sizeof(model.Log{})) + kvSize + 1 +
+		int(unsafe.Sizeof(model.Process{})) + 3 + kvSize + 1 +
+		int(unsafe.Sizeof("")) + 4
+	assert.Equal(t, expected, span.Size())
+	assert.NotEqual(t, span.EstimateSize(), span.Size())
+}
+
 func TestParentSpanID(t *testing.T) {
 	span := makeSpan(model.String("k", "v"))
 	assert.Equal(t, model.SpanID(123), span.ParentSpanID())
@@ -207,6 +783,51 @@ func TestParentSpanID(t *testing.T) {
 	assert.Equal(t, model.SpanID(0), span.ParentSpanID())
 }
 
+func TestSpanEnsureSingleParent(t *testing.T) {
+	traceID := model.TraceID{Low: 1}
+	otherTraceID := model.TraceID{Low: 2}
+	span := &model.Span{
+		TraceID: traceID,
+		References: []model.SpanRef{
+			model.NewChildOfRef(otherTraceID, 7),
+			model.NewFollowsFromRef(traceID, 1),
+			model.NewChildOfRef(traceID, 2),
+			model.NewChildOfRef(traceID, 3),
+			model.NewChildOfRef(traceID, 4),
+		},
+	}
+	assert.True(t, span.EnsureSingleParent())
+	assert.Equal(t, []model.SpanRef{
+		model.NewChildOfRef(otherTraceID, 7),
+		model.NewFollowsFromRef(traceID, 1),
+		model.NewChildOfRef(traceID, 2),
+		model.NewFollowsFromRef(traceID, 3),
+		model.NewFollowsFromRef(traceID, 4),
+	}, span.References)
+	assert.Equal(t, model.SpanID(2), span.ParentSpanID())
+	assert.Equal(t, []string{"span has multiple parents, changed 2 ChildOf references to FollowsFrom"}, span.Warnings)
+
+	assert.False(t, span.EnsureSingleParent())
+	assert.Len(t, span.Warnings, 1)
+}
+
+func TestSpanReferenceCounts(t *testing.T) {
+	traceID := model.TraceID{Low: 1}
+	span := &model.Span{
+		References: []model.SpanRef{
+			model.NewChildOfRef(traceID, 1),
+			model.NewFollowsFromRef(traceID, 2),
+			model.NewFollowsFromRef(model.TraceID{Low: 2}, 3),
+			{RefType: model.SpanRefType(7), TraceID: traceID, SpanID: 4},
+		},
+	}
+	childOf, followsFrom, other := span.ReferenceCounts()
+	assert.Equal(t, []int{1, 2, 1}, []int{childOf, followsFrom, other})
+
+	childOf, followsFrom, other = (&model.Span{}).ReferenceCounts()
+	assert.Equal(t, []int{0, 0, 0}, []int{childOf, followsFrom, other})
+}
+
 func TestReplaceParentSpanID(t *testing.T) {
 	span := makeSpan(model.String("k", "v"))
 	assert.Equal(t, model.SpanID(123), span.ParentSpanID())
@@ -221,6 +842,214 @@ func TestReplaceParentSpanID(t *testing.T) {
 	assert.Equal(t, model.SpanID(789), span.ParentSpanID())
 }
 
+func TestSpanTrimToErrorEssentials(t *testing.T) {
+	base := time.Unix(100, 0)
+	errorLog := model.Log{Timestamp: base, Fields: []model.KeyValue{model.String("event", "error")}}
+	infoLog := model.Log{Timestamp: base, Fields: []model.KeyValue{model.String("event", "cache miss")}}
+	span := &model.Span{
+		TraceID:    model.TraceID{Low: 1},
+		SpanID:     model.SpanID(2),
+		References: []model.SpanRef{model.NewChildOfRef(model.TraceID{Low: 1}, 1)},
+		StartTime:  base,
+		Duration:   time.Second,
+		Tags: []model.KeyValue{
+			model.Bool("error", true),
+			model.String("span.kind", "server"),
+			model.String("http.url", "/users"),
+			model.Int64("http.status_code", 500),
+			model.String("exception.type", "IOError"),
+			model.String("error.object", "disk full"),
+			model.String("user.id", "42"),
+		},
+		Logs:    []model.Log{infoLog, errorLog},
+		Process: model.NewProcess("svc", []model.KeyValue{model.String("hostname", "h1")}),
+	}
+	span.TrimToErrorEssentials()
+	assert.Equal(t, &model.Span{
+		TraceID:    model.TraceID{Low: 1},
+		SpanID:     model.SpanID(2),
+		References: []model.SpanRef{model.NewChildOfRef(model.TraceID{Low: 1}, 1)},
+		StartTime:  base,
+		Duration:   time.Second,
+		Tags: []model.KeyValue{
+			model.Bool("error", true),
+			model.String("span.kind", "server"),
+			model.Int64("http.status_code", 500),
+			model.String("exception.type", "IOError"),
+			model.String("error.object", "disk full"),
+		},
+		Logs:    []model.Log{errorLog},
+		Process: model.NewProcess("svc", []model.KeyValue{model.String("hostname", "h1")}),
+	}, span)
+
+	span = &model.Span{
+		Tags: []model.KeyValue{model.String("span.kind", "client"), model.String("user.id", "42")},
+		Logs: []model.Log{infoLog, errorLog},
+	}
+	span.TrimToErrorEssentials()
+	assert.Equal(t, []model.KeyValue{model.String("span.kind", "client"), model.String("user.id", "42")}, span.Tags)
+	assert.Nil(t, span.Logs)
+}
+
+func TestSpanRedactTag(t *testing.T) {
+	span := makeSpan(model.String("password", "secret"))
+	assert.Equal(t, 2, span.RedactTag("password"))
+	assert.Equal(t, model.RedactedValue, span.Tags[0].VStr)
+	assert.Equal(t, model.RedactedValue, span.Logs[0].Fields[0].VStr)
+	assert.Equal(t, "secret", span.Process.Tags[0].VStr, "process tags are not redacted")
+	assert.Equal(t, 0, span.RedactTag("missing"))
+}
+
+func TestSpanRedactValuesMatching(t *testing.T) {
+	email := regexp.MustCompile(`[\w.]+@[\w.]+`)
+	span := makeSpan(model.String("http.url", "/users?a=x@example.com&b=y@example.com"))
+	span.Tags = append(span.Tags, model.String("user", "$1"), model.Binary("blob", []byte("z@example.com")))
+	assert.Equal(t, 4, span.RedactValuesMatching(email, "$1"))
+	assert.Equal(t, "/users?a=$1&b=$1", span.Tags[0].VStr)
+	assert.Equal(t, "$1", span.Tags[1].VStr)
+	assert.Equal(t, []byte("z@example.com"), span.Tags[2].VBlob, "only string values are redacted")
+	assert.Equal(t, "/users?a=$1&b=$1", span.Logs[0].Fields[0].VStr)
+	assert.Equal(t, "/users?a=x@example.com&b=y@example.com", span.Process.Tags[0].VStr, "process tags are not redacted")
+	assert.Equal(t, 0, span.RedactValuesMatching(email, model.RedactedValue))
+}
+
+func TestSpanRemoveTag(t *testing.T) {
+	span := makeSpan(model.String("k", "v"))
+	span.Tags = append(span.Tags, model.Int64("x", 1), model.String("k", "v2"))
+	assert.Equal(t, 2, span.RemoveTag("k"))
+	assert.Equal(t, []model.KeyValue{model.Int64("x", 1)}, span.Tags)
+	assert.Equal(t, 0, span.RemoveTag("k"))
+}
+
+func TestSpanRemoveTagsByType(t *testing.T) {
+	span := &model.Span{
+		Tags: []model.KeyValue{
+			model.String("http.status_code", "200"),
+			model.Int64("http.status_code", 200),
+			model.String("http.method", "GET"),
+			model.Float64("http.status_code", 200),
+		},
+	}
+	assert.Equal(t, 2, span.RemoveTagsByType("http.status_code", model.Int64Type))
+	assert.Equal(t, []model.KeyValue{model.Int64("http.status_code", 200), model.String("http.method", "GET")}, span.Tags)
+	assert.Equal(t, 0, span.RemoveTagsByType("http.method", model.StringType))
+	assert.Equal(t, 0, span.RemoveTagsByType("missing", model.StringType))
+	assert.Len(t, span.Tags, 2)
+}
+
+func TestSpanNormalizeBoolTags(t *testing.T) {
+	span := &model.Span{
+		Tags: model.KeyValues{
+			model.String("error", "TRUE"),
+			model.String("feature.x", "no"),
+			model.Int64("feature.y", 1),
+			model.Bool("feature.z", false),
+			model.String("feature.w", "maybe"),
+			model.String("other", "yes"),
+		},
+	}
+	span.NormalizeBoolTags("error", "feature.x", "feature.y", "feature.z", "feature.w")
+	assert.Equal(t, []model.KeyValue{
+		model.Bool("error", true),
+		model.Bool("feature.x", false),
+		model.Bool("feature.y", true),
+		model.Bool("feature.z", false),
+		model.String("feature.w", "maybe"),
+		model.String("other", "yes"),
+	}, span.Tags)
+	assert.Equal(t, []string{"cannot normalize tag feature.w=maybe to bool"}, span.Warnings)
+}
+
+func TestSpanLogTimes(t *testing.T) {
+	span := &model.Span{}
+	assert.False(t, span.HasLogs())
+	_, ok := span.FirstLogTime()
+	assert.False(t, ok)
+	_, ok = span.LastLogTime()
+	assert.False(t, ok)
+
+	base := time.Unix(100, 0)
+	span.Logs = []model.Log{
+		{Timestamp: base.Add(2 * time.Second)},
+		{Timestamp: base},
+		{Timestamp: base.Add(5 * time.Second)},
+		{Timestamp: base.Add(time.Second)},
+	}
+	assert.True(t, span.HasLogs())
+	first, ok := span.FirstLogTime()
+	assert.True(t, ok)
+	assert.Equal(t, base, first)
+	last, ok := span.LastLogTime()
+	assert.True(t, ok)
+	assert.Equal(t, base.Add(5*time.Second), last)
+}
+
+func TestSpanRecomputeDurationFromLogs(t *testing.T) {
+	base := time.Unix(100, 0)
+	logs := []model.Log{
+		{Timestamp: base.Add(2 * time.Second)},
+		{Timestamp: base.Add(1500 * time.Millisecond)},
+	}
+	span := &model.Span{StartTime: base, Logs: logs}
+	assert.True(t, span.RecomputeDurationFromLogs())
+	assert.Equal(t, 2*time.Second, span.Duration)
+	assert.Equal(t, []string{"span duration is missing, recomputed as 2s from logs"}, span.Warnings)
+	assert.False(t, span.RecomputeDurationFromLogs(), "duration is already set")
+	assert.Len(t, span.Warnings, 1)
+
+	testCases := []*model.Span{
+		{StartTime: base},
+		{StartTime: base.Add(2 * time.Second), Logs: logs},
+		{StartTime: base, Duration: time.Second, Logs: logs},
+	}
+	for _, span := range testCases {
+		duration := span.Duration
+		assert.False(t, span.RecomputeDurationFromLogs())
+		assert.Equal(t, duration, span.Duration)
+		assert.Empty(t, span.Warnings)
+	}
+}
+
+func TestSpanPromoteLogField(t *testing.T) {
+	base := time.Unix(100, 0)
+	span := &model.Span{
+		Tags: []model.KeyValue{model.String("exception.type", "stale")},
+		Logs: []model.Log{
+			{Timestamp: base.Add(2 * time.Second), Fields: []model.KeyValue{model.String("exception.type", "IOError")}},
+			{Timestamp: base.Add(3 * time.Second), Fields: []model.KeyValue{model.String("event", "retry")}},
+			{Timestamp: base, Fields: []model.KeyValue{model.String("exception.type", "Timeout")}},
+			{Timestamp: base.Add(time.Second), Fields: []model.KeyValue{model.Int64("attempt", 1)}},
+		},
+	}
+	assert.True(t, span.PromoteLogField("exception.type", "exception.type"))
+	assert.True(t, span.PromoteLogField("attempt", "retry.attempt"))
+	assert.False(t, span.PromoteLogField("exception.message", "exception.message"))
+	assert.Equal(t, []model.KeyValue{
+		model.String("exception.type", "IOError"),
+		model.Int64("retry.attempt", 1),
+	}, span.Tags)
+}
+
+func TestSpanJSONRoundTrip(t *testing.T) {
+	span := makeSpan(model.Binary("blob", []byte{0, 1, 0xfe, 0xff}))
+	span.Flags.SetSampled()
+	span.Tags = append(span.Tags,
+		model.String("s", "str"),
+		model.Bool("b", true),
+		model.Int64("i", -1<<62),
+		model.Float64("f", 0.1),
+	)
+	span.Process.Tags = append(span.Process.Tags, model.Float64("p", 1e-300))
+	span.Warnings = []string{"w"}
+	span.NormalizeTimestamps()
+
+	data, err := json.Marshal(span)
+	require.NoError(t, err)
+	var out model.Span
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, span, &out)
+}
+
 func makeSpan(someKV model.KeyValue) *model.Span {
 	traceID := model.TraceID{Low: 123}
 	return &model.Span{
@@ -244,7 +1073,7 @@ func makeSpan(someKV model.KeyValue) *model.Span {
 	}
 }
 
-// BenchmarkSpanHash-8   	   50000	     26977 ns/op	    2203 B/op	      68 allocs/op
+// BenchmarkSpanHash-8   	 1862302	       650 ns/op	     112 B/op	       9 allocs/op
 func BenchmarkSpanHash(b *testing.B) {
 	span := makeSpan(model.String("x", "y"))
 	buf := &bytes.Buffer{}
@@ -253,3 +1082,33 @@ func BenchmarkSpanHash(b *testing.B) {
 		span.Hash(buf)
 	}
 }
+
+// BenchmarkSpanHashGob measures the gob encoding previously used by Span.Hash, for comparison.
+// BenchmarkSpanHashGob-8   	   77504	     15738 ns/op	    2712 B/op	      35 allocs/op
+func BenchmarkSpanHashGob(b *testing.B) {
+	span := makeSpan(model.String("x", "y"))
+	buf := &bytes.Buffer{}
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		gob.NewEncoder(buf).Encode(span)
+	}
+}
+
+// BenchmarkSpanCacheKey-8         	38476466	        32.4 ns/op	       0 B/op	       0 allocs/op
+func BenchmarkSpanCacheKey(b *testing.B) {
+	span := makeSpan(model.String("x", "y"))
+	cache := map[[24]byte]*model.Span{span.CacheKey(): span}
+	for i := 0; i < b.N; i++ {
+		_ = cache[span.CacheKey()]
+	}
+}
+
+// BenchmarkSpanStringCacheKey-8   	 4323294	       271 ns/op	      16 B/op	       3 allocs/op
+func BenchmarkSpanStringCacheKey(b *testing.B) {
+	span := makeSpan(model.String("x", "y"))
+	key := func(s *model.Span) string { return s.TraceID.String() + ":" + s.SpanID.String() }
+	cache := map[string]*model.Span{key(span): span}
+	for i := 0; i < b.N; i++ {
+		_ = cache[key(span)]
+	}
+}
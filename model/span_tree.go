@@ -0,0 +1,181 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// TreeNode is a node in the tree of spans of a trace, built from the references
+// between spans of the trace.
+type TreeNode struct {
+	Span *Span
+	// Parents are the nodes of the spans in the trace referenced by this span, e.g.
+	// a span that is a child of one span and follows from another has two parents.
+	Parents []*TreeNode
+	// Children are the nodes placed under this node in the tree. Each node is placed
+	// under a single parent, the span of its ParentSpanID if it is in the trace, or
+	// otherwise its first parent.
+	Children []*TreeNode
+}
+
+// BuildSpanTree builds the tree of spans of the trace, with the children of each span
+// in the order in which they appear in the trace. An error is returned unless the trace
+// has exactly one root and all spans are reachable from it, see BuildSpanForest.
+func (t *Trace) BuildSpanTree() (*TreeNode, error) {
+	return t.buildSpanTree(false)
+}
+
+// BuildSpanTreeSorted is like BuildSpanTree, but orders the children of each span
+// by start time, then by span ID, so that the resulting tree is fully reproducible.
+func (t *Trace) BuildSpanTreeSorted() (*TreeNode, error) {
+	return t.buildSpanTree(true)
+}
+
+func (t *Trace) buildSpanTree(sortChildren bool) (*TreeNode, error) {
+	if len(t.Spans) == 0 {
+		return nil, errors.New("cannot build span tree for a trace without spans")
+	}
+	roots, _, unreachable := t.buildSpanForest(sortChildren)
+	if len(roots) != 1 {
+		return nil, fmt.Errorf("trace has %d root spans, expected exactly one", len(roots))
+	}
+	if len(unreachable) > 0 {
+		return nil, fmt.Errorf("%d spans are not reachable from the root span", len(unreachable))
+	}
+	return roots[0], nil
+}
+
+// BuildSpanForest builds the trees of spans of the trace like BuildSpanTree, but accepts
+// any trace: it can have several roots, orphans, spans with several parents, and reference
+// cycles. It returns
+//
+//   - roots, the nodes without parents in the trace, in the order they appear in the trace;
+//   - orphans, the roots that reference spans of their trace that are not in the trace,
+//     e.g. because the parent span was lost or not yet received;
+//   - unreachable, the nodes that cannot be reached from any root because they are part
+//     of a reference cycle or descend from one. They have parents but no children.
+//
+// Spans with the same span ID are merged into one node, the first span with that ID in
+// the trace. References to spans of other traces, e.g. links, are ignored.
+func (t *Trace) BuildSpanForest() (roots, orphans, unreachable []*TreeNode) {
+	return t.buildSpanForest(false)
+}
+
+func (t *Trace) buildSpanForest(sortChildren bool) (roots, orphans, unreachable []*TreeNode) {
+	nodesByID := make(map[SpanID]*TreeNode, len(t.Spans))
+	var nodes []*TreeNode
+	for _, span := range t.Spans {
+		if _, ok := nodesByID[span.SpanID]; !ok {
+			node := &TreeNode{Span: span}
+			nodesByID[span.SpanID] = node
+			nodes = append(nodes, node)
+		}
+	}
+	children := make(map[*TreeNode][]*TreeNode, len(nodes))
+	for _, node := range nodes {
+		hasRefs := false
+		parentID := node.Span.ParentSpanID()
+		var treeParent *TreeNode
+		for _, ref := range node.Span.References {
+			if ref.TraceID != node.Span.TraceID {
+				continue
+			}
+			hasRefs = true
+			parent, ok := nodesByID[ref.SpanID]
+			if !ok || containsTreeNode(node.Parents, parent) {
+				continue
+			}
+			node.Parents = append(node.Parents, parent)
+			if ref.SpanID == parentID {
+				treeParent = parent
+			}
+		}
+		if len(node.Parents) == 0 {
+			roots = append(roots, node)
+			if hasRefs {
+				orphans = append(orphans, node)
+			}
+			continue
+		}
+		if treeParent == nil {
+			treeParent = node.Parents[0]
+		}
+		children[treeParent] = append(children[treeParent], node)
+	}
+	visited := make(map[*TreeNode]struct{}, len(nodes))
+	var build func(node *TreeNode)
+	build = func(node *TreeNode) {
+		visited[node] = struct{}{}
+		nodeChildren := children[node]
+		if sortChildren {
+			sort.Sort(treeNodesByStartTime(nodeChildren))
+		}
+		for _, child := range nodeChildren {
+			if _, ok := visited[child]; !ok {
+				node.Children = append(node.Children, child)
+				build(child)
+			}
+		}
+	}
+	for _, root := range roots {
+		build(root)
+	}
+	for _, node := range nodes {
+		if _, ok := visited[node]; !ok {
+			unreachable = append(unreachable, node)
+		}
+	}
+	return roots, orphans, unreachable
+}
+
+type treeNodesByStartTime []*TreeNode
+
+func (s treeNodesByStartTime) Len() int      { return len(s) }
+func (s treeNodesByStartTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s treeNodesByStartTime) Less(i, j int) bool {
+	a, b := s[i].Span, s[j].Span
+	if !a.StartTime.Equal(b.StartTime) {
+		return a.StartTime.Before(b.StartTime)
+	}
+	return a.SpanID < b.SpanID
+}
+
+// WalkDFS visits the nodes of the tree in depth-first pre-order, calling fn with
+// each node and its depth, starting with depth 0 for this node. If fn returns false,
+// the children of that node are skipped.
+func (n *TreeNode) WalkDFS(fn func(node *TreeNode, depth int) bool) {
+	n.walkDFS(fn, 0)
+}
+
+func (n *TreeNode) walkDFS(fn func(node *TreeNode, depth int) bool, depth int) {
+	if !fn(n, depth) {
+		return
+	}
+	for _, child := range n.Children {
+		child.walkDFS(fn, depth+1)
+	}
+}
+
+func containsTreeNode(nodes []*TreeNode, node *TreeNode) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
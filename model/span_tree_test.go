@@ -0,0 +1,188 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// dumpTree renders the tree as indented span IDs, one per line.
+func dumpTree(root *model.TreeNode) string {
+	var lines []string
+	root.WalkDFS(func(node *model.TreeNode, depth int) bool {
+		lines = append(lines, fmt.Sprintf("%s%v", strings.Repeat(" ", depth), node.Span.SpanID))
+		return true
+	})
+	return strings.Join(lines, "\n")
+}
+
+func makeTreeTrace() *model.Trace {
+	base := time.Unix(100, 0)
+	span := func(id, parent model.SpanID, start time.Duration) *model.Span {
+		s := makeTreeSpan(id, parent)
+		s.StartTime = base.Add(start)
+		return s
+	}
+	return &model.Trace{
+		Spans: []*model.Span{
+			span(1, 0, 0),
+			span(4, 1, 3*time.Millisecond),
+			span(3, 1, time.Millisecond),
+			span(2, 1, time.Millisecond),
+			span(5, 4, 4*time.Millisecond),
+		},
+	}
+}
+
+func TestTraceBuildSpanTree(t *testing.T) {
+	root, err := makeTreeTrace().BuildSpanTree()
+	require.NoError(t, err)
+	assert.Equal(t, "1\n 4\n  5\n 3\n 2", dumpTree(root))
+}
+
+func TestTraceBuildSpanTreeSorted(t *testing.T) {
+	trace := makeTreeTrace()
+	root, err := trace.BuildSpanTreeSorted()
+	require.NoError(t, err)
+	assert.Equal(t, "1\n 2\n 3\n 4\n  5", dumpTree(root))
+
+	// the order of spans in the trace must not matter
+	trace.Spans[0], trace.Spans[4] = trace.Spans[4], trace.Spans[0]
+	trace.Spans[1], trace.Spans[3] = trace.Spans[3], trace.Spans[1]
+	root2, err := trace.BuildSpanTreeSorted()
+	require.NoError(t, err)
+	assert.Equal(t, dumpTree(root), dumpTree(root2))
+}
+
+func TestTraceBuildSpanTreeErrors(t *testing.T) {
+	testCases := []struct {
+		trace *model.Trace
+		err   string
+	}{
+		{
+			trace: &model.Trace{},
+			err:   "cannot build span tree for a trace without spans",
+		},
+		{
+			trace: &model.Trace{Spans: []*model.Span{makeTreeSpan(1, 0), makeTreeSpan(2, 7)}},
+			err:   "trace has 2 root spans, expected exactly one",
+		},
+		{
+			trace: &model.Trace{Spans: []*model.Span{makeTreeSpan(1, 2), makeTreeSpan(2, 1)}},
+			err:   "trace has 0 root spans, expected exactly one",
+		},
+		{
+			trace: &model.Trace{Spans: []*model.Span{makeTreeSpan(1, 0), makeTreeSpan(2, 3), makeTreeSpan(3, 2)}},
+			err:   "2 spans are not reachable from the root span",
+		},
+	}
+	for _, testCase := range testCases {
+		_, err := testCase.trace.BuildSpanTree()
+		assert.EqualError(t, err, testCase.err)
+	}
+}
+
+func TestTreeNodeWalkDFSSkipChildren(t *testing.T) {
+	root, err := makeTreeTrace().BuildSpanTree()
+	require.NoError(t, err)
+	var visited []model.SpanID
+	root.WalkDFS(func(node *model.TreeNode, depth int) bool {
+		visited = append(visited, node.Span.SpanID)
+		return node.Span.SpanID != 4
+	})
+	assert.Equal(t, []model.SpanID{1, 4, 3, 2}, visited)
+}
+
+// dumpForest renders the trees of the roots as indented span IDs, one per line.
+func dumpForest(roots []*model.TreeNode) string {
+	var lines []string
+	for _, root := range roots {
+		lines = append(lines, dumpTree(root))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func treeNodeIDs(nodes []*model.TreeNode) []model.SpanID {
+	ids := make([]model.SpanID, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.Span.SpanID)
+	}
+	return ids
+}
+
+func findTreeNode(roots []*model.TreeNode, id model.SpanID) *model.TreeNode {
+	var found *model.TreeNode
+	for _, root := range roots {
+		root.WalkDFS(func(node *model.TreeNode, depth int) bool {
+			if node.Span.SpanID == id && found == nil {
+				found = node
+			}
+			return true
+		})
+	}
+	return found
+}
+
+func TestTraceBuildSpanForest(t *testing.T) {
+	traceID := model.TraceID{Low: 1}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(1, 0),
+			makeTreeSpan(2, 1),
+			makeTreeSpan(3, 1),
+			makeTreeSpan(4, 9), // orphan
+			makeTreeSpan(5, 4),
+			makeTreeSpan(6, 7), // cycle
+			makeTreeSpan(7, 6),
+			makeTreeSpan(8, 7), // below the cycle
+			makeTreeSpan(2, 3), // duplicate span ID
+			{TraceID: traceID, SpanID: 10, References: []model.SpanRef{
+				model.NewFollowsFromRef(model.TraceID{Low: 2}, 1), // link to another trace
+			}},
+		},
+	}
+	// span 3 also follows from span 2, and refers to span 1 twice
+	trace.Spans[2].References = append(trace.Spans[2].References,
+		model.NewFollowsFromRef(traceID, 2), model.NewFollowsFromRef(traceID, 1))
+
+	roots, orphans, unreachable := trace.BuildSpanForest()
+	assert.Equal(t, []model.SpanID{1, 4, 10}, treeNodeIDs(roots))
+	assert.Equal(t, []model.SpanID{4}, treeNodeIDs(orphans))
+	assert.Equal(t, []model.SpanID{6, 7, 8}, treeNodeIDs(unreachable))
+	assert.Equal(t, "1\n 2\n 3\n4\n 5\na", dumpForest(roots))
+
+	node := findTreeNode(roots, 3)
+	require.NotNil(t, node)
+	assert.Equal(t, []model.SpanID{1, 2}, treeNodeIDs(node.Parents), "span 3 is placed under its parent span 1")
+	assert.True(t, findTreeNode(roots, 2).Span == trace.Spans[1], "the first span with an ID is used")
+	assert.Equal(t, []model.SpanID{6}, treeNodeIDs(unreachable[1].Parents))
+	assert.Empty(t, unreachable[1].Children, "cycles are not followed")
+	assert.Nil(t, findTreeNode(roots, 9))
+}
+
+func TestTraceBuildSpanForestEmpty(t *testing.T) {
+	roots, orphans, unreachable := (&model.Trace{}).BuildSpanForest()
+	assert.Empty(t, roots)
+	assert.Empty(t, orphans)
+	assert.Empty(t, unreachable)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+type xraySegment struct {
+	Name        string                 `json:"name"`
+	ID          string                 `json:"id"`
+	TraceID     string                 `json:"trace_id"`
+	ParentID    string                 `json:"parent_id,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	StartTime   float64                `json:"start_time"`
+	EndTime     float64                `json:"end_time"`
+	Error       bool                   `json:"error,omitempty"`
+	Fault       bool                   `json:"fault,omitempty"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// ToXRaySegment returns the span as an AWS X-Ray segment document in JSON.
+//
+// The X-Ray trace ID is the trace ID in the form of ToXRayString, with its top 32 bits
+// replaced by traceIDEpoch in Unix seconds. traceIDEpoch must be the same for all spans
+// of a trace, e.g. the start of the root span. If traceIDEpoch is zero, the trace ID is
+// used as is, which suits trace IDs generated with IDGeneratorOptions.TimePrefixed.
+// Spans with `span.kind` client, producer, or without a kind but with a parent, become
+// subsegments named after the operation; other spans become segments named after the
+// service. Span tags are converted to annotations, replacing characters other than
+// letters, digits and '_' in the keys with '_'. Error spans (see IsError) are flagged
+// as `error` for HTTP 4xx status codes and as `fault` otherwise.
+func (s *Span) ToXRaySegment(traceIDEpoch time.Time) ([]byte, error) {
+	segment := xraySegment{
+		Name:      s.serviceName(),
+		ID:        fmt.Sprintf("%016x", uint64(s.SpanID)),
+		TraceID:   xrayTraceID(s.TraceID, traceIDEpoch).ToXRayString(),
+		StartTime: xrayTime(s.StartTime),
+		EndTime:   xrayTime(s.StartTime.Add(s.Duration)),
+	}
+	if parentID := s.ParentSpanID(); parentID != 0 {
+		segment.ParentID = fmt.Sprintf("%016x", uint64(parentID))
+	}
+	if s.isXRaySubsegment() {
+		segment.Type = "subsegment"
+		segment.Name = s.InferOperationName()
+	}
+	if segment.Name == "" {
+		segment.Name = UnknownOperationName
+	}
+	if s.IsError() {
+		status, _ := KeyValues(s.Tags).FindByKey(string(ext.HTTPStatusCode))
+		if code := status.Int64(); code >= 400 && code < 500 {
+			segment.Error = true
+		} else {
+			segment.Fault = true
+		}
+	}
+	if len(s.Tags) > 0 {
+		segment.Annotations = make(map[string]interface{}, len(s.Tags))
+		for i := range s.Tags {
+			segment.Annotations[xrayAnnotationKey(s.Tags[i].Key)] = templateValue(&s.Tags[i])
+		}
+	}
+	return json.Marshal(segment)
+}
+
+func (s *Span) isXRaySubsegment() bool {
+	tag, ok := KeyValues(s.Tags).FindByKey(string(ext.SpanKind))
+	if !ok {
+		return s.ParentSpanID() != 0
+	}
+	switch tag.AsString() {
+	case string(ext.SpanKindRPCClientEnum), string(ext.SpanKindProducerEnum):
+		return true
+	case string(ext.SpanKindRPCServerEnum), string(ext.SpanKindConsumerEnum):
+		return false
+	}
+	return s.ParentSpanID() != 0
+}
+
+// xrayTime returns the time in seconds since the Unix epoch, with microsecond precision.
+func xrayTime(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond()/1000)/1e6
+}
+
+func xrayAnnotationKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// xrayTraceID replaces the top 32 bits of the trace ID with the epoch, unless it is zero.
+func xrayTraceID(traceID TraceID, epoch time.Time) TraceID {
+	if epoch.IsZero() {
+		return traceID
+	}
+	traceID.High = uint64(uint32(epoch.Unix()))<<32 | uint64(uint32(traceID.High))
+	return traceID
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestSpanToXRaySegment(t *testing.T) {
+	traceID := model.TraceID{High: 0x1122334455667788, Low: 0x99aabbccddeeff00}
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.SpanID(0xab),
+		OperationName: "GET /users",
+		StartTime:     time.Unix(1500000000, 250000000),
+		Duration:      1500 * time.Millisecond,
+		Tags: []model.KeyValue{
+			model.String("span.kind", "server"),
+			model.String("http.method", "GET"),
+			model.Int64("http.status_code", 503),
+			model.Bool("error", true),
+		},
+		Process: model.NewProcess("frontend", nil),
+	}
+	segment, err := span.ToXRaySegment(time.Unix(0x5a000000, 0))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "frontend",
+		"id": "00000000000000ab",
+		"trace_id": "1-5a000000-5566778899aabbccddeeff00",
+		"start_time": 1500000000.25,
+		"end_time": 1500000001.75,
+		"fault": true,
+		"annotations": {
+			"span_kind": "server",
+			"http_method": "GET",
+			"http_status_code": 503,
+			"error": true
+		}
+	}`, string(segment))
+
+	segment, err = span.ToXRaySegment(time.Time{})
+	require.NoError(t, err)
+	assert.Contains(t, string(segment), `"trace_id":"`+traceID.ToXRayString()+`"`)
+}
+
+func TestSpanToXRaySubsegment(t *testing.T) {
+	traceID := model.TraceID{Low: 1}
+	testCases := []struct {
+		tags     []model.KeyValue
+		parentID model.SpanID
+		expected string
+	}{
+		{
+			tags: []model.KeyValue{model.String("span.kind", "client"), model.Int64("http.status_code", 404), model.Bool("error", true)},
+			expected: `"type": "subsegment", "name": "query", "error": true,
+				"annotations": {"span_kind": "client", "http_status_code": 404, "error": true}`,
+		},
+		{
+			parentID: 1,
+			expected: `"type": "subsegment", "name": "query", "parent_id": "0000000000000001"`,
+		},
+		{
+			tags:     []model.KeyValue{model.String("span.kind", "consumer")},
+			parentID: 1,
+			expected: `"name": "unknown", "parent_id": "0000000000000001", "annotations": {"span_kind": "consumer"}`,
+		},
+	}
+	for _, testCase := range testCases {
+		span := &model.Span{
+			TraceID:       traceID,
+			SpanID:        model.SpanID(2),
+			OperationName: "query",
+			StartTime:     time.Unix(10, 0),
+			Tags:          testCase.tags,
+			References:    model.MaybeAddParentSpanID(traceID, testCase.parentID, nil),
+		}
+		segment, err := span.ToXRaySegment(time.Unix(10, 0))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"id": "0000000000000002",
+			"trace_id": "1-0000000a-000000000000000000000001",
+			"start_time": 10,
+			"end_time": 10,
+			`+testCase.expected+`
+		}`, string(segment))
+	}
+}
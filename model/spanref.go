@@ -39,6 +39,8 @@ type SpanRef struct {
 	RefType SpanRefType `json:"refType"`
 	TraceID TraceID     `json:"traceID"`
 	SpanID  SpanID      `json:"spanID"`
+	// Tags are optional attributes of the reference, such as the attributes of OpenTelemetry links
+	Tags []KeyValue `json:"tags,omitempty"`
 }
 
 func (p SpanRefType) String() string {
@@ -105,6 +107,14 @@ func MaybeAddParentSpanID(traceID TraceID, parentSpanID SpanID, refs []SpanRef)
 	return newRefs
 }
 
+// Equal compares SpanRef object with another SpanRef, including the tags.
+func (r *SpanRef) Equal(other *SpanRef) bool {
+	return r.RefType == other.RefType &&
+		r.TraceID == other.TraceID &&
+		r.SpanID == other.SpanID &&
+		KeyValues(r.Tags).Equal(other.Tags)
+}
+
 // NewChildOfRef creates a new child-of span reference.
 func NewChildOfRef(traceID TraceID, spanID SpanID) SpanRef {
 	return SpanRef{
@@ -45,6 +45,31 @@ func TestSpanRefTypeToFromString(t *testing.T) {
 	}
 }
 
+func TestSpanRefEqual(t *testing.T) {
+	ref := model.SpanRef{
+		RefType: model.FollowsFrom,
+		TraceID: model.TraceID{Low: 1},
+		SpanID:  2,
+		Tags:    []model.KeyValue{model.String("link.reason", "batch")},
+	}
+	same := ref
+	same.Tags = []model.KeyValue{model.String("link.reason", "batch")}
+	assert.True(t, ref.Equal(&same))
+
+	testCases := []func(r *model.SpanRef){
+		func(r *model.SpanRef) { r.RefType = model.ChildOf },
+		func(r *model.SpanRef) { r.TraceID.High++ },
+		func(r *model.SpanRef) { r.SpanID++ },
+		func(r *model.SpanRef) { r.Tags = nil },
+		func(r *model.SpanRef) { r.Tags = []model.KeyValue{model.String("link.reason", "retry")} },
+	}
+	for i, modify := range testCases {
+		other := ref
+		modify(&other)
+		assert.False(t, ref.Equal(&other), "case %d", i)
+	}
+}
+
 func TestSpanRefTypeToFromJSON(t *testing.T) {
 	sr := model.SpanRef{
 		RefType: model.ChildOf,
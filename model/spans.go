@@ -0,0 +1,156 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GroupSpans groups spans by the key returned by keyFn.
+// Within each group the spans keep their original order.
+func GroupSpans(spans []*Span, keyFn func(*Span) string) map[string][]*Span {
+	groups := make(map[string][]*Span)
+	for _, span := range spans {
+		key := keyFn(span)
+		groups[key] = append(groups[key], span)
+	}
+	return groups
+}
+
+// DistinctOperations returns the number of spans of each operation in the batch,
+// keyed by Span.OperationKey so that equal operation names of different services
+// are counted separately.
+func DistinctOperations(spans []*Span) map[string]int {
+	counts := make(map[string]int)
+	for _, span := range spans {
+		counts[span.OperationKey()]++
+	}
+	return counts
+}
+
+// FilterByDurationRange returns the spans with duration in the inclusive range [min, max].
+// A zero max means that there is no upper bound.
+func FilterByDurationRange(spans []*Span, min, max time.Duration) []*Span {
+	var filtered []*Span
+	for _, span := range spans {
+		if span.Duration >= min && (max == 0 || span.Duration <= max) {
+			filtered = append(filtered, span)
+		}
+	}
+	return filtered
+}
+
+// PartitionSpansBySize splits spans into consecutive batches whose total size, as
+// estimated by Span.EstimateSize, does not exceed maxBytes. Spans are never split:
+// a span larger than maxBytes is placed in a batch of its own, and a warning is
+// added to it. A non-positive maxBytes puts all spans in a single batch.
+func PartitionSpansBySize(spans []*Span, maxBytes int) [][]*Span {
+	if len(spans) == 0 {
+		return nil
+	}
+	if maxBytes <= 0 {
+		return [][]*Span{spans}
+	}
+	var batches [][]*Span
+	var batch []*Span
+	batchSize := 0
+	for _, span := range spans {
+		size := span.EstimateSize()
+		if size > maxBytes {
+			if len(batch) > 0 {
+				batches = append(batches, batch)
+				batch, batchSize = nil, 0
+			}
+			span.Warnings = append(span.Warnings, fmt.Sprintf("span size %d bytes exceeds batch size limit %d bytes", size, maxBytes))
+			batches = append(batches, []*Span{span})
+			continue
+		}
+		if batchSize+size > maxBytes {
+			batches = append(batches, batch)
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, span)
+		batchSize += size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// TagSizeRecord describes the size of a single span tag, see LargestTagValues.
+type TagSizeRecord struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Key     string
+	// Size is the size of the tag in bytes, as returned by KeyValue.Size.
+	Size int
+}
+
+// LargestTagValues returns up to n of the largest span tags found in the spans,
+// ordered from the largest to the smallest. It keeps only n records in memory
+// instead of sorting all tags.
+func LargestTagValues(spans []*Span, n int) []TagSizeRecord {
+	if n <= 0 {
+		return nil
+	}
+	tagCount := 0
+	for _, span := range spans {
+		tagCount += len(span.Tags)
+	}
+	if n > tagCount {
+		n = tagCount
+	}
+	h := make(tagSizeHeap, 0, n)
+	for _, span := range spans {
+		for i := range span.Tags {
+			size := span.Tags[i].Size()
+			if len(h) == n && size <= h[0].Size {
+				continue
+			}
+			record := TagSizeRecord{
+				TraceID: span.TraceID,
+				SpanID:  span.SpanID,
+				Key:     span.Tags[i].Key,
+				Size:    size,
+			}
+			if len(h) < n {
+				heap.Push(&h, record)
+			} else {
+				h[0] = record
+				heap.Fix(&h, 0)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(h))
+	return h
+}
+
+// tagSizeHeap is a min-heap of tag size records, the smallest one on top.
+type tagSizeHeap []TagSizeRecord
+
+func (h tagSizeHeap) Len() int            { return len(h) }
+func (h tagSizeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h tagSizeHeap) Less(i, j int) bool  { return h[i].Size < h[j].Size }
+func (h *tagSizeHeap) Push(x interface{}) { *h = append(*h, x.(TagSizeRecord)) }
+func (h *tagSizeHeap) Pop() interface{} {
+	old := *h
+	record := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return record
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestGroupSpans(t *testing.T) {
+	spans := []*model.Span{
+		{SpanID: 1, OperationName: "a", Process: model.NewProcess("svc1", nil)},
+		{SpanID: 2, OperationName: "b", Process: model.NewProcess("svc2", nil)},
+		{SpanID: 3, OperationName: "a", Process: model.NewProcess("svc2", nil)},
+		{SpanID: 4, OperationName: "c", Process: model.NewProcess("svc1", nil)},
+	}
+	byService := model.GroupSpans(spans, func(s *model.Span) string { return s.Process.ServiceName })
+	assert.Len(t, byService, 2)
+	assert.Equal(t, []model.SpanID{1, 4}, spanIDs(byService["svc1"]))
+	assert.Equal(t, []model.SpanID{2, 3}, spanIDs(byService["svc2"]))
+
+	byOperation := model.GroupSpans(spans, func(s *model.Span) string { return s.OperationName })
+	assert.Len(t, byOperation, 3)
+	assert.Equal(t, []model.SpanID{1, 3}, spanIDs(byOperation["a"]))
+
+	assert.Len(t, model.GroupSpans(nil, func(s *model.Span) string { return "" }), 0)
+}
+
+func TestDistinctOperations(t *testing.T) {
+	spans := []*model.Span{
+		{OperationName: "GET", Process: model.NewProcess("svc1", nil)},
+		{OperationName: "GET", Process: model.NewProcess("svc2", nil)},
+		{OperationName: "GET", Process: model.NewProcess("svc1", nil)},
+		{OperationName: "POST", Process: model.NewProcess("svc1", nil)},
+		{OperationName: "GET"},
+	}
+	assert.Equal(t, map[string]int{"svc1:GET": 2, "svc2:GET": 1, "svc1:POST": 1, ":GET": 1}, model.DistinctOperations(spans))
+	assert.Empty(t, model.DistinctOperations(nil))
+}
+
+func TestPartitionSpansBySize(t *testing.T) {
+	makeSpan := func(id model.SpanID, tagSize int) *model.Span {
+		// a span without tags has an estimated size of 44 bytes
+		return &model.Span{SpanID: id, Tags: []model.KeyValue{model.Binary("", make([]byte, tagSize))}}
+	}
+	spans := []*model.Span{
+		makeSpan(1, 6),   // 50
+		makeSpan(2, 16),  // 60
+		makeSpan(3, 56),  // 100
+		makeSpan(4, 100), // 144, too large
+		makeSpan(5, 6),   // 50
+		makeSpan(6, 6),   // 50
+	}
+	batches := model.PartitionSpansBySize(spans, 110)
+	var ids [][]model.SpanID
+	for _, batch := range batches {
+		ids = append(ids, spanIDs(batch))
+	}
+	assert.Equal(t, [][]model.SpanID{{1, 2}, {3}, {4}, {5, 6}}, ids)
+	assert.Equal(t, []string{"span size 144 bytes exceeds batch size limit 110 bytes"}, spans[3].Warnings)
+	for _, span := range spans[:3] {
+		assert.Empty(t, span.Warnings)
+	}
+
+	assert.Equal(t, [][]*model.Span{spans}, model.PartitionSpansBySize(spans, 0))
+	assert.Nil(t, model.PartitionSpansBySize(nil, 100))
+}
+
+func TestLargestTagValues(t *testing.T) {
+	traceID := model.TraceID{Low: 1}
+	spans := []*model.Span{
+		{
+			TraceID: traceID,
+			SpanID:  1,
+			Tags: model.KeyValues{
+				model.String("a", "12345"),
+				model.Int64("b", 1),
+			},
+		},
+		{
+			TraceID: traceID,
+			SpanID:  2,
+			Tags: model.KeyValues{
+				model.Binary("c", make([]byte, 100)),
+				model.String("d", ""),
+				model.String("e", "123"),
+			},
+		},
+	}
+	assert.Equal(t, []model.TagSizeRecord{
+		{TraceID: traceID, SpanID: 2, Key: "c", Size: 101},
+		{TraceID: traceID, SpanID: 1, Key: "b", Size: 9},
+		{TraceID: traceID, SpanID: 1, Key: "a", Size: 6},
+	}, model.LargestTagValues(spans, 3))
+	assert.Len(t, model.LargestTagValues(spans, 10), 5)
+	assert.Len(t, model.LargestTagValues(spans, math.MaxInt32), 5, "large n does not preallocate n records")
+	assert.Nil(t, model.LargestTagValues(spans, 0))
+}
+
+func TestFilterByDurationRange(t *testing.T) {
+	spans := []*model.Span{
+		{SpanID: 1, Duration: 5 * time.Millisecond},
+		{SpanID: 2, Duration: 10 * time.Millisecond},
+		{SpanID: 3, Duration: 15 * time.Millisecond},
+		{SpanID: 4, Duration: 20 * time.Millisecond},
+	}
+	testCases := []struct {
+		min, max time.Duration
+		expected []model.SpanID
+	}{
+		{min: 10 * time.Millisecond, max: 15 * time.Millisecond, expected: []model.SpanID{2, 3}},
+		{min: 10 * time.Millisecond, max: 0, expected: []model.SpanID{2, 3, 4}},
+		{min: 0, max: 5 * time.Millisecond, expected: []model.SpanID{1}},
+		{min: 21 * time.Millisecond, max: 0, expected: nil},
+		{min: 15 * time.Millisecond, max: 10 * time.Millisecond, expected: nil},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, spanIDs(model.FilterByDurationRange(spans, testCase.min, testCase.max)))
+	}
+}
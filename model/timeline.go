@@ -0,0 +1,85 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "time"
+
+// TimelineRow describes the position of a span in the timeline (Gantt) view of a trace.
+type TimelineRow struct {
+	Span  *Span
+	Depth int
+	// RelativeStart and RelativeEnd are offsets from the start of the trace.
+	RelativeStart time.Duration
+	RelativeEnd   time.Duration
+}
+
+// Timeline returns the spans of the trace in depth-first order, with children
+// ordered by start time, along with their depth in the span tree and their
+// start and end times relative to the start of the trace.
+//
+// Spans whose parent is not in the trace are shown as roots. Every span
+// appears exactly once, even if its references form a cycle.
+func (t *Trace) Timeline() []TimelineRow {
+	if len(t.Spans) == 0 {
+		return nil
+	}
+	traceStart := t.Spans[0].StartTime
+	for _, span := range t.Spans {
+		if span.StartTime.Before(traceStart) {
+			traceStart = span.StartTime
+		}
+	}
+	spansByID := t.spansByID()
+	children := t.ChildIndex()
+	rows := make([]TimelineRow, 0, len(t.Spans))
+	visited := make(map[*Span]struct{}, len(t.Spans))
+	var visit func(span *Span, depth int)
+	visit = func(span *Span, depth int) {
+		if _, ok := visited[span]; ok {
+			return
+		}
+		visited[span] = struct{}{}
+		start := span.StartTime.Sub(traceStart)
+		rows = append(rows, TimelineRow{
+			Span:          span,
+			Depth:         depth,
+			RelativeStart: start,
+			RelativeEnd:   start + span.Duration,
+		})
+		spanChildren := children[span.SpanID]
+		sortSpansByStartTime(spanChildren)
+		for _, child := range spanChildren {
+			visit(child, depth+1)
+		}
+	}
+
+	var roots []*Span
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.ParentSpanID()]; !ok {
+			roots = append(roots, span)
+		}
+	}
+	sortSpansByStartTime(roots)
+	for _, root := range roots {
+		visit(root, 0)
+	}
+	// spans not reachable from any root are part of a reference cycle
+	remaining := append([]*Span(nil), t.Spans...)
+	sortSpansByStartTime(remaining)
+	for _, span := range remaining {
+		visit(span, 0)
+	}
+	return rows
+}
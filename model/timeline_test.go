@@ -0,0 +1,63 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceTimeline(t *testing.T) {
+	base := time.Unix(100, 0)
+	span := func(id, parent model.SpanID, start, duration time.Duration) *model.Span {
+		s := makeTreeSpan(id, parent)
+		s.StartTime = base.Add(start)
+		s.Duration = duration
+		return s
+	}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			span(3, 1, 5*time.Millisecond, 2*time.Millisecond),
+			span(2, 1, time.Millisecond, 3*time.Millisecond),
+			span(4, 2, 2*time.Millisecond, time.Millisecond),
+			span(1, 0, 0, 10*time.Millisecond),
+			span(5, 6, 7*time.Millisecond, time.Millisecond), // cycle between 5 and 6
+			span(6, 5, 8*time.Millisecond, time.Millisecond),
+		},
+	}
+	type row struct {
+		id         model.SpanID
+		depth      int
+		start, end time.Duration
+	}
+	expected := []row{
+		{1, 0, 0, 10 * time.Millisecond},
+		{2, 1, time.Millisecond, 4 * time.Millisecond},
+		{4, 2, 2 * time.Millisecond, 3 * time.Millisecond},
+		{3, 1, 5 * time.Millisecond, 7 * time.Millisecond},
+		{5, 0, 7 * time.Millisecond, 8 * time.Millisecond},
+		{6, 1, 8 * time.Millisecond, 9 * time.Millisecond},
+	}
+	var actual []row
+	for _, r := range trace.Timeline() {
+		actual = append(actual, row{r.Span.SpanID, r.Depth, r.RelativeStart, r.RelativeEnd})
+	}
+	assert.Equal(t, expected, actual)
+	assert.Nil(t, (&model.Trace{}).Timeline())
+}
@@ -14,10 +14,58 @@
 
 package model
 
+import (
+	"container/heap"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
 // Trace is a directed acyclic graph of Spans
 type Trace struct {
 	Spans    []*Span  `json:"spans,omitempty"`
 	Warnings []string `json:"warnings,omitempty"`
+	// Attributes describe the whole trace, e.g. the tenant or the release version,
+	// and apply to every span in the trace. See EffectiveTags, and LiftAttributes
+	// for how they are stored.
+	Attributes KeyValues `json:"attributes,omitempty"`
+}
+
+// EffectiveTags returns the span tags followed by the trace attributes whose keys
+// are not used by any of the span tags.
+func (t *Trace) EffectiveTags(span *Span) KeyValues {
+	if len(t.Attributes) == 0 {
+		return span.Tags
+	}
+	tags := make(KeyValues, len(span.Tags), len(span.Tags)+len(t.Attributes))
+	copy(tags, span.Tags)
+	for _, attr := range t.Attributes {
+		if _, ok := KeyValues(span.Tags).FindByKey(attr.Key); !ok {
+			tags = append(tags, attr)
+		}
+	}
+	return tags
+}
+
+// ID returns the trace ID of the first span in the trace, or false if the trace has no spans.
+// See IsSingleTrace for checking that it is shared by all spans.
+func (t *Trace) ID() (TraceID, bool) {
+	if len(t.Spans) == 0 {
+		return TraceID{}, false
+	}
+	return t.Spans[0].TraceID, true
+}
+
+// IsSingleTrace returns true if all spans in the trace have the same trace ID.
+// A trace without spans is considered a single trace.
+func (t *Trace) IsSingleTrace() bool {
+	for _, span := range t.Spans {
+		if span.TraceID != t.Spans[0].TraceID {
+			return false
+		}
+	}
+	return true
 }
 
 // FindSpanByID looks for a span with given span ID and returns the first one
@@ -37,3 +85,504 @@ func (t *Trace) NormalizeTimestamps() {
 		span.NormalizeTimestamps()
 	}
 }
+
+// FindSpansByOperationRegex returns the spans whose operation name matches the given
+// regular expression, in the order they appear in the trace.
+func (t *Trace) FindSpansByOperationRegex(pattern string) ([]*Span, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid operation name pattern %q: %v", pattern, err)
+	}
+	var spans []*Span
+	for _, span := range t.Spans {
+		if re.MatchString(span.OperationName) {
+			spans = append(spans, span)
+		}
+	}
+	return spans, nil
+}
+
+// RenameOperation changes the operation name of all spans named from to the new name.
+// Returns the number of spans that were renamed.
+func (t *Trace) RenameOperation(from, to string) int {
+	count := 0
+	for _, span := range t.Spans {
+		if span.OperationName == from {
+			span.OperationName = to
+			count++
+		}
+	}
+	return count
+}
+
+// RemoveSpans removes spans with the given span IDs from the trace.
+// Returns the number of spans that were removed.
+func (t *Trace) RemoveSpans(ids ...SpanID) int {
+	remove := make(map[SpanID]struct{}, len(ids))
+	for _, id := range ids {
+		remove[id] = struct{}{}
+	}
+	spans := t.Spans[:0]
+	for _, span := range t.Spans {
+		if _, ok := remove[span.SpanID]; !ok {
+			spans = append(spans, span)
+		}
+	}
+	removed := len(t.Spans) - len(spans)
+	t.Spans = spans
+	return removed
+}
+
+// LongestSpan returns the span with the longest duration, or nil if the trace has no spans.
+// If several spans have the same duration, the one that started first is returned.
+func (t *Trace) LongestSpan() *Span {
+	var longest *Span
+	for _, span := range t.Spans {
+		if longest == nil || isLonger(span, longest) {
+			longest = span
+		}
+	}
+	return longest
+}
+
+// TopNByDuration returns up to n spans with the longest durations, ordered
+// from the longest to the shortest, with ties broken by earliest start time.
+func (t *Trace) TopNByDuration(n int) []*Span {
+	if n <= 0 {
+		return nil
+	}
+	if n > len(t.Spans) {
+		n = len(t.Spans)
+	}
+	// min-heap of the n longest spans seen so far, the shortest one on top
+	h := make(spansByDurationHeap, 0, n)
+	for _, span := range t.Spans {
+		if len(h) < n {
+			heap.Push(&h, span)
+		} else if isLonger(span, h[0]) {
+			h[0] = span
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Sort(sort.Reverse(h))
+	return h
+}
+
+// isLonger returns true if span a has longer duration than b, or the same duration but an earlier start.
+func isLonger(a, b *Span) bool {
+	if a.Duration != b.Duration {
+		return a.Duration > b.Duration
+	}
+	return a.StartTime.Before(b.StartTime)
+}
+
+type spansByDurationHeap []*Span
+
+func (h spansByDurationHeap) Len() int            { return len(h) }
+func (h spansByDurationHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h spansByDurationHeap) Less(i, j int) bool  { return isLonger(h[j], h[i]) }
+func (h *spansByDurationHeap) Push(x interface{}) { *h = append(*h, x.(*Span)) }
+func (h *spansByDurationHeap) Pop() interface{} {
+	old := *h
+	span := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return span
+}
+
+// FilterKeepingAncestors returns a new trace with the spans that match the predicate,
+// as well as all their ancestors, so that the matching spans remain connected to the root.
+// The spans are shared with the original trace and keep their original order.
+func (t *Trace) FilterKeepingAncestors(pred func(*Span) bool) *Trace {
+	spansByID := t.spansByID()
+	keep := make(map[*Span]struct{})
+	for _, span := range t.Spans {
+		if !pred(span) {
+			continue
+		}
+		// walk up to the root, stopping at spans already kept to avoid cycles
+		for s := span; s != nil; s = spansByID[s.ParentSpanID()] {
+			if _, ok := keep[s]; ok {
+				break
+			}
+			keep[s] = struct{}{}
+		}
+	}
+	filtered := &Trace{Warnings: t.Warnings, Attributes: t.Attributes}
+	for _, span := range t.Spans {
+		if _, ok := keep[span]; ok {
+			filtered.Spans = append(filtered.Spans, span)
+		}
+	}
+	return filtered
+}
+
+// ChildIndex returns a map from span ID to the spans that refer to it as their parent
+// (see Span.ParentSpanID). Only children within the trace are included.
+func (t *Trace) ChildIndex() map[SpanID][]*Span {
+	children := make(map[SpanID][]*Span)
+	for _, span := range t.Spans {
+		if parentID := span.ParentSpanID(); parentID != 0 {
+			children[parentID] = append(children[parentID], span)
+		}
+	}
+	return children
+}
+
+// Fanout returns the number of direct children of each span in the trace, counting
+// only spans in the trace that refer to it as their parent (see Span.ParentSpanID).
+// Spans without children are included with a count of zero.
+func (t *Trace) Fanout() map[SpanID]int {
+	fanout := make(map[SpanID]int, len(t.Spans))
+	for _, span := range t.Spans {
+		fanout[span.SpanID] = 0
+	}
+	for _, span := range t.Spans {
+		if parentID := span.ParentSpanID(); parentID != 0 {
+			if _, ok := fanout[parentID]; ok {
+				fanout[parentID]++
+			}
+		}
+	}
+	return fanout
+}
+
+// ChildrenExceedParent returns the spans that have at least one child (see ChildIndex)
+// starting before or ending after them, in the order they appear in the trace.
+// This indicates a timing bug in the instrumentation, so the trace should be checked
+// after clock skew has been corrected by the adjusters. The trace is not modified.
+func (t *Trace) ChildrenExceedParent() []*Span {
+	children := t.ChildIndex()
+	var spans []*Span
+	for _, span := range t.Spans {
+		interval := span.Interval()
+		for _, child := range children[span.SpanID] {
+			if !interval.Contains(child.Interval()) {
+				spans = append(spans, span)
+				break
+			}
+		}
+	}
+	return spans
+}
+
+// EntrySpans returns the spans where the trace enters a service, i.e. spans whose
+// parent is not in the trace, or belongs to a different service. Spans are returned
+// in the order they appear in the trace.
+func (t *Trace) EntrySpans() []*Span {
+	spansByID := t.spansByID()
+	var entries []*Span
+	for _, span := range t.Spans {
+		parent, ok := spansByID[span.ParentSpanID()]
+		if !ok || parent.serviceName() != span.serviceName() {
+			entries = append(entries, span)
+		}
+	}
+	return entries
+}
+
+// Compact reduces the memory used by the trace without changing the content of its
+// spans: spans with equal processes (see Process.Equal) are made to share a single
+// *Process, and duplicate tags and references are removed from each span. Since
+// processes are shared afterwards, they must not be modified in place for one span.
+func (t *Trace) Compact() {
+	var processes []*Process
+	for _, span := range t.Spans {
+		if span.Process != nil {
+			shared := false
+			for _, process := range processes {
+				if process == span.Process || process.Equal(span.Process) {
+					span.Process = process
+					shared = true
+					break
+				}
+			}
+			if !shared {
+				processes = append(processes, span.Process)
+			}
+		}
+		if tags := mergeKeyValues(span.Tags[:0:0], span.Tags); len(tags) < len(span.Tags) {
+			span.Tags = tags
+		}
+		if refs := mergeReferences(span.References[:0:0], span.References); len(refs) < len(span.References) {
+			span.References = refs
+		}
+	}
+}
+
+// SpansWithoutProcess returns the spans of the trace that have no process.
+func (t *Trace) SpansWithoutProcess() []*Span {
+	var spans []*Span
+	for _, span := range t.Spans {
+		if span.Process == nil {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// AttachProcess sets the process of all spans in the trace that have no process,
+// e.g. when the process was received separately from the spans. The spans share p.
+func (t *Trace) AttachProcess(p *Process) {
+	for _, span := range t.SpansWithoutProcess() {
+		span.Process = p
+	}
+}
+
+// TraceWarning is a warning about a trace, or about one of its spans.
+type TraceWarning struct {
+	// SpanID is the ID of the span with the warning, or zero for warnings about the whole trace.
+	SpanID  SpanID
+	Message string
+}
+
+// CollectWarnings returns the warnings of the trace followed by the warnings of
+// each of its spans, in the order the spans appear in the trace.
+func (t *Trace) CollectWarnings() []TraceWarning {
+	var warnings []TraceWarning
+	for _, warning := range t.Warnings {
+		warnings = append(warnings, TraceWarning{Message: warning})
+	}
+	for _, span := range t.Spans {
+		for _, warning := range span.Warnings {
+			warnings = append(warnings, TraceWarning{SpanID: span.SpanID, Message: warning})
+		}
+	}
+	return warnings
+}
+
+// ServiceLatencies returns the latency attributed to each service in the trace: the sum
+// of the durations of its entry spans (see EntrySpans). Only entry spans are counted,
+// as the other spans of a service run within its entry spans and would be counted twice.
+func (t *Trace) ServiceLatencies() map[string]time.Duration {
+	latencies := make(map[string]time.Duration)
+	for _, span := range t.EntrySpans() {
+		latencies[span.serviceName()] += span.Duration
+	}
+	return latencies
+}
+
+// DurationOutliers returns the spans whose duration exceeds factor times the median
+// duration of the spans with the same operation (see Span.OperationKey) in the trace,
+// in the order they appear in the trace.
+func (t *Trace) DurationOutliers(factor float64) []*Span {
+	byOperation := make(map[string][]time.Duration)
+	for _, span := range t.Spans {
+		key := span.OperationKey()
+		byOperation[key] = append(byOperation[key], span.Duration)
+	}
+	medians := make(map[string]float64, len(byOperation))
+	for key, durations := range byOperation {
+		medians[key] = medianDuration(durations)
+	}
+	var outliers []*Span
+	for _, span := range t.Spans {
+		if float64(span.Duration) > factor*medians[span.OperationKey()] {
+			outliers = append(outliers, span)
+		}
+	}
+	return outliers
+}
+
+// medianDuration returns the median of the durations, which are sorted in place.
+// For an even number of durations it is the mean of the two middle ones.
+func medianDuration(durations []time.Duration) float64 {
+	sort.Sort(durationSlice(durations))
+	mid := len(durations) / 2
+	if len(durations)%2 == 1 {
+		return float64(durations[mid])
+	}
+	return (float64(durations[mid-1]) + float64(durations[mid])) / 2
+}
+
+type durationSlice []time.Duration
+
+func (s durationSlice) Len() int           { return len(s) }
+func (s durationSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s durationSlice) Less(i, j int) bool { return s[i] < s[j] }
+
+// spansByID returns a map from span ID to the first span in the trace with that ID.
+func (t *Trace) spansByID() map[SpanID]*Span {
+	spansByID := make(map[SpanID]*Span, len(t.Spans))
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.SpanID]; !ok {
+			spansByID[span.SpanID] = span
+		}
+	}
+	return spansByID
+}
+
+// CausalLess reports whether span a must be ordered before span b in a causal order:
+// it returns true if a is an ancestor of b, false if b is an ancestor of a, and otherwise
+// compares the spans by start time, then by span ID. Unlike ordering by time alone,
+// this keeps parents before children even when timestamps are affected by clock skew.
+//
+// CausalLess is not a strict weak ordering: with clock skew, a parent can be ordered
+// before its child, the child before an unrelated span and that span before the parent.
+// It also indexes the spans of the trace on every call. Use SortCausally to sort spans.
+func (t *Trace) CausalLess(a, b *Span) bool {
+	spansByID := t.spansByID()
+	if isAncestor(spansByID, a, b) {
+		return true
+	}
+	if isAncestor(spansByID, b, a) {
+		return false
+	}
+	if !a.StartTime.Equal(b.StartTime) {
+		return a.StartTime.Before(b.StartTime)
+	}
+	return a.SpanID < b.SpanID
+}
+
+// isAncestor returns true if ancestor is found among the parents of span.
+// It stops at the first repeated span, so cyclic references terminate.
+func isAncestor(spansByID map[SpanID]*Span, ancestor, span *Span) bool {
+	visited := map[*Span]struct{}{span: {}}
+	for s := spansByID[span.ParentSpanID()]; s != nil; s = spansByID[s.ParentSpanID()] {
+		if s == ancestor {
+			return true
+		}
+		if _, ok := visited[s]; ok {
+			return false
+		}
+		visited[s] = struct{}{}
+	}
+	return false
+}
+
+// InheritTag copies the span tag with the given key from parents to their children,
+// walking the span tree from the roots down, so that a tag set on a span is propagated
+// to all its descendants. Children that already have the tag keep their own value,
+// which is then propagated to their descendants instead.
+func (t *Trace) InheritTag(key string) {
+	spansByID := t.spansByID()
+	children := t.ChildIndex()
+	var queue []*Span
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.ParentSpanID()]; !ok {
+			queue = append(queue, span)
+		}
+	}
+	visited := make(map[*Span]struct{}, len(t.Spans))
+	for len(queue) > 0 {
+		span := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[span]; ok {
+			continue
+		}
+		visited[span] = struct{}{}
+		tag, ok := KeyValues(span.Tags).FindByKey(key)
+		for _, child := range children[span.SpanID] {
+			if _, found := KeyValues(child.Tags).FindByKey(key); ok && !found {
+				child.Tags = append(child.Tags, tag)
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// TagKeyCounts returns, for each span tag key, the number of spans that have a tag with that key.
+// Process tags and log fields are not included, see AllTagKeyCounts.
+func (t *Trace) TagKeyCounts() map[string]int {
+	return t.tagKeyCounts(false)
+}
+
+// AllTagKeyCounts is like TagKeyCounts, but also counts the keys of process tags and log fields.
+func (t *Trace) AllTagKeyCounts() map[string]int {
+	return t.tagKeyCounts(true)
+}
+
+func (t *Trace) tagKeyCounts(all bool) map[string]int {
+	counts := make(map[string]int)
+	for _, span := range t.Spans {
+		keys := make(map[string]struct{})
+		addKeys := func(kvs []KeyValue) {
+			for i := range kvs {
+				keys[kvs[i].Key] = struct{}{}
+			}
+		}
+		addKeys(span.Tags)
+		if all {
+			if span.Process != nil {
+				addKeys(span.Process.Tags)
+			}
+			for i := range span.Logs {
+				addKeys(span.Logs[i].Fields)
+			}
+		}
+		for key := range keys {
+			counts[key]++
+		}
+	}
+	return counts
+}
+
+// Interval returns the time interval from the start of the earliest span to the end
+// of the latest span in the trace, or an empty interval if the trace has no spans.
+func (t *Trace) Interval() Interval {
+	if len(t.Spans) == 0 {
+		return Interval{}
+	}
+	interval := t.Spans[0].Interval()
+	for _, span := range t.Spans[1:] {
+		spanInterval := span.Interval()
+		if spanInterval.Start.Before(interval.Start) {
+			interval.Start = spanInterval.Start
+		}
+		if spanInterval.End.After(interval.End) {
+			interval.End = spanInterval.End
+		}
+	}
+	return interval
+}
+
+// Duration returns the time from the start of the earliest span to the end of the
+// latest span in the trace, or zero if the trace has no spans.
+func (t *Trace) Duration() time.Duration {
+	return t.Interval().Duration()
+}
+
+// DurationShare returns the duration of the span as a fraction of the trace duration,
+// clamped to [0, 1]. It returns 0 if the trace has no spans or zero duration.
+func (t *Trace) DurationShare(s *Span) float64 {
+	traceDuration := t.Duration()
+	if traceDuration <= 0 {
+		return 0
+	}
+	share := float64(s.Duration) / float64(traceDuration)
+	if share < 0 {
+		return 0
+	}
+	if share > 1 {
+		return 1
+	}
+	return share
+}
+
+// RebaseToZero shifts all timestamps in the trace so that the earliest span starts
+// at the Unix epoch. It returns the original start time of the earliest span, which
+// can be passed to RebaseTo to undo the change.
+func (t *Trace) RebaseToZero() time.Time {
+	return t.RebaseTo(time.Unix(0, 0).UTC())
+}
+
+// RebaseTo shifts all timestamps in the trace so that the earliest span starts at base.
+// It returns the original start time of the earliest span, or zero time for an empty trace.
+func (t *Trace) RebaseTo(base time.Time) time.Time {
+	if len(t.Spans) == 0 {
+		return time.Time{}
+	}
+	start := t.Spans[0].StartTime
+	for _, span := range t.Spans {
+		if span.StartTime.Before(start) {
+			start = span.StartTime
+		}
+	}
+	delta := base.Sub(start)
+	for _, span := range t.Spans {
+		span.StartTime = span.StartTime.Add(delta)
+		for i := range span.Logs {
+			span.Logs[i].Timestamp = span.Logs[i].Timestamp.Add(delta)
+		}
+	}
+	return start
+}
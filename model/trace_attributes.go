@@ -0,0 +1,81 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "strings"
+
+// TraceAttributeTagPrefix is prepended to the keys of trace attributes stored as span tags.
+// Clients can set such tags on the root span to attach attributes to the whole trace.
+const TraceAttributeTagPrefix = "jaeger.trace_attribute."
+
+// MoveAttributesToRootSpans stores the trace attributes as tags of the root spans, with
+// TraceAttributeTagPrefix prepended to the keys, and clears the attributes. Storage keeps
+// spans rather than traces, so this is how attributes are written; LiftAttributes reads
+// them back. If no span is a root span, e.g. because of reference cycles, the attributes
+// are stored on the first span. Traces without spans are not changed.
+func (t *Trace) MoveAttributesToRootSpans() {
+	if len(t.Attributes) == 0 || len(t.Spans) == 0 {
+		return
+	}
+	spansByID := t.spansByID()
+	var roots []*Span
+	for _, span := range t.Spans {
+		if _, ok := spansByID[span.ParentSpanID()]; !ok {
+			roots = append(roots, span)
+		}
+	}
+	if len(roots) == 0 {
+		roots = t.Spans[:1]
+	}
+	for _, span := range roots {
+		for _, attr := range t.Attributes {
+			attr.Key = TraceAttributeTagPrefix + attr.Key
+			span.setTag(attr)
+		}
+	}
+	t.Attributes = nil
+}
+
+// LiftAttributes removes the span tags whose keys start with TraceAttributeTagPrefix and
+// adds them to the trace attributes without the prefix. Attributes already set on the trace
+// take precedence over the tags, and tags of earlier spans over those of later spans.
+func (t *Trace) LiftAttributes() {
+	for _, span := range t.Spans {
+		var tags []KeyValue
+		lifted := false
+		for i, tag := range span.Tags {
+			if !strings.HasPrefix(tag.Key, TraceAttributeTagPrefix) {
+				if lifted {
+					tags = append(tags, tag)
+				}
+				continue
+			}
+			if !lifted {
+				tags = append(make([]KeyValue, 0, len(span.Tags)-1), span.Tags[:i]...)
+				lifted = true
+			}
+			tag.Key = strings.TrimPrefix(tag.Key, TraceAttributeTagPrefix)
+			if _, ok := t.Attributes.FindByKey(tag.Key); !ok {
+				t.Attributes = append(t.Attributes, tag)
+			}
+		}
+		if lifted {
+			if len(tags) == 0 {
+				tags = nil
+			}
+			span.Tags = tags
+		}
+	}
+}
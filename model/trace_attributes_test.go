@@ -0,0 +1,78 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceMoveAndLiftAttributes(t *testing.T) {
+	root := makeTreeSpan(1, 0)
+	root.Tags = []model.KeyValue{model.String("k", "v")}
+	child := makeTreeSpan(2, 1)
+	trace := &model.Trace{
+		Spans:      []*model.Span{root, child},
+		Attributes: model.KeyValues{model.String("tenant", "acme"), model.Int64("shard", 3)},
+	}
+	trace.MoveAttributesToRootSpans()
+	assert.Nil(t, trace.Attributes)
+	assert.Equal(t, []model.KeyValue{
+		model.String("k", "v"),
+		model.String("jaeger.trace_attribute.tenant", "acme"),
+		model.Int64("jaeger.trace_attribute.shard", 3),
+	}, root.Tags)
+	assert.Nil(t, child.Tags)
+
+	trace.LiftAttributes()
+	assert.Equal(t, model.KeyValues{model.String("tenant", "acme"), model.Int64("shard", 3)}, trace.Attributes)
+	assert.Equal(t, []model.KeyValue{model.String("k", "v")}, root.Tags)
+}
+
+func TestTraceLiftAttributesPrecedence(t *testing.T) {
+	first := &model.Span{SpanID: 1, Tags: []model.KeyValue{
+		model.String("jaeger.trace_attribute.release", "v1"),
+		model.String("jaeger.trace_attribute.region", "us"),
+	}}
+	second := &model.Span{SpanID: 2, Tags: []model.KeyValue{
+		model.String("jaeger.trace_attribute.region", "eu"),
+		model.String("k", "v"),
+	}}
+	trace := &model.Trace{
+		Spans:      []*model.Span{first, second},
+		Attributes: model.KeyValues{model.String("release", "v2")},
+	}
+	trace.LiftAttributes()
+	assert.Equal(t, model.KeyValues{model.String("release", "v2"), model.String("region", "us")}, trace.Attributes)
+	assert.Nil(t, first.Tags)
+	assert.Equal(t, []model.KeyValue{model.String("k", "v")}, second.Tags)
+}
+
+func TestTraceMoveAttributesWithoutRoot(t *testing.T) {
+	cycle := &model.Trace{
+		Spans:      []*model.Span{makeTreeSpan(1, 2), makeTreeSpan(2, 1)},
+		Attributes: model.KeyValues{model.Bool("b", true)},
+	}
+	cycle.MoveAttributesToRootSpans()
+	assert.Equal(t, []model.KeyValue{model.Bool("jaeger.trace_attribute.b", true)}, cycle.Spans[0].Tags)
+	assert.Nil(t, cycle.Spans[1].Tags)
+
+	empty := &model.Trace{Attributes: model.KeyValues{model.Bool("b", true)}}
+	empty.MoveAttributesToRootSpans()
+	assert.Len(t, empty.Attributes, 1, "attributes are kept without spans to store them")
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"traceID", "spanID", "parentSpanID", "serviceName", "operationName", "startTime", "duration"}
+
+// WriteCSV writes the spans of the trace to w as CSV, one row per span in
+// chronological order. Each row has the trace, span and parent span IDs, service
+// and operation names, start time in RFC 3339 format and duration in microseconds,
+// followed by the values of the tags listed in tagColumns (empty if the span does
+// not have the tag). The first row is a header with the column names.
+func (t *Trace) WriteCSV(w io.Writer, tagColumns []string) error {
+	spans := append([]*Span(nil), t.Spans...)
+	sortSpansByStartTime(spans)
+
+	writer := csv.NewWriter(w)
+	header := append(append(make([]string, 0, len(csvHeader)+len(tagColumns)), csvHeader...), tagColumns...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, span := range spans {
+		row := make([]string, 0, len(header))
+		parentSpanID := ""
+		if parentID := span.ParentSpanID(); parentID != 0 {
+			parentSpanID = parentID.String()
+		}
+		row = append(row,
+			span.TraceID.String(),
+			span.SpanID.String(),
+			parentSpanID,
+			span.serviceName(),
+			span.OperationName,
+			span.StartTime.UTC().Format(time.RFC3339Nano),
+			strconv.FormatUint(DurationAsMicroseconds(span.Duration), 10),
+		)
+		for _, key := range tagColumns {
+			value := ""
+			if tag, ok := KeyValues(span.Tags).FindByKey(key); ok {
+				value = tag.AsString()
+			}
+			row = append(row, value)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceWriteCSV(t *testing.T) {
+	base := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	root := makeTreeSpan(1, 0)
+	root.OperationName = "GET /users"
+	root.StartTime = base
+	root.Duration = 5 * time.Millisecond
+	root.Process = model.NewProcess("frontend", nil)
+	root.Tags = []model.KeyValue{model.Int64("http.status_code", 200), model.String("http.method", "GET")}
+
+	child := makeTreeSpan(2, 1)
+	child.OperationName = "query, \"users\""
+	child.StartTime = base.Add(1500 * time.Microsecond)
+	child.Duration = 2 * time.Millisecond
+	child.Tags = []model.KeyValue{model.Bool("error", true)}
+
+	trace := &model.Trace{Spans: []*model.Span{child, root}}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, trace.WriteCSV(buf, []string{"http.status_code", "error"}))
+	expected := "traceID,spanID,parentSpanID,serviceName,operationName,startTime,duration,http.status_code,error\n" +
+		"1,1,,frontend,GET /users,2018-03-01T12:00:00Z,5000,200,\n" +
+		"1,2,1,,\"query, \"\"users\"\"\",2018-03-01T12:00:00.0015Z,2000,,true\n"
+	assert.Equal(t, expected, buf.String())
+	assert.Equal(t, []model.SpanID{2, 1}, spanIDs(trace.Spans), "trace must not be reordered")
+}
+
+func TestTraceWriteCSVEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, (&model.Trace{}).WriteCSV(buf, nil))
+	assert.Equal(t, "traceID,spanID,parentSpanID,serviceName,operationName,startTime,duration\n", buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestTraceWriteCSVError(t *testing.T) {
+	trace := &model.Trace{Spans: []*model.Span{makeTreeSpan(1, 0)}}
+	assert.EqualError(t, trace.WriteCSV(failingWriter{}, nil), "write failed")
+}
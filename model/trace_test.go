@@ -15,14 +15,32 @@
 package model_test
 
 import (
+	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jaegertracing/jaeger/model"
 )
 
+func TestTraceID(t *testing.T) {
+	trace := &model.Trace{}
+	_, ok := trace.ID()
+	assert.False(t, ok)
+	assert.True(t, trace.IsSingleTrace())
+
+	trace.Spans = []*model.Span{makeTreeSpan(1, 0), makeTreeSpan(2, 1)}
+	id, ok := trace.ID()
+	assert.True(t, ok)
+	assert.Equal(t, model.TraceID{Low: 1}, id)
+	assert.True(t, trace.IsSingleTrace())
+
+	trace.Spans = append(trace.Spans, &model.Span{TraceID: model.TraceID{High: 1, Low: 1}, SpanID: 3})
+	assert.False(t, trace.IsSingleTrace())
+}
+
 func TestTraceFindSpanByID(t *testing.T) {
 	trace := &model.Trace{
 		Spans: []*model.Span{
@@ -65,3 +83,456 @@ func TestTraceNormalizeTimestamps(t *testing.T) {
 	assert.Equal(t, span.StartTime, tt1.UTC())
 	assert.Equal(t, span.Logs[0].Timestamp, tt2.UTC())
 }
+
+func TestTraceFindSpansByOperationRegex(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: model.SpanID(1), OperationName: "GET /api/v1/users"},
+			{SpanID: model.SpanID(2), OperationName: "GET /api/users"},
+			{SpanID: model.SpanID(3), OperationName: "POST /api/v2/users"},
+			{SpanID: model.SpanID(4), OperationName: "GET /api/v12/orders"},
+		},
+	}
+	spans, err := trace.FindSpansByOperationRegex(`^GET /api/v[0-9]+`)
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanID{1, 4}, spanIDs(spans))
+
+	spans, err = trace.FindSpansByOperationRegex("orders$|^POST")
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanID{3, 4}, spanIDs(spans))
+
+	spans, err = trace.FindSpansByOperationRegex("DELETE")
+	require.NoError(t, err)
+	assert.Empty(t, spans)
+
+	_, err = trace.FindSpansByOperationRegex("GET /api/v[0-9")
+	assert.EqualError(t, err, "invalid operation name pattern \"GET /api/v[0-9\": error parsing regexp: missing closing ]: `[0-9`")
+}
+
+func TestTraceRenameOperation(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: model.SpanID(1), OperationName: "x"},
+			{SpanID: model.SpanID(2), OperationName: "y"},
+			{SpanID: model.SpanID(3), OperationName: "x"},
+		},
+	}
+	assert.Equal(t, 2, trace.RenameOperation("x", "z"))
+	assert.Equal(t, "z", trace.Spans[0].OperationName)
+	assert.Equal(t, "y", trace.Spans[1].OperationName)
+	assert.Equal(t, "z", trace.Spans[2].OperationName)
+}
+
+func TestTraceRemoveSpans(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: model.SpanID(1)},
+			{SpanID: model.SpanID(2)},
+			{SpanID: model.SpanID(3)},
+		},
+	}
+	assert.Equal(t, 2, trace.RemoveSpans(model.SpanID(1), model.SpanID(3), model.SpanID(4)))
+	assert.Len(t, trace.Spans, 1)
+	assert.Equal(t, model.SpanID(2), trace.Spans[0].SpanID)
+}
+
+func makeDurationTrace() *model.Trace {
+	base := time.Unix(100, 0)
+	return &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: model.SpanID(1), StartTime: base, Duration: 10 * time.Millisecond},
+			{SpanID: model.SpanID(2), StartTime: base.Add(2 * time.Millisecond), Duration: 30 * time.Millisecond},
+			{SpanID: model.SpanID(3), StartTime: base.Add(time.Millisecond), Duration: 30 * time.Millisecond},
+			{SpanID: model.SpanID(4), StartTime: base, Duration: 20 * time.Millisecond},
+			{SpanID: model.SpanID(5), StartTime: base, Duration: 5 * time.Millisecond},
+		},
+	}
+}
+
+func spanIDs(spans []*model.Span) []model.SpanID {
+	var ids []model.SpanID
+	for _, span := range spans {
+		ids = append(ids, span.SpanID)
+	}
+	return ids
+}
+
+func TestTraceLongestSpan(t *testing.T) {
+	trace := makeDurationTrace()
+	assert.Equal(t, model.SpanID(3), trace.LongestSpan().SpanID)
+	assert.Nil(t, (&model.Trace{}).LongestSpan())
+}
+
+func TestTraceTopNByDuration(t *testing.T) {
+	trace := makeDurationTrace()
+	testCases := []struct {
+		n        int
+		expected []model.SpanID
+	}{
+		{n: 0, expected: nil},
+		{n: 1, expected: []model.SpanID{3}},
+		{n: 3, expected: []model.SpanID{3, 2, 4}},
+		{n: 10, expected: []model.SpanID{3, 2, 4, 1, 5}},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, spanIDs(trace.TopNByDuration(testCase.n)), "n=%d", testCase.n)
+	}
+	assert.Len(t, trace.TopNByDuration(math.MaxInt32), len(trace.Spans), "large n does not preallocate n spans")
+}
+
+func TestTraceFilterKeepingAncestors(t *testing.T) {
+	span := func(id, parent model.SpanID, op string) *model.Span {
+		s := makeTreeSpan(id, parent)
+		s.OperationName = op
+		return s
+	}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			span(1, 0, "root"),
+			span(2, 1, "a"),
+			span(3, 2, "error"),
+			span(4, 1, "b"),
+			span(5, 4, "c"),
+			span(6, 7, "error"), // cycle between 6 and 7
+			span(7, 6, "d"),
+		},
+		Warnings:   []string{"w"},
+		Attributes: model.KeyValues{model.String("tenant", "acme")},
+	}
+	isError := func(s *model.Span) bool { return s.OperationName == "error" }
+	filtered := trace.FilterKeepingAncestors(isError)
+	assert.Equal(t, []model.SpanID{1, 2, 3, 6, 7}, spanIDs(filtered.Spans))
+	assert.Equal(t, []string{"w"}, filtered.Warnings)
+	assert.Equal(t, trace.Attributes, filtered.Attributes)
+	assert.Len(t, trace.Spans, 7, "original trace must not be modified")
+
+	none := trace.FilterKeepingAncestors(func(*model.Span) bool { return false })
+	assert.Len(t, none.Spans, 0)
+}
+
+// makeTreeSpan creates a span in trace 1 with a child-of reference to the parent, if non-zero.
+func makeTreeSpan(id, parent model.SpanID) *model.Span {
+	traceID := model.TraceID{Low: 1}
+	return &model.Span{
+		TraceID:    traceID,
+		SpanID:     id,
+		References: model.MaybeAddParentSpanID(traceID, parent, nil),
+	}
+}
+
+func TestTraceChildIndex(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(1, 0),
+			makeTreeSpan(2, 1),
+			makeTreeSpan(3, 1),
+			makeTreeSpan(4, 3),
+			makeTreeSpan(5, 9), // parent not in trace
+		},
+	}
+	children := trace.ChildIndex()
+	assert.Len(t, children, 3)
+	assert.Equal(t, []model.SpanID{2, 3}, spanIDs(children[1]))
+	assert.Equal(t, []model.SpanID{4}, spanIDs(children[3]))
+	assert.Equal(t, []model.SpanID{5}, spanIDs(children[9]))
+}
+
+func TestTraceCompact(t *testing.T) {
+	makeTrace := func() *model.Trace {
+		trace := &model.Trace{
+			Spans: []*model.Span{makeTreeSpan(1, 0), makeTreeSpan(2, 1), makeTreeSpan(3, 1), makeTreeSpan(4, 1)},
+		}
+		trace.Spans[0].Process = model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "a")})
+		trace.Spans[1].Process = model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "a")})
+		trace.Spans[2].Process = model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "b")})
+		trace.Spans[1].Tags = []model.KeyValue{model.String("k", "v"), model.String("k", "v"), model.String("k", "w")}
+		trace.Spans[2].Tags = []model.KeyValue{}
+		trace.Spans[2].References = append(trace.Spans[2].References, trace.Spans[2].References...)
+		return trace
+	}
+	trace := makeTrace()
+	trace.Compact()
+	assert.True(t, trace.Spans[0].Process == trace.Spans[1].Process, "equal processes must be shared")
+	assert.False(t, trace.Spans[0].Process == trace.Spans[2].Process)
+	assert.Nil(t, trace.Spans[3].Process)
+	assert.Equal(t, []model.KeyValue{model.String("k", "v"), model.String("k", "w")}, trace.Spans[1].Tags)
+	assert.Len(t, trace.Spans[2].References, 1)
+
+	expected := makeTrace()
+	expected.Spans[1].Tags = expected.Spans[1].Tags[1:]
+	expected.Spans[2].References = expected.Spans[2].References[1:]
+	assert.Equal(t, expected, trace)
+}
+
+func TestTraceAttachProcess(t *testing.T) {
+	frontend := model.NewProcess("frontend", nil)
+	trace := &model.Trace{
+		Spans: []*model.Span{{SpanID: 1, Process: frontend}, {SpanID: 2}, {SpanID: 3}},
+	}
+	assert.Equal(t, []model.SpanID{2, 3}, spanIDs(trace.SpansWithoutProcess()))
+
+	backend := model.NewProcess("backend", nil)
+	trace.AttachProcess(backend)
+	assert.Empty(t, trace.SpansWithoutProcess())
+	assert.True(t, trace.Spans[0].Process == frontend, "existing process must be kept")
+	assert.True(t, trace.Spans[1].Process == backend)
+	assert.True(t, trace.Spans[2].Process == backend)
+}
+
+func TestTraceCollectWarnings(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: 2, Warnings: []string{"clock skew adjusted", "duplicate span"}},
+			{SpanID: 1},
+			{SpanID: 3, Warnings: []string{"invalid parent"}},
+		},
+		Warnings: []string{"trace is incomplete"},
+	}
+	assert.Equal(t, []model.TraceWarning{
+		{Message: "trace is incomplete"},
+		{SpanID: 2, Message: "clock skew adjusted"},
+		{SpanID: 2, Message: "duplicate span"},
+		{SpanID: 3, Message: "invalid parent"},
+	}, trace.CollectWarnings())
+	assert.Nil(t, (&model.Trace{Spans: []*model.Span{{SpanID: 1}}}).CollectWarnings())
+}
+
+func TestTraceFanout(t *testing.T) {
+	followsFrom := makeTreeSpan(6, 0)
+	followsFrom.References = []model.SpanRef{model.NewFollowsFromRef(followsFrom.TraceID, 1)}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(1, 0),
+			makeTreeSpan(2, 1),
+			makeTreeSpan(3, 1),
+			makeTreeSpan(4, 1),
+			makeTreeSpan(5, 9), // parent not in trace
+			followsFrom,
+		},
+	}
+	assert.Equal(t, map[model.SpanID]int{1: 3, 2: 0, 3: 0, 4: 0, 5: 0, 6: 0}, trace.Fanout())
+	assert.Empty(t, (&model.Trace{}).Fanout())
+}
+
+func TestTraceChildrenExceedParent(t *testing.T) {
+	base := time.Unix(100, 0)
+	span := func(id, parent model.SpanID, start, duration time.Duration) *model.Span {
+		span := makeTreeSpan(id, parent)
+		span.StartTime = base.Add(start)
+		span.Duration = duration
+		return span
+	}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			span(1, 0, 0, 10*time.Second),
+			span(2, 1, time.Second, 9*time.Second),                  // ends with the parent
+			span(3, 2, 2*time.Second, 9*time.Second),                // ends after the parent
+			span(4, 1, -time.Second, time.Second),                   // starts before the parent
+			span(5, 9, 0, 20*time.Second),                           // parent not in trace
+			span(6, 4, -500*time.Millisecond, 500*time.Millisecond), // within the parent
+		},
+	}
+	assert.Equal(t, []model.SpanID{1, 2}, spanIDs(trace.ChildrenExceedParent()))
+	assert.Nil(t, (&model.Trace{}).ChildrenExceedParent())
+}
+
+func TestTraceServiceLatencies(t *testing.T) {
+	services := map[model.SpanID]string{1: "frontend", 2: "frontend", 3: "backend", 4: "backend", 5: "db", 6: "db"}
+	durations := map[model.SpanID]time.Duration{1: 100, 2: 90, 3: 50, 4: 40, 5: 10, 6: 15}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(1, 0),
+			makeTreeSpan(2, 1),
+			makeTreeSpan(3, 2),
+			makeTreeSpan(4, 3),
+			makeTreeSpan(5, 4),
+			makeTreeSpan(6, 2),
+		},
+	}
+	for _, span := range trace.Spans {
+		span.Process = model.NewProcess(services[span.SpanID], nil)
+		span.Duration = durations[span.SpanID] * time.Millisecond
+	}
+	assert.Equal(t, map[string]time.Duration{
+		"frontend": 100 * time.Millisecond,
+		"backend":  50 * time.Millisecond,
+		"db":       25 * time.Millisecond,
+	}, trace.ServiceLatencies())
+	assert.Empty(t, (&model.Trace{}).ServiceLatencies())
+}
+
+func TestTraceDurationOutliers(t *testing.T) {
+	trace := &model.Trace{}
+	for i, d := range []time.Duration{10, 12, 11, 40, 30, 100, 300} {
+		op := "query"
+		if i >= 5 {
+			op = "render" // a slow operation with its own baseline
+		}
+		trace.Spans = append(trace.Spans, &model.Span{SpanID: model.SpanID(i + 1), OperationName: op, Duration: d})
+	}
+	// medians: query 12, render 200
+	assert.Equal(t, []model.SpanID{4, 5}, spanIDs(trace.DurationOutliers(2)))
+	assert.Equal(t, []model.SpanID{4}, spanIDs(trace.DurationOutliers(2.5)))
+	assert.Equal(t, []model.SpanID{4, 5, 7}, spanIDs(trace.DurationOutliers(1.2)))
+	assert.Empty(t, trace.DurationOutliers(4))
+	assert.Equal(t, []model.SpanID{1, 2, 3, 4, 5, 6, 7}, spanIDs(trace.Spans), "spans must not be reordered")
+	assert.Empty(t, (&model.Trace{}).DurationOutliers(2))
+}
+
+func TestTraceEntrySpans(t *testing.T) {
+	services := map[model.SpanID]string{1: "frontend", 2: "frontend", 3: "backend", 4: "backend", 5: "backend", 6: "frontend"}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			makeTreeSpan(1, 0),
+			makeTreeSpan(2, 1),
+			makeTreeSpan(3, 2),
+			makeTreeSpan(4, 3),
+			makeTreeSpan(5, 9), // parent not in trace
+			makeTreeSpan(6, 4),
+		},
+	}
+	for _, span := range trace.Spans {
+		span.Process = model.NewProcess(services[span.SpanID], nil)
+	}
+	assert.Equal(t, []model.SpanID{1, 3, 5, 6}, spanIDs(trace.EntrySpans()))
+	assert.Nil(t, (&model.Trace{}).EntrySpans())
+}
+
+func TestTraceCausalLess(t *testing.T) {
+	base := time.Unix(100, 0)
+	span := func(id, parent model.SpanID, start time.Duration) *model.Span {
+		s := makeTreeSpan(id, parent)
+		s.StartTime = base.Add(start)
+		return s
+	}
+	root := span(1, 0, time.Second) // starts after its child due to clock skew
+	child := span(2, 1, 0)
+	grandchild := span(3, 2, 0)
+	unrelated := span(4, 0, 0)
+	sameTime := span(5, 0, 0)
+	cycle1 := span(6, 7, 0)
+	cycle2 := span(7, 6, time.Second)
+	trace := &model.Trace{
+		Spans: []*model.Span{root, child, grandchild, unrelated, sameTime, cycle1, cycle2},
+	}
+	testCases := []struct {
+		a, b     *model.Span
+		expected bool
+	}{
+		{a: root, b: child, expected: true},
+		{a: child, b: root, expected: false},
+		{a: root, b: grandchild, expected: true},
+		{a: grandchild, b: root, expected: false},
+		{a: unrelated, b: root, expected: true},
+		{a: root, b: unrelated, expected: false},
+		{a: unrelated, b: sameTime, expected: true},
+		{a: sameTime, b: unrelated, expected: false},
+		{a: cycle1, b: cycle2, expected: true}, // terminates despite the cycle
+		{a: grandchild, b: cycle1, expected: true},
+	}
+	for i, testCase := range testCases {
+		assert.Equal(t, testCase.expected, trace.CausalLess(testCase.a, testCase.b), "test case %d", i)
+	}
+}
+
+func TestTraceInheritTag(t *testing.T) {
+	span := func(id, parent model.SpanID, tags ...model.KeyValue) *model.Span {
+		s := makeTreeSpan(id, parent)
+		s.Tags = tags
+		return s
+	}
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			span(4, 3),
+			span(3, 1, model.String("tenant.id", "t2")),
+			span(2, 1, model.String("x", "y")),
+			span(1, 0, model.String("tenant.id", "t1")),
+			span(5, 2),
+			span(6, 7), // cycle between 6 and 7
+			span(7, 6, model.String("tenant.id", "t3")),
+		},
+	}
+	trace.InheritTag("tenant.id")
+	tenant := func(id model.SpanID) string {
+		tag, _ := model.KeyValues(trace.FindSpanByID(id).Tags).FindByKey("tenant.id")
+		return tag.VStr
+	}
+	assert.Equal(t, "t1", tenant(1))
+	assert.Equal(t, "t1", tenant(2))
+	assert.Equal(t, "t2", tenant(3), "existing child value is not overwritten")
+	assert.Equal(t, "t2", tenant(4))
+	assert.Equal(t, "t1", tenant(5))
+	assert.Equal(t, "", tenant(6), "spans in a cycle are not reachable from a root")
+	assert.Len(t, trace.FindSpanByID(2).Tags, 2)
+}
+
+func TestTraceTagKeyCounts(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				Tags:    model.KeyValues{model.String("a", "1"), model.String("a", "2"), model.String("b", "1")},
+				Process: model.NewProcess("svc", []model.KeyValue{model.String("host", "h")}),
+				Logs:    []model.Log{{Fields: model.KeyValues{model.String("event", "x"), model.String("a", "3")}}},
+			},
+			{
+				Tags: model.KeyValues{model.String("b", "2")},
+			},
+		},
+	}
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, trace.TagKeyCounts())
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "host": 1, "event": 1}, trace.AllTagKeyCounts())
+}
+
+func TestTraceRebaseToZero(t *testing.T) {
+	base := time.Unix(1000, 0).UTC()
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{StartTime: base.Add(time.Second), Logs: []model.Log{{Timestamp: base.Add(2 * time.Second)}}},
+			{StartTime: base},
+		},
+	}
+	original := trace.RebaseToZero()
+	assert.Equal(t, base, original)
+	assert.Equal(t, time.Unix(1, 0).UTC(), trace.Spans[0].StartTime)
+	assert.Equal(t, time.Unix(2, 0).UTC(), trace.Spans[0].Logs[0].Timestamp)
+	assert.Equal(t, time.Unix(0, 0).UTC(), trace.Spans[1].StartTime)
+
+	assert.Equal(t, time.Unix(0, 0).UTC(), trace.RebaseTo(original))
+	assert.Equal(t, base.Add(time.Second), trace.Spans[0].StartTime)
+	assert.Equal(t, base, trace.Spans[1].StartTime)
+
+	assert.True(t, (&model.Trace{}).RebaseToZero().IsZero())
+}
+
+func TestTraceDuration(t *testing.T) {
+	trace := makeDurationTrace()
+	base := time.Unix(100, 0)
+	assert.Equal(t, model.Interval{Start: base, End: base.Add(32 * time.Millisecond)}, trace.Interval())
+	assert.Equal(t, 32*time.Millisecond, trace.Duration())
+	assert.Equal(t, time.Duration(0), (&model.Trace{}).Duration())
+}
+
+func TestTraceDurationShare(t *testing.T) {
+	trace := makeDurationTrace()
+	assert.Equal(t, 0.3125, trace.DurationShare(trace.Spans[0]))
+	assert.Equal(t, 1.0, trace.DurationShare(&model.Span{Duration: time.Second}))
+	assert.Equal(t, 0.0, trace.DurationShare(&model.Span{Duration: -time.Second}))
+
+	assert.Equal(t, 0.0, (&model.Trace{}).DurationShare(&model.Span{Duration: time.Second}))
+	zero := &model.Trace{Spans: []*model.Span{{StartTime: time.Unix(100, 0)}}}
+	assert.Equal(t, 0.0, zero.DurationShare(zero.Spans[0]))
+}
+
+func TestTraceEffectiveTags(t *testing.T) {
+	span := &model.Span{Tags: []model.KeyValue{model.String("release", "v1"), model.Int64("n", 1)}}
+	trace := &model.Trace{Spans: []*model.Span{span}}
+	assert.Equal(t, model.KeyValues(span.Tags), trace.EffectiveTags(span))
+
+	trace.Attributes = model.KeyValues{model.String("tenant", "acme"), model.String("release", "v2")}
+	assert.Equal(t, model.KeyValues{
+		model.String("release", "v1"),
+		model.Int64("n", 1),
+		model.String("tenant", "acme"),
+	}, trace.EffectiveTags(span))
+	assert.Len(t, span.Tags, 2, "span tags must not be modified")
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "fmt"
+
+// MaxValidTagValueLength is the largest size in bytes of a string or binary value
+// of a tag, log field or process tag that ValidateSpan accepts.
+const MaxValidTagValueLength = 64 * 1024
+
+// Violation describes a problem with a span found by ValidateSpan.
+type Violation struct {
+	// Field is the path of the invalid field in the syntax of Span.Get, e.g.
+	// "duration", "tags.http.url" or "references[1].spanID". A tag with an empty
+	// key has no such path and is reported by its index instead, e.g. "tags[3]".
+	Field   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// ValidateSpan checks the span for malformed data: zero trace or span IDs, an empty
+// operation name, a zero start time or negative duration, a missing process or service
+// name, tags with empty keys, an unknown value type or values larger than
+// MaxValidTagValueLength, and references with zero IDs, an unknown type, or
+// pointing at the span itself.
+// It returns all violations found, in the order of the span fields, or nil if the span is valid.
+func ValidateSpan(span *Span) []Violation {
+	var violations []Violation
+	add := func(field, format string, args ...interface{}) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if span.TraceID.High == 0 && span.TraceID.Low == 0 {
+		add("traceID", "trace ID is zero")
+	}
+	if span.SpanID == 0 {
+		add("spanID", "span ID is zero")
+	}
+	if span.OperationName == "" {
+		add("operationName", "operation name is empty")
+	}
+	for i, ref := range span.References {
+		field := fmt.Sprintf("references[%d]", i)
+		if ref.RefType != ChildOf && ref.RefType != FollowsFrom {
+			add(field+".refType", "unknown reference type %d", ref.RefType)
+		}
+		if ref.TraceID.High == 0 && ref.TraceID.Low == 0 {
+			add(field+".traceID", "trace ID is zero")
+		}
+		if ref.SpanID == 0 {
+			add(field+".spanID", "span ID is zero")
+		} else if ref.SpanID == span.SpanID && ref.TraceID == span.TraceID {
+			add(field+".spanID", "span refers to itself")
+		}
+	}
+	if span.StartTime.IsZero() {
+		add("startTime", "start time is zero")
+	}
+	if span.Duration < 0 {
+		add("duration", "duration %v is negative", span.Duration)
+	}
+	violations = validateKeyValues(violations, "tags", span.Tags)
+	for i := range span.Logs {
+		violations = validateKeyValues(violations, fmt.Sprintf("logs[%d].fields", i), span.Logs[i].Fields)
+	}
+	if span.Process == nil {
+		add("process", "process is missing")
+	} else {
+		if span.Process.ServiceName == "" {
+			add("process.serviceName", "service name is empty")
+		}
+		violations = validateKeyValues(violations, "process.tags", span.Process.Tags)
+	}
+	return violations
+}
+
+func validateKeyValues(violations []Violation, prefix string, kvs []KeyValue) []Violation {
+	for i := range kvs {
+		kv := &kvs[i]
+		field := prefix + "." + kv.Key
+		if kv.Key == "" {
+			field = fmt.Sprintf("%s[%d]", prefix, i)
+			violations = append(violations, Violation{Field: field, Message: "key is empty"})
+		}
+		if kv.VType < StringType || kv.VType > BinaryType {
+			violations = append(violations, Violation{
+				Field:   field,
+				Message: fmt.Sprintf("unknown value type %d", kv.VType),
+			})
+		}
+		size := len(kv.VStr)
+		if kv.VType == BinaryType {
+			size = len(kv.VBlob)
+		}
+		if size > MaxValidTagValueLength {
+			violations = append(violations, Violation{
+				Field:   field,
+				Message: fmt.Sprintf("value of %d bytes exceeds the limit of %d bytes", size, MaxValidTagValueLength),
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeValidSpan() *model.Span {
+	traceID := model.TraceID{Low: 1}
+	return &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.SpanID(2),
+		OperationName: "op",
+		References:    []model.SpanRef{model.NewChildOfRef(traceID, 1)},
+		StartTime:     time.Unix(100, 0),
+		Duration:      time.Second,
+		Tags:          []model.KeyValue{model.String("k", "v")},
+		Logs:          []model.Log{{Fields: []model.KeyValue{model.String("event", "x")}}},
+		Process:       model.NewProcess("svc", []model.KeyValue{model.String("hostname", "h")}),
+	}
+}
+
+func TestValidateSpan(t *testing.T) {
+	assert.Nil(t, model.ValidateSpan(makeValidSpan()))
+
+	large := strings.Repeat("x", model.MaxValidTagValueLength+1)
+	testCases := []struct {
+		modify   func(s *model.Span)
+		expected []string
+	}{
+		{
+			modify:   func(s *model.Span) { s.TraceID = model.TraceID{} },
+			expected: []string{"traceID: trace ID is zero"},
+		},
+		{
+			modify:   func(s *model.Span) { s.SpanID = 0 },
+			expected: []string{"spanID: span ID is zero"},
+		},
+		{
+			modify:   func(s *model.Span) { s.OperationName = "" },
+			expected: []string{"operationName: operation name is empty"},
+		},
+		{
+			modify: func(s *model.Span) {
+				s.References = append(s.References,
+					model.SpanRef{RefType: model.SpanRefType(7), TraceID: s.TraceID, SpanID: 1},
+					model.SpanRef{RefType: model.FollowsFrom},
+					model.NewChildOfRef(s.TraceID, s.SpanID),
+				)
+			},
+			expected: []string{
+				"references[1].refType: unknown reference type 7",
+				"references[2].traceID: trace ID is zero",
+				"references[2].spanID: span ID is zero",
+				"references[3].spanID: span refers to itself",
+			},
+		},
+		{
+			modify:   func(s *model.Span) { s.StartTime = time.Time{} },
+			expected: []string{"startTime: start time is zero"},
+		},
+		{
+			modify:   func(s *model.Span) { s.Duration = -time.Millisecond },
+			expected: []string{"duration: duration -1ms is negative"},
+		},
+		{
+			modify: func(s *model.Span) {
+				s.Tags = append(s.Tags, model.String("http.url", large), model.String("", "v"))
+				s.Logs[0].Fields = append(s.Logs[0].Fields, model.Binary("payload", []byte(large)))
+				s.Process.Tags = append(s.Process.Tags, model.String("ip", large), model.KeyValue{Key: "x", VType: model.ValueType(-1)})
+			},
+			expected: []string{
+				"tags.http.url: value of 65537 bytes exceeds the limit of 65536 bytes",
+				"tags[2]: key is empty",
+				"logs[0].fields.payload: value of 65537 bytes exceeds the limit of 65536 bytes",
+				"process.tags.ip: value of 65537 bytes exceeds the limit of 65536 bytes",
+				"process.tags.x: unknown value type -1",
+			},
+		},
+		{
+			modify:   func(s *model.Span) { s.Process = nil },
+			expected: []string{"process: process is missing"},
+		},
+		{
+			modify:   func(s *model.Span) { s.Process.ServiceName = "" },
+			expected: []string{"process.serviceName: service name is empty"},
+		},
+	}
+	for _, testCase := range testCases {
+		span := makeValidSpan()
+		testCase.modify(span)
+		var actual []string
+		for _, v := range model.ValidateSpan(span) {
+			actual = append(actual, v.String())
+		}
+		assert.Equal(t, testCase.expected, actual)
+	}
+}
+
+func TestValidateSpanFieldPaths(t *testing.T) {
+	span := makeValidSpan()
+	span.Duration = -1
+	span.Tags[0].VStr = strings.Repeat("x", model.MaxValidTagValueLength+1)
+	for _, v := range model.ValidateSpan(span) {
+		_, ok := span.Get(v.Field)
+		assert.True(t, ok, "violation field %s must be a valid path", v.Field)
+	}
+
+	// a tag with an empty key cannot be addressed by Span.Get, so it is reported by index
+	span.Tags = append(span.Tags, model.String("", "v"))
+	violations := model.ValidateSpan(span)
+	last := violations[len(violations)-1]
+	assert.Equal(t, model.Violation{Field: "tags[1]", Message: "key is empty"}, last)
+	_, ok := span.Get(last.Field)
+	assert.False(t, ok)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TraceParentHeader is the name of the W3C Trace Context header carrying the trace context.
+	TraceParentHeader = "traceparent"
+	// TraceStateHeader is the name of the W3C Trace Context header carrying vendor-specific data.
+	TraceStateHeader = "tracestate"
+
+	traceParentVersion = "00"
+	// w3cSampledFlag is the bit of the W3C trace-flags that marks the trace as sampled.
+	w3cSampledFlag = 0x01
+)
+
+// ToW3CString converts TraceID to the W3C Trace Context form of 32 lowercase hex characters.
+// It is also the canonical zero-padded form of a TraceID.
+func (t TraceID) ToW3CString() string {
+	return t.paddedHex()
+}
+
+// TraceIDFromW3C creates a TraceID from the W3C Trace Context form of 32 lowercase hex
+// characters. All-zero trace IDs are invalid in W3C Trace Context and are rejected.
+func TraceIDFromW3C(s string) (TraceID, error) {
+	if len(s) != 32 || !isLowerHex(s) {
+		return TraceID{}, fmt.Errorf("W3C trace ID must be 32 lowercase hex characters: %s", s)
+	}
+	id, err := TraceIDFromString(s)
+	if err != nil {
+		return TraceID{}, err
+	}
+	if id.High == 0 && id.Low == 0 {
+		return TraceID{}, fmt.Errorf("W3C trace ID cannot be all zeros")
+	}
+	return id, nil
+}
+
+// ToW3CString converts SpanID to the W3C Trace Context form of 16 lowercase hex characters.
+func (s SpanID) ToW3CString() string {
+	return fmt.Sprintf("%016x", uint64(s))
+}
+
+// SpanIDFromW3C creates a SpanID from the W3C Trace Context form of 16 lowercase hex
+// characters. All-zero span IDs are invalid in W3C Trace Context and are rejected.
+func SpanIDFromW3C(s string) (SpanID, error) {
+	if len(s) != 16 || !isLowerHex(s) {
+		return SpanID(0), fmt.Errorf("W3C span ID must be 16 lowercase hex characters: %s", s)
+	}
+	id, err := SpanIDFromString(s)
+	if err != nil {
+		return SpanID(0), err
+	}
+	if id == 0 {
+		return SpanID(0), fmt.Errorf("W3C span ID cannot be all zeros")
+	}
+	return id, nil
+}
+
+// TraceParent is the trace context carried by the W3C traceparent header.
+type TraceParent struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Flags only has the sampled flag, the only one defined by both Jaeger and W3C.
+	Flags Flags
+}
+
+// TraceParent returns the W3C trace context of the span.
+// Only the sampled flag is kept, as the other Jaeger flags have no W3C equivalent.
+func (s *Span) TraceParent() TraceParent {
+	return TraceParent{TraceID: s.TraceID, SpanID: s.SpanID, Flags: s.Flags & SampledFlag}
+}
+
+// String formats the trace context as the value of a version 00 traceparent header,
+// e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func (p TraceParent) String() string {
+	var w3cFlags byte
+	if p.Flags.IsSampled() {
+		w3cFlags |= w3cSampledFlag
+	}
+	return fmt.Sprintf("%s-%s-%s-%02x", traceParentVersion, p.TraceID.ToW3CString(), p.SpanID.ToW3CString(), w3cFlags)
+}
+
+// ParseTraceParent parses the value of a traceparent header. Headers of versions later
+// than 00 are accepted as long as they start with the version 00 fields, as required by
+// the specification. The sampled W3C flag is mapped to the sampled Jaeger flag, the
+// other W3C flags are ignored.
+func ParseTraceParent(header string) (TraceParent, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return TraceParent{}, fmt.Errorf("traceparent must have 4 fields separated by '-': %s", header)
+	}
+	version := parts[0]
+	if len(version) != 2 || !isLowerHex(version) || version == "ff" {
+		return TraceParent{}, fmt.Errorf("invalid traceparent version: %s", version)
+	}
+	if version == traceParentVersion && len(parts) != 4 {
+		return TraceParent{}, fmt.Errorf("traceparent version 00 must have 4 fields separated by '-': %s", header)
+	}
+	traceID, err := TraceIDFromW3C(parts[1])
+	if err != nil {
+		return TraceParent{}, err
+	}
+	spanID, err := SpanIDFromW3C(parts[2])
+	if err != nil {
+		return TraceParent{}, err
+	}
+	if len(parts[3]) != 2 || !isLowerHex(parts[3]) {
+		return TraceParent{}, fmt.Errorf("invalid traceparent flags: %s", parts[3])
+	}
+	w3cFlags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return TraceParent{}, err
+	}
+	p := TraceParent{TraceID: traceID, SpanID: spanID}
+	if w3cFlags&w3cSampledFlag != 0 {
+		p.Flags.SetSampled()
+	}
+	return p, nil
+}
+
+// ParseTraceState parses the value of a tracestate header into a list of string
+// KeyValues, one per list member, in the order of the header. Empty list members
+// are skipped, as allowed by the specification.
+func ParseTraceState(header string) (KeyValues, error) {
+	var members KeyValues
+	for _, member := range strings.Split(header, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		i := strings.IndexByte(member, '=')
+		if i <= 0 || i == len(member)-1 {
+			return nil, fmt.Errorf("tracestate member must be a non-empty key=value pair: %s", member)
+		}
+		members = append(members, String(member[:i], member[i+1:]))
+	}
+	return members, nil
+}
+
+// FormatTraceState formats the list members as the value of a tracestate header.
+// The members are converted to strings with KeyValue.AsString.
+func FormatTraceState(members KeyValues) string {
+	parts := make([]string, 0, len(members))
+	for _, member := range members {
+		parts = append(parts, member.Key+"="+member.AsString())
+	}
+	return strings.Join(parts, ",")
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
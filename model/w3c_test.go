@@ -0,0 +1,140 @@
+// Copyright (c) 2018 The Jaeger Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestTraceIDW3CRoundTrip(t *testing.T) {
+	testCases := []struct {
+		id  model.TraceID
+		w3c string
+	}{
+		{id: model.TraceID{Low: 1}, w3c: "00000000000000000000000000000001"},
+		{id: model.TraceID{High: 0x4bf92f3577b34da6, Low: 0xa3ce929d0e0e4736}, w3c: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{id: model.TraceID{High: 1}, w3c: "00000000000000010000000000000000"},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.w3c, testCase.id.ToW3CString())
+		id, err := model.TraceIDFromW3C(testCase.w3c)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.id, id)
+	}
+}
+
+func TestTraceIDFromW3CErrors(t *testing.T) {
+	testCases := []struct {
+		s   string
+		err string
+	}{
+		{s: "1", err: "W3C trace ID must be 32 lowercase hex characters: 1"},
+		{s: "4BF92F3577B34DA6A3CE929D0E0E4736", err: "W3C trace ID must be 32 lowercase hex characters: 4BF92F3577B34DA6A3CE929D0E0E4736"},
+		{s: "00000000000000000000000000000000", err: "W3C trace ID cannot be all zeros"},
+	}
+	for _, testCase := range testCases {
+		_, err := model.TraceIDFromW3C(testCase.s)
+		assert.EqualError(t, err, testCase.err)
+	}
+}
+
+func TestSpanIDW3CRoundTrip(t *testing.T) {
+	assert.Equal(t, "00f067aa0ba902b7", model.SpanID(0xf067aa0ba902b7).ToW3CString())
+	id, err := model.SpanIDFromW3C("00f067aa0ba902b7")
+	require.NoError(t, err)
+	assert.Equal(t, model.SpanID(0xf067aa0ba902b7), id)
+
+	_, err = model.SpanIDFromW3C("f067aa0ba902b7")
+	assert.EqualError(t, err, "W3C span ID must be 16 lowercase hex characters: f067aa0ba902b7")
+	_, err = model.SpanIDFromW3C("0000000000000000")
+	assert.EqualError(t, err, "W3C span ID cannot be all zeros")
+}
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	span := &model.Span{
+		TraceID: model.TraceID{High: 0x4bf92f3577b34da6, Low: 0xa3ce929d0e0e4736},
+		SpanID:  model.SpanID(0xf067aa0ba902b7),
+	}
+	span.Flags.SetDebug()
+	p := span.TraceParent()
+	assert.Equal(t, model.Flags(0), p.Flags, "debug flag has no W3C equivalent")
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", p.String())
+
+	span.Flags.SetSampled()
+	p = span.TraceParent()
+	header := p.String()
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", header)
+	parsed, err := model.ParseTraceParent(header)
+	require.NoError(t, err)
+	assert.Equal(t, p, parsed)
+}
+
+func TestParseTraceParent(t *testing.T) {
+	p, err := model.ParseTraceParent(" 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03 ")
+	require.NoError(t, err)
+	assert.True(t, p.Flags.IsSampled())
+	assert.False(t, p.Flags.IsDebug(), "unknown W3C flags are ignored")
+
+	p, err = model.ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	require.NoError(t, err, "later versions may add fields")
+	assert.Equal(t, model.SpanID(0xf067aa0ba902b7), p.SpanID)
+	assert.False(t, p.Flags.IsSampled())
+}
+
+func TestParseTraceParentErrors(t *testing.T) {
+	testCases := []struct {
+		header string
+		err    string
+	}{
+		{header: "", err: "traceparent must have 4 fields separated by '-': "},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", err: "traceparent must have 4 fields separated by '-': 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+		{header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", err: "invalid traceparent version: ff"},
+		{header: "0-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", err: "invalid traceparent version: 0"},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-x", err: "traceparent version 00 must have 4 fields separated by '-': 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-x"},
+		{header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", err: "W3C trace ID cannot be all zeros"},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", err: "W3C span ID cannot be all zeros"},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1", err: "invalid traceparent flags: 1"},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0X", err: "invalid traceparent flags: 0X"},
+	}
+	for _, testCase := range testCases {
+		_, err := model.ParseTraceParent(testCase.header)
+		assert.EqualError(t, err, testCase.err, testCase.header)
+	}
+}
+
+func TestTraceStateRoundTrip(t *testing.T) {
+	members, err := model.ParseTraceState("rojo=00f067aa0ba902b7, ,congo=t61rcWkgMzE")
+	require.NoError(t, err)
+	assert.Equal(t, model.KeyValues{
+		model.String("rojo", "00f067aa0ba902b7"),
+		model.String("congo", "t61rcWkgMzE"),
+	}, members)
+	assert.Equal(t, "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE", model.FormatTraceState(members))
+
+	members, err = model.ParseTraceState("")
+	require.NoError(t, err)
+	assert.Empty(t, members)
+	assert.Equal(t, "", model.FormatTraceState(nil))
+
+	for _, header := range []string{"rojo", "=x", "rojo="} {
+		_, err := model.ParseTraceState(header)
+		assert.Error(t, err, header)
+	}
+}